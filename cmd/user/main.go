@@ -1,17 +1,29 @@
 package main
 
 import (
+	"context"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/joho/godotenv"
 	"github.com/jonattasmoraes/titan/internal/config"
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/infra/grpc"
 	"github.com/jonattasmoraes/titan/internal/user/infra/http"
 	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
 	"github.com/jonattasmoraes/titan/internal/user/infra/server"
 	"github.com/jonattasmoraes/titan/internal/user/usecase"
 	_ "github.com/lib/pq"
+	"google.golang.org/grpc/health"
 )
 
 func main() {
@@ -20,6 +32,9 @@ func main() {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	dsn := os.Getenv("DSN")
 
 	writer, err := config.GetWriterSqlx(dsn)
@@ -38,28 +53,237 @@ func main() {
 	config.StartMigrations(writer)
 
 	repo := repository.NewSqlxRepository(writer, reader)
+	invitationRepo := repository.NewSqlxInvitationRepository(writer, reader)
+	eventRepo := repository.NewSqlxUserEventRepository(writer, reader)
+
+	// Single-ID lookups arriving within 2ms of each other share one query.
+	userLoader := repository.NewUserLoader(repo, 2*time.Millisecond, 100)
+
+	transactor := repository.NewSqlxUserTransactor(writer)
 
-	createUser := usecase.NewCreateUserUsecase(repo)
-	getUserById := usecase.NewGetUserByIdUsecase(repo)
+	createUser := usecase.NewCreateUserUsecase(transactor)
+	getUserById := usecase.NewGetUserByIdUsecase(userLoader)
+	batchGetUsers := usecase.NewBatchGetUsersUsecase(repo)
+	getUserByEmail := usecase.NewGetUserByEmailUsecase(repo)
 	listUsers := usecase.NewListUsersUsecase(repo)
-	patchUser := usecase.NewPatchUserUsecase(repo)
-	deleteUser := usecase.NewDeleteUserUsecase(repo)
+	exportUsers := usecase.NewExportUsersUsecase(repo)
+	searchUsers := usecase.NewSearchUsersUsecase(repository.NewPostgresUserSearchIndex(reader))
+	updateUser := usecase.NewUpdateUserUsecase(transactor)
+	patchUserDocument := usecase.NewPatchUserDocumentUsecase(repo, updateUser)
+	replaceUser := usecase.NewReplaceUserUsecase(transactor, envBool("PUT_CREATES_MISSING_USERS", false))
+	deleteUser := usecase.NewDeleteUserUsecase(transactor)
+	batchWriteUsers := usecase.NewBatchWriteUsersUsecase(repo, transactor)
+	importUsers := usecase.NewImportUsersUsecase(repo, createUser)
+	listUserEvents := usecase.NewListUserEventsUsecase(eventRepo)
 
-	userHandlers := http.NewUserHandler(
+	identity := identityVerifier()
+
+	var policyEngine *policy.Engine
+	if policyFile := os.Getenv("POLICY_FILE"); policyFile != "" {
+		policyEngine, err = policy.NewEngine(policyFile, policy.NewJSONDecisionLogger(os.Stdout))
+		if err != nil {
+			log.Fatalf("Failed to load policy: %v", err)
+		}
+
+		go policyEngine.Watch(ctx, 5*time.Second)
+	} else {
+		log.Println("WARNING: POLICY_FILE is not set: every request is allowed, whoever the caller")
+	}
+
+	createInvitation := usecase.NewCreateInvitationUsecase(invitationRepo, repo)
+	resendInvitation := usecase.NewResendInvitationUsecase(invitationRepo, repo)
+	revokeInvitation := usecase.NewRevokeInvitationUsecase(invitationRepo, repo)
+	acceptInvitation := usecase.NewAcceptInvitationUsecase(invitationRepo, repository.NewSqlxInvitationTransactor(writer))
+
+	var idempotencyStore domain.IdempotencyStore
+	switch os.Getenv("IDEMPOTENCY_STORE") {
+	case "memory":
+		idempotencyStore = repository.NewMemoryIdempotencyStore()
+	default:
+		idempotencyStore = repository.NewSqlxIdempotencyStore(writer, reader)
+	}
+
+	idempotency := http.NewIdempotency(idempotencyStore, envDuration("IDEMPOTENCY_TTL", http.DefaultIdempotencyTTL))
+	go idempotency.Purge(ctx, time.Hour)
+
+	userGrpcServer := grpc.NewUserGrpcServer(
 		createUser,
 		getUserById,
+		getUserByEmail,
+		batchGetUsers,
 		listUsers,
-		patchUser,
+		exportUsers,
+		updateUser,
 		deleteUser,
+		listUserEvents,
+		policyEngine,
+		// Watch streams end once the server starts draining.
+		ctx,
 	)
 
-	go func() {
-		server.StartServer(userHandlers)
-	}()
+	// The /api operations that have an RPC are served by the gRPC server.
+	userHandlers := http.NewUserHandler(
+		userGrpcServer,
+		getUserById,
+		searchUsers,
+		patchUserDocument,
+		replaceUser,
+		batchWriteUsers,
+		importUsers,
+		policyEngine,
+		idempotency,
+	)
 
+	invitationHandlers := http.NewInvitationHandler(
+		createInvitation,
+		resendInvitation,
+		revokeInvitation,
+		acceptInvitation,
+	)
+
+	grpcAddress := os.Getenv("GRPC_ADDRESS")
+	if grpcAddress == "" {
+		grpcAddress = server.DefaultGrpcAddress
+	}
+
+	gatewayEndpoint := os.Getenv("GATEWAY_ENDPOINT")
+	if gatewayEndpoint == "" {
+		gatewayEndpoint = grpcAddress
+		if strings.HasPrefix(gatewayEndpoint, ":") {
+			gatewayEndpoint = "localhost" + gatewayEndpoint
+		}
+	}
+
+	gateway, err := server.NewGateway(ctx, gatewayEndpoint)
+	if err != nil {
+		log.Fatalf("Failed to start gateway: %v", err)
+	}
+
+	grpcConfig := grpc.DefaultServerConfig(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+	grpcConfig.DefaultTimeout = envDuration("GRPC_DEFAULT_TIMEOUT", grpcConfig.DefaultTimeout)
+	grpcConfig.MaxRecvMsgSize = envInt("GRPC_MAX_RECV_MSG_SIZE", grpcConfig.MaxRecvMsgSize)
+	grpcConfig.MaxSendMsgSize = envInt("GRPC_MAX_SEND_MSG_SIZE", grpcConfig.MaxSendMsgSize)
+	grpcConfig.Identity = identity
+	grpcConfig.Metrics.Publish("grpc_server")
+
+	healthServer := health.NewServer()
+	go server.WatchDatabaseHealth(ctx, healthServer, writer, 5*time.Second)
+
+	grpcServer := server.NewGrpcServer(userGrpcServer, healthServer, grpcConfig.ServerOptions()...)
+
+	grpcWeb := server.NewGrpcWebHandler(grpcServer, server.GrpcWebConfig{
+		AllowedOrigins: envList("GRPC_WEB_ALLOWED_ORIGINS"),
+		AllowedHeaders: envList("GRPC_WEB_ALLOWED_HEADERS"),
+	})
+
+	routerConfig := server.RouterConfig{
+		V1Deprecation: http.Deprecation{
+			Since:  envTime("API_V1_DEPRECATED_AT"),
+			Sunset: envTime("API_V1_SUNSET_AT"),
+		},
+		Identity: identity,
+	}
+
+	httpStopped := make(chan struct{})
 	go func() {
-		server.StartGrpcServer(getUserById)
+		defer close(httpStopped)
+
+		httpDrainTimeout := envDuration("HTTP_DRAIN_TIMEOUT", server.DefaultDrainTimeout)
+		if err := server.StartServer(ctx, server.DefaultHTTPAddress, userHandlers, invitationHandlers, gateway, grpcWeb, routerConfig, httpDrainTimeout); err != nil {
+			log.Fatalf("HTTP server failed: %v", err)
+		}
 	}()
 
-	select {}
+	drainTimeout := envDuration("GRPC_DRAIN_TIMEOUT", server.DefaultDrainTimeout)
+	if err := server.StartGrpcServer(ctx, grpcAddress, grpcServer, healthServer, drainTimeout); err != nil {
+		log.Fatalf("GRPC server failed: %v", err)
+	}
+
+	// The HTTP requests may start imports, so they are done first.
+	<-httpStopped
+
+	importDrainTimeout := envDuration("IMPORT_DRAIN_TIMEOUT", usecase.DefaultImportDrainTimeout)
+	log.Printf("Waiting up to %s for the background imports", importDrainTimeout)
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), importDrainTimeout)
+	defer cancel()
+
+	if err := importUsers.Drain(drainCtx); err != nil {
+		log.Printf("Import drain timeout reached, stopping with imports still running")
+	}
+}
+
+// identityVerifier believes the identity headers signed with IDENTITY_SECRET
+// by the authenticating gateway, or every identity header when
+// TRUST_IDENTITY_HEADERS is set for development. Otherwise every caller is
+// anonymous.
+func identityVerifier() *policy.IdentityVerifier {
+	if secret := os.Getenv("IDENTITY_SECRET"); secret != "" {
+		return policy.NewIdentityVerifier(secret)
+	}
+
+	if envBool("TRUST_IDENTITY_HEADERS", false) {
+		log.Println("TRUST_IDENTITY_HEADERS is set: identity headers are believed without a signature")
+		return policy.TrustIdentityHeaders
+	}
+
+	log.Println("IDENTITY_SECRET is not set: every caller is anonymous")
+	return nil
+}
+
+// envDuration reads a duration such as "15s" from the environment, falling
+// back to def when the variable is unset or invalid.
+func envDuration(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// envTime reads an RFC 3339 time such as "2024-06-01T00:00:00Z" from the
+// environment, returning the zero time when the variable is unset or invalid.
+func envTime(key string) time.Time {
+	value, err := time.Parse(time.RFC3339, os.Getenv(key))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return value
+}
+
+// envInt reads an integer from the environment, falling back to def when the
+// variable is unset or invalid.
+func envInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// envBool reads a boolean such as "true" or "1" from the environment, falling
+// back to def when the variable is unset or invalid.
+func envBool(key string, def bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// envList reads a comma-separated list from the environment, ignoring empty
+// entries.
+func envList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
 }
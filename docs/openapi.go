@@ -0,0 +1,9 @@
+package docs
+
+import _ "embed"
+
+// UserServiceOpenAPI is the OpenAPI document generated from proto/user.proto
+// for the /v1 gRPC-Gateway routes. Regenerate it with `make protoc`.
+//
+//go:embed openapi/proto/user.swagger.json
+var UserServiceOpenAPI []byte
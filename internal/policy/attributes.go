@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"strings"
+
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+)
+
+const (
+	HeaderUserID        = "X-User-ID"
+	HeaderUserRole      = "X-User-Role"
+	HeaderSubjectPrefix = "X-Subject-"
+)
+
+// Subject builds the subject attributes from the identity headers set by the
+// authenticating gateway. Extra attributes such as a region arrive as
+// X-Subject-<name> headers (x-subject-<name> metadata over gRPC).
+func Subject(id string, role string, attributes map[string]string) map[string]interface{} {
+	subject := map[string]interface{}{}
+
+	for name, value := range attributes {
+		subject[strings.ToLower(strings.ReplaceAll(name, "-", "_"))] = value
+	}
+
+	subject["id"] = id
+	subject["role"] = role
+	if role == "" {
+		subject["role"] = "anonymous"
+	}
+
+	return subject
+}
+
+// UserResource exposes a user as resource attributes. The password is never
+// part of it. The region is only set when the user has one, so that policies
+// can test it with has(resource.region).
+func UserResource(user *dto.UserResponseDTO) map[string]interface{} {
+	if user == nil {
+		return map[string]interface{}{}
+	}
+
+	resource := map[string]interface{}{
+		"type":         "user",
+		"id":           user.ID,
+		"email":        user.Email,
+		"email_domain": emailDomain(user.Email),
+		"role":         user.Role,
+	}
+	if user.Region != "" {
+		resource["region"] = user.Region
+	}
+
+	return resource
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+
+	return strings.ToLower(email[at+1:])
+}
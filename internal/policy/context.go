@@ -0,0 +1,36 @@
+package policy
+
+import "context"
+
+type contextKey int
+
+const (
+	identityContextKey contextKey = iota
+	environmentContextKey
+)
+
+// WithIdentity returns a copy of ctx that carries the caller.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey, identity)
+}
+
+// IdentityFrom returns the caller ctx carries, the anonymous one when it
+// carries none.
+func IdentityFrom(ctx context.Context) Identity {
+	identity, _ := ctx.Value(identityContextKey).(Identity)
+	return identity
+}
+
+// WithEnvironment returns a copy of ctx that carries the environment
+// attributes of the request being served. A transport that hands its request
+// over to another, as the HTTP API does to the gRPC server, sets it so that
+// policies see the request as the caller sent it.
+func WithEnvironment(ctx context.Context, environment map[string]interface{}) context.Context {
+	return context.WithValue(ctx, environmentContextKey, environment)
+}
+
+// EnvironmentFrom returns the environment attributes ctx carries, if any.
+func EnvironmentFrom(ctx context.Context) (map[string]interface{}, bool) {
+	environment, ok := ctx.Value(environmentContextKey).(map[string]interface{})
+	return environment, ok
+}
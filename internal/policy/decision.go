@@ -0,0 +1,45 @@
+package policy
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of one evaluation. Every evaluation produces one
+// and hands it to the engine's DecisionLogger.
+type Decision struct {
+	Time          time.Time              `json:"time"`
+	PolicyVersion string                 `json:"policy_version"`
+	Action        string                 `json:"action"`
+	Subject       map[string]interface{} `json:"subject"`
+	Resource      map[string]interface{} `json:"resource"`
+	Environment   map[string]interface{} `json:"env"`
+	Allowed       bool                   `json:"allowed"`
+	Effect        string                 `json:"effect"`
+	Rule          string                 `json:"rule,omitempty"`
+	Errors        []string               `json:"errors,omitempty"`
+	Duration      time.Duration          `json:"duration_ns"`
+}
+
+type DecisionLogger interface {
+	LogDecision(decision Decision)
+}
+
+type jsonDecisionLogger struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONDecisionLogger writes every decision as one JSON line to w.
+func NewJSONDecisionLogger(w io.Writer) DecisionLogger {
+	return &jsonDecisionLogger{encoder: json.NewEncoder(w)}
+}
+
+func (l *jsonDecisionLogger) LogDecision(decision Decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_ = l.encoder.Encode(decision)
+}
@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Request describes a single authorization question.
+type Request struct {
+	Action      string
+	Subject     map[string]interface{}
+	Resource    map[string]interface{}
+	Environment map[string]interface{}
+}
+
+// Engine evaluates requests against the rules of a policy file and reloads the
+// file when it changes on disk.
+type Engine struct {
+	path   string
+	logger DecisionLogger
+
+	mu      sync.RWMutex
+	policy  *compiledPolicy
+	modTime time.Time
+}
+
+func NewEngine(path string, logger DecisionLogger) (*Engine, error) {
+	engine := &Engine{path: path, logger: logger}
+
+	if err := engine.Reload(); err != nil {
+		return nil, err
+	}
+
+	return engine, nil
+}
+
+// Version returns the version declared by the policy currently in use.
+func (e *Engine) Version() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.policy.version
+}
+
+// Reload reads the policy file again if it changed since the last load. When
+// the new file cannot be parsed or compiled the current policy is kept.
+func (e *Engine) Reload() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return err
+	}
+
+	e.mu.RLock()
+	unchanged := e.policy != nil && info.ModTime().Equal(e.modTime)
+	e.mu.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	compiled, err := loadFile(e.path)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.policy = compiled
+	e.modTime = info.ModTime()
+	e.mu.Unlock()
+
+	log.Printf("Policy %s loaded, version %s with %d rules", e.path, compiled.version, len(compiled.rules))
+
+	return nil
+}
+
+// Watch polls the policy file every interval until ctx is done.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Reload(); err != nil {
+				log.Printf("Failed to reload policy %s, keeping version %s: %v", e.path, e.Version(), err)
+			}
+		}
+	}
+}
+
+// Evaluate decides the request and writes the decision to the decision log.
+func (e *Engine) Evaluate(request Request) Decision {
+	start := time.Now()
+
+	e.mu.RLock()
+	policy := e.policy
+	e.mu.RUnlock()
+
+	environment := map[string]interface{}{}
+	for key, value := range request.Environment {
+		environment[key] = value
+	}
+	if _, ok := environment["time"]; !ok {
+		environment["time"] = start
+	}
+
+	decision := Decision{
+		Time:          start,
+		PolicyVersion: policy.version,
+		Action:        request.Action,
+		Subject:       request.Subject,
+		Resource:      request.Resource,
+		Environment:   environment,
+		Allowed:       policy.allow,
+		Effect:        EffectDeny,
+	}
+	if policy.allow {
+		decision.Effect = EffectAllow
+	}
+
+	vars := map[string]interface{}{
+		"action":   request.Action,
+		"subject":  orEmpty(request.Subject),
+		"resource": orEmpty(request.Resource),
+		"env":      environment,
+	}
+
+	allowedBy := ""
+
+	for i := range policy.rules {
+		rule := &policy.rules[i]
+		if !rule.matchesAction(request.Action) {
+			continue
+		}
+
+		// A condition that cannot be evaluated denies the request, whatever
+		// the effect of its rule: a broken deny rule must not let requests
+		// through.
+		matched, err := rule.eval(vars)
+		if err != nil {
+			decision.Errors = append(decision.Errors, fmt.Sprintf("%s: %v", rule.Name, err))
+		} else if !matched {
+			continue
+		}
+
+		if rule.Effect == EffectDeny || err != nil {
+			decision.Allowed = false
+			decision.Effect = EffectDeny
+			decision.Rule = rule.Name
+			allowedBy = ""
+			break
+		}
+
+		if allowedBy == "" {
+			allowedBy = rule.Name
+		}
+	}
+
+	if allowedBy != "" {
+		decision.Allowed = true
+		decision.Effect = EffectAllow
+		decision.Rule = allowedBy
+	}
+
+	decision.Duration = time.Since(start)
+
+	if e.logger != nil {
+		e.logger.LogDecision(decision)
+	}
+
+	return decision
+}
+
+func orEmpty(attributes map[string]interface{}) map[string]interface{} {
+	if attributes == nil {
+		return map[string]interface{}{}
+	}
+
+	return attributes
+}
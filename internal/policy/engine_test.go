@@ -0,0 +1,222 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	decisions []Decision
+}
+
+func (l *recordingLogger) LogDecision(decision Decision) {
+	l.decisions = append(l.decisions, decision)
+}
+
+const testPolicy = `
+version: "1"
+default: deny
+rules:
+  - name: admins
+    effect: allow
+    actions: ["user.*"]
+    condition: subject.role == "admin"
+  - name: support-region
+    effect: allow
+    actions: ["user.read"]
+    condition: >
+      subject.role == "support" && subject.region == resource.region &&
+      env.time.getHours("UTC") >= 9 && env.time.getHours("UTC") < 18
+  - name: no-self-delete
+    effect: deny
+    actions: ["user.delete"]
+    condition: subject.id == resource.id
+`
+
+func writePolicy(t *testing.T, path string, content string, modTime time.Time) {
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write policy file: %v", err)
+	}
+
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to touch policy file: %v", err)
+	}
+}
+
+func setupEngine(t *testing.T) (*Engine, *recordingLogger, string) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	writePolicy(t, path, testPolicy, time.Now().Add(-time.Hour))
+
+	logger := &recordingLogger{}
+	engine, err := NewEngine(path, logger)
+	if err != nil {
+		t.Fatalf("Failed to load policy: %v", err)
+	}
+
+	return engine, logger, path
+}
+
+func TestEvaluate_AllowAndDefaultDeny(t *testing.T) {
+	engine, logger, _ := setupEngine(t)
+
+	// Admins may list users
+	decision := engine.Evaluate(Request{
+		Action:  "user.list",
+		Subject: map[string]interface{}{"id": "1", "role": "admin"},
+	})
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "admins", decision.Rule)
+
+	// Regular users match no rule and fall back to the default
+	decision = engine.Evaluate(Request{
+		Action:  "user.list",
+		Subject: map[string]interface{}{"id": "2", "role": "user"},
+	})
+	assert.False(t, decision.Allowed)
+	assert.Empty(t, decision.Rule)
+
+	// Every evaluation is written to the decision log
+	assert.Len(t, logger.decisions, 2)
+	assert.Equal(t, "1", logger.decisions[0].PolicyVersion)
+}
+
+func TestEvaluate_EnvironmentAttributes(t *testing.T) {
+	engine, _, _ := setupEngine(t)
+
+	request := Request{
+		Action:   "user.read",
+		Subject:  map[string]interface{}{"id": "1", "role": "support", "region": "south"},
+		Resource: map[string]interface{}{"id": "2", "region": "south"},
+	}
+
+	// Inside business hours
+	request.Environment = map[string]interface{}{"time": time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)}
+	assert.True(t, engine.Evaluate(request).Allowed)
+
+	// Outside business hours
+	request.Environment = map[string]interface{}{"time": time.Date(2024, 6, 3, 20, 0, 0, 0, time.UTC)}
+	assert.False(t, engine.Evaluate(request).Allowed)
+
+	// Another region
+	request.Environment = map[string]interface{}{"time": time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)}
+	request.Resource = map[string]interface{}{"id": "2", "region": "north"}
+	assert.False(t, engine.Evaluate(request).Allowed)
+}
+
+func TestEvaluate_DenyWins(t *testing.T) {
+	engine, _, _ := setupEngine(t)
+
+	// The admin rule matches but the deny rule wins
+	decision := engine.Evaluate(Request{
+		Action:   "user.delete",
+		Subject:  map[string]interface{}{"id": "1", "role": "admin"},
+		Resource: map[string]interface{}{"id": "1"},
+	})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "no-self-delete", decision.Rule)
+}
+
+func TestEvaluate_ErrorsDeny(t *testing.T) {
+	engine, _, _ := setupEngine(t)
+
+	// The support rule fails on the missing region
+	decision := engine.Evaluate(Request{
+		Action:  "user.read",
+		Subject: map[string]interface{}{"id": "1", "role": "support"},
+	})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "support-region", decision.Rule)
+	assert.NotEmpty(t, decision.Errors)
+
+	// A deny rule that fails wins over the admin rule instead of being skipped
+	decision = engine.Evaluate(Request{
+		Action:  "user.delete",
+		Subject: map[string]interface{}{"id": "1", "role": "admin"},
+	})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, EffectDeny, decision.Effect)
+	assert.Equal(t, "no-self-delete", decision.Rule)
+	assert.NotEmpty(t, decision.Errors)
+}
+
+func TestReload(t *testing.T) {
+	engine, _, path := setupEngine(t)
+
+	// A changed file is picked up
+	writePolicy(t, path, `
+version: "2"
+default: allow
+rules: []
+`, time.Now())
+	assert.NoError(t, engine.Reload())
+	assert.Equal(t, "2", engine.Version())
+	assert.True(t, engine.Evaluate(Request{Action: "user.list"}).Allowed)
+
+	// A broken file is rejected and the previous policy stays in place
+	writePolicy(t, path, `
+version: "3"
+rules:
+  - name: broken
+    effect: allow
+    actions: ["user.read"]
+    condition: subject.role ==
+`, time.Now().Add(time.Minute))
+	assert.Error(t, engine.Reload())
+	assert.Equal(t, "2", engine.Version())
+}
+
+func TestNewEngine_InvalidEffect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	writePolicy(t, path, `
+version: "1"
+rules:
+  - name: maybe
+    effect: perhaps
+    actions: ["user.read"]
+`, time.Now())
+
+	_, err := NewEngine(path, nil)
+	assert.ErrorIs(t, err, ErrInvalidEffect)
+}
+
+func TestNewEngine_ShippedPolicy(t *testing.T) {
+	// The policy shipped with the repository must always compile
+	engine, err := NewEngine(filepath.Join("..", "..", "policies", "users.yaml"), nil)
+	assert.NoError(t, err)
+
+	// Support reads users of their own region during business hours in São
+	// Paulo, on a Wednesday at 10h there (13h UTC)
+	request := Request{
+		Action:      "user.read",
+		Subject:     Subject("1", "support", map[string]string{"Region": "south"}),
+		Resource:    map[string]interface{}{"type": "user", "id": "2", "email": "ada@example.com", "email_domain": "example.com", "role": "user", "region": "south"},
+		Environment: map[string]interface{}{"time": time.Date(2024, 6, 5, 13, 0, 0, 0, time.UTC)},
+	}
+	decision := engine.Evaluate(request)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "support-reads-own-region-during-business-hours", decision.Rule)
+	assert.Empty(t, decision.Errors)
+
+	// but not on a Saturday
+	request.Environment = map[string]interface{}{"time": time.Date(2024, 6, 8, 13, 0, 0, 0, time.UTC)}
+	decision = engine.Evaluate(request)
+	assert.False(t, decision.Allowed)
+	assert.Empty(t, decision.Errors)
+
+	// nor users of another region
+	request.Environment = map[string]interface{}{"time": time.Date(2024, 6, 5, 13, 0, 0, 0, time.UTC)}
+	request.Resource["region"] = "north"
+	decision = engine.Evaluate(request)
+	assert.False(t, decision.Allowed)
+	assert.Empty(t, decision.Errors)
+
+	// nor users without one
+	delete(request.Resource, "region")
+	decision = engine.Evaluate(request)
+	assert.False(t, decision.Allowed)
+	assert.Empty(t, decision.Errors)
+}
@@ -0,0 +1,29 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityVerifier_Authenticate(t *testing.T) {
+	verifier := NewIdentityVerifier("gateway-secret")
+	admin := Identity{ID: "1", Role: "admin", Attributes: map[string]string{"Region": "eu"}}
+	signature := verifier.Sign(admin)
+
+	// A signed identity is believed, attribute names are case-insensitive
+	assert.Equal(t, admin, verifier.Authenticate(admin, signature))
+	assert.Equal(t, signature, verifier.Sign(Identity{ID: "1", Role: "admin", Attributes: map[string]string{"region": "eu"}}))
+
+	// Anything else is anonymous: a missing signature, a changed header, or a
+	// signature made with another secret
+	assert.Equal(t, Identity{}, verifier.Authenticate(admin, ""))
+	assert.Equal(t, Identity{}, verifier.Authenticate(Identity{ID: "2", Role: "admin", Attributes: admin.Attributes}, signature))
+	assert.Equal(t, Identity{}, verifier.Authenticate(admin, NewIdentityVerifier("guess").Sign(admin)))
+	assert.Equal(t, Identity{}, NewIdentityVerifier("").Authenticate(admin, NewIdentityVerifier("").Sign(admin)))
+
+	// Without a verifier nobody is believed, unless headers are trusted
+	var none *IdentityVerifier
+	assert.Equal(t, Identity{}, none.Authenticate(admin, signature))
+	assert.Equal(t, admin, TrustIdentityHeaders.Authenticate(admin, ""))
+}
@@ -0,0 +1,159 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+var (
+	ErrPolicyVersionRequired = errors.New("policy file must declare a version")
+	ErrInvalidEffect         = errors.New("rule effect must be 'allow' or 'deny'")
+	ErrRuleWithoutActions    = errors.New("rule must list at least one action")
+)
+
+// Document is the on-disk shape of a policy file.
+//
+// Rules are CEL expressions over the subject, resource and env maps plus the
+// action string. A matching deny rule always wins over matching allow rules,
+// and Default decides when nothing matches.
+type Document struct {
+	Version string `yaml:"version"`
+	Default string `yaml:"default"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Effect      string   `yaml:"effect"`
+	Actions     []string `yaml:"actions"`
+	Condition   string   `yaml:"condition"`
+}
+
+type compiledRule struct {
+	Rule
+	program cel.Program
+}
+
+type compiledPolicy struct {
+	version string
+	allow   bool
+	rules   []compiledRule
+}
+
+func newCelEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("subject", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("env", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("action", cel.StringType),
+	)
+}
+
+func loadFile(path string) (*compiledPolicy, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc Document
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse policy file %s: %w", path, err)
+	}
+
+	return compile(&doc)
+}
+
+func compile(doc *Document) (*compiledPolicy, error) {
+	if doc.Version == "" {
+		return nil, ErrPolicyVersionRequired
+	}
+
+	env, err := newCelEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &compiledPolicy{
+		version: doc.Version,
+		allow:   doc.Default == EffectAllow,
+	}
+
+	for i, rule := range doc.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return nil, fmt.Errorf("%s: %w", rule.Name, ErrInvalidEffect)
+		}
+
+		if len(rule.Actions) == 0 {
+			return nil, fmt.Errorf("%s: %w", rule.Name, ErrRuleWithoutActions)
+		}
+
+		condition := strings.TrimSpace(rule.Condition)
+		if condition == "" {
+			condition = "true"
+		}
+
+		ast, issues := env.Compile(condition)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Name, issues.Err())
+		}
+
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			return nil, fmt.Errorf("%s: condition must evaluate to a bool, got %s", rule.Name, ast.OutputType())
+		}
+
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Name, err)
+		}
+
+		compiled.rules = append(compiled.rules, compiledRule{Rule: rule, program: program})
+	}
+
+	return compiled, nil
+}
+
+// matchesAction reports whether the rule applies to action. Patterns may be
+// an exact action, "*", or a prefix ending in ".*" such as "user.*".
+func (r *compiledRule) matchesAction(action string) bool {
+	for _, pattern := range r.Actions {
+		if pattern == "*" || pattern == action {
+			return true
+		}
+
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(action, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// eval evaluates the condition of the rule, which must return a bool.
+func (r *compiledRule) eval(vars map[string]interface{}) (bool, error) {
+	out, _, err := r.program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition returned %v", out.Type())
+	}
+
+	return matched, nil
+}
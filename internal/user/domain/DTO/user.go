@@ -1,10 +1,13 @@
 package dto
 
+import "time"
+
 type UserRequestDTO struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
 	Password  string `json:"password"`
+	Region    string `json:"region,omitempty"`
 }
 
 type UserResponseDTO struct {
@@ -13,17 +16,156 @@ type UserResponseDTO struct {
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
 	Role      string `json:"role"`
+	Region    string `json:"region,omitempty"`
 	CreateAt  string `json:"create_at"`
 	UpdateAt  string `json:"update_at"`
+	DeleteAt  string `json:"delete_at,omitempty"`
+	Version   int64  `json:"version,omitempty"`
+	// CreatedTime, UpdatedTime and DeletedTime are CreateAt, UpdateAt and
+	// DeleteAt as times, for the transports that encode timestamps themselves.
+	CreatedTime time.Time `json:"-"`
+	UpdatedTime time.Time `json:"-"`
+	DeletedTime time.Time `json:"-"`
 }
 
 type PatchRequestDTO struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
+	Region    string `json:"region,omitempty"`
+}
+
+// ProblemResponse is an RFC 7807 problem, the body of every error response.
+// Type links to the catalogue entry of Code, which clients should match on
+// rather than on Title or Detail.
+type ProblemResponse struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// TraceID identifies the request in the server logs.
+	TraceID string              `json:"trace_id"`
+	Errors  []ProblemFieldError `json:"errors,omitempty"`
+}
+
+// ProblemFieldError is the part of a problem about one request field.
+type ProblemFieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// ErrorCodeDTO is one entry of the error catalogue.
+type ErrorCodeDTO struct {
+	Code   string `json:"code"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Field  string `json:"field,omitempty"`
+}
+
+type InvitationRequestDTO struct {
+	OrganizationID string `json:"organization_id"`
+	Email          string `json:"email"`
+	Role           string `json:"role"`
+	InvitedBy      string `json:"-"`
+}
+
+type InvitationResponseDTO struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organization_id"`
+	Email          string `json:"email"`
+	Role           string `json:"role"`
+	Status         string `json:"status"`
+	Token          string `json:"token,omitempty"`
+	ExpiresAt      string `json:"expires_at"`
+	CreateAt       string `json:"create_at"`
+	UpdateAt       string `json:"update_at"`
+}
+
+type AcceptInvitationRequestDTO struct {
+	Token     string `json:"token"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Password  string `json:"password"`
+}
+
+type MembershipResponseDTO struct {
+	OrganizationID string           `json:"organization_id"`
+	Role           string           `json:"role"`
+	User           *UserResponseDTO `json:"user"`
+}
+
+type UserEventDTO struct {
+	Cursor     string           `json:"cursor"`
+	Type       string           `json:"type"`
+	User       *UserResponseDTO `json:"user"`
+	OccurredAt string           `json:"occurred_at"`
+	// OccurredTime is OccurredAt as a time.
+	OccurredTime time.Time `json:"-"`
+}
+
+type UserSearchResultDTO struct {
+	User *UserResponseDTO `json:"user"`
+	Rank float64          `json:"rank"`
+	// Highlights holds HTML-escaped fields with the matches in <mark></mark>.
+	Highlights map[string]string `json:"highlights"`
+}
+
+// BatchOperationDTO is one operation of a batch write. Creates read user,
+// patches read id and the non-empty fields of user, and deletes read id.
+// Patches and deletes only apply at version when it is set.
+type BatchOperationDTO struct {
+	Op      string          `json:"op" enums:"create,patch,delete"`
+	ID      string          `json:"id,omitempty"`
+	Version int64           `json:"version,omitempty"`
+	User    *UserRequestDTO `json:"user,omitempty"`
+}
+
+type BatchWriteRequestDTO struct {
+	Mode       string              `json:"mode" enums:"atomic,best_effort"`
+	Operations []BatchOperationDTO `json:"operations"`
+}
+
+type BatchWriteResultDTO struct {
+	Index  int              `json:"index"`
+	Op     string           `json:"op"`
+	Status int              `json:"status"`
+	User   *UserResponseDTO `json:"user,omitempty"`
+	Error  string           `json:"error,omitempty"`
+	// Code is the error code of a failed operation.
+	Code string `json:"code,omitempty"`
+	// Field is the request field a validation error is about.
+	Field string `json:"field,omitempty"`
+}
+
+// ImportRowDTO reports what an import did, or would do in a dry run, with
+// one row of the file. Line is where the row starts in the file.
+type ImportRowDTO struct {
+	Line   int    `json:"line"`
+	Email  string `json:"email"`
+	Status string `json:"status" enums:"created,valid,invalid,duplicate,failed"`
+	UserID string `json:"user_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Code is the error code of a rejected row.
+	Code string `json:"code,omitempty"`
+	// Field is the column a validation error is about.
+	Field string `json:"field,omitempty"`
 }
 
-type ErrorResponse struct {
-	Code  int    `json:"code"`
-	Error string `json:"error"`
+// ImportJobDTO is the progress of an import. Rows holds the report of the
+// rows processed so far, in file order.
+type ImportJobDTO struct {
+	ID            string         `json:"id"`
+	Status        string         `json:"status" enums:"running,completed"`
+	DryRun        bool           `json:"dry_run"`
+	TotalRows     int            `json:"total_rows"`
+	ProcessedRows int            `json:"processed_rows"`
+	Succeeded     int            `json:"succeeded"`
+	Failed        int            `json:"failed"`
+	Rows          []ImportRowDTO `json:"rows"`
+	CreateAt      string         `json:"create_at"`
+	FinishedAt    string         `json:"finished_at,omitempty"`
 }
@@ -0,0 +1,19 @@
+package dtov2
+
+type InvitationResponseDTO struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organization_id"`
+	Email          string `json:"email"`
+	Role           string `json:"role"`
+	Status         string `json:"status"`
+	Token          string `json:"token,omitempty"`
+	ExpiresAt      string `json:"expires_at"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+type MembershipResponseDTO struct {
+	OrganizationID string           `json:"organization_id"`
+	Role           string           `json:"role"`
+	User           *UserResponseDTO `json:"user"`
+}
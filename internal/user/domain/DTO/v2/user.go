@@ -0,0 +1,52 @@
+// Package dtov2 holds the DTOs of the /api/v2 routes. Requests are the same
+// as in v1; responses spell their fields consistently.
+package dtov2
+
+import dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+
+type UserResponseDTO struct {
+	ID        string `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	Region    string `json:"region,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	DeletedAt string `json:"deleted_at,omitempty"`
+	// Version is the version of the user, also sent as its ETag.
+	Version int64 `json:"version"`
+}
+
+type UserSearchResultDTO struct {
+	User *UserResponseDTO `json:"user"`
+	Rank float64          `json:"rank"`
+	// Highlights holds HTML-escaped fields with the matches in <mark></mark>.
+	Highlights map[string]string `json:"highlights"`
+}
+
+type BatchWriteResultDTO struct {
+	Index  int              `json:"index"`
+	Op     string           `json:"op"`
+	Status int              `json:"status"`
+	User   *UserResponseDTO `json:"user,omitempty"`
+	Error  string           `json:"error,omitempty"`
+	// Code is the error code of a failed operation.
+	Code string `json:"code,omitempty"`
+	// Field is the request field a validation error is about.
+	Field string `json:"field,omitempty"`
+}
+
+// ImportJobDTO is the progress of an import. The rows are reported as in v1.
+type ImportJobDTO struct {
+	ID            string             `json:"id"`
+	Status        string             `json:"status" enums:"running,completed"`
+	DryRun        bool               `json:"dry_run"`
+	TotalRows     int                `json:"total_rows"`
+	ProcessedRows int                `json:"processed_rows"`
+	Succeeded     int                `json:"succeeded"`
+	Failed        int                `json:"failed"`
+	Rows          []dto.ImportRowDTO `json:"rows"`
+	CreatedAt     string             `json:"created_at"`
+	FinishedAt    string             `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,144 @@
+package entities
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+
+	// DefaultInvitationTTL is how long an invitation token stays valid after
+	// it is issued or resent.
+	DefaultInvitationTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrOrganizationIsRequired = errcode.New(errcode.OrganizationRequired, "param: 'organization_id' is required, please try again")
+	ErrInvitedByIsRequired    = errcode.New(errcode.InvitedByRequired, "param: 'invited_by' is required, please try again")
+)
+
+type Invitation struct {
+	ID             string
+	OrganizationID string
+	Email          string
+	Role           string
+	TokenHash      string
+	Status         string
+	InvitedBy      string
+	UserID         string
+	ExpiresAt      time.Time
+	AcceptedAt     time.Time
+	RevokedAt      time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type Membership struct {
+	OrganizationID string
+	UserID         string
+	Role           string
+	CreatedAt      time.Time
+}
+
+// NewInvitation builds a pending invitation and returns it together with the
+// raw token. Only the token hash is kept on the entity, so the raw token must
+// be handed to the invitee right away.
+func NewInvitation(organizationID string, email string, role string, invitedBy string) (*Invitation, string, error) {
+	invitation := &Invitation{
+		ID:             ulid.Make().String(),
+		OrganizationID: organizationID,
+		Email:          strings.ToLower(strings.TrimSpace(email)),
+		Role:           role,
+		Status:         InvitationPending,
+		InvitedBy:      invitedBy,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := invitation.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	token, err := invitation.RenewToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return invitation, token, nil
+}
+
+func (i *Invitation) Validate() error {
+	if i.OrganizationID == "" {
+		return ErrorValidation(ErrOrganizationIsRequired)
+	}
+
+	if i.Email == "" {
+		return ErrorValidation(ErrEmailIsRequired)
+	}
+
+	if !IsValidEmail(i.Email) {
+		return ErrorValidation(fmt.Errorf("%w: %s, please try again with a valid email", ErrInvalidEmail, i.Email))
+	}
+
+	if i.Role == "" {
+		return ErrorValidation(ErrRoleIsRequired)
+	}
+
+	if !IsValidRole(i.Role) {
+		return ErrorValidation(ErrIncorrectRole)
+	}
+
+	if i.InvitedBy == "" {
+		return ErrorValidation(ErrInvitedByIsRequired)
+	}
+
+	return nil
+}
+
+// RenewToken issues a fresh token, replaces the stored hash and pushes the
+// expiration forward. Any previously issued token stops working.
+func (i *Invitation) RenewToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	i.TokenHash = HashInvitationToken(token)
+	i.Status = InvitationPending
+	i.ExpiresAt = time.Now().Add(DefaultInvitationTTL)
+	i.UpdatedAt = time.Now()
+
+	return token, nil
+}
+
+func (i *Invitation) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// MatchesEmail reports whether email is the address the invitation was sent to.
+func (i *Invitation) MatchesEmail(email string) bool {
+	return strings.EqualFold(i.Email, strings.TrimSpace(email))
+}
+
+func HashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func IsValidRole(role string) bool {
+	return role == "admin" || role == "super" || role == "user"
+}
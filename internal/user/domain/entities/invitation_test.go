@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInvitation_Valid(t *testing.T) {
+	// Create a new invitation with valid parameters
+	invitation, token, err := NewInvitation("org-1", "Alice.Johnson@Example.com", "admin", "inviter-1")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, "alice.johnson@example.com", invitation.Email)
+	assert.Equal(t, InvitationPending, invitation.Status)
+	assert.Equal(t, HashInvitationToken(token), invitation.TokenHash)
+	assert.NotEqual(t, token, invitation.TokenHash)
+}
+
+func TestNewInvitation_MissingOrganization(t *testing.T) {
+	// Attempt to create an invitation without an organization
+	_, _, err := NewInvitation("", "alice.johnson@example.com", "user", "inviter-1")
+	assert.EqualError(t, err, ErrOrganizationIsRequired.Error())
+}
+
+func TestNewInvitation_IncorrectRole(t *testing.T) {
+	// Attempt to create an invitation with an unknown role
+	_, _, err := NewInvitation("org-1", "alice.johnson@example.com", "owner", "inviter-1")
+	assert.EqualError(t, err, ErrIncorrectRole.Error())
+}
+
+func TestInvitation_RenewToken(t *testing.T) {
+	// Renewing the token replaces the stored hash and revives the invitation
+	invitation, token, err := NewInvitation("org-1", "alice.johnson@example.com", "user", "inviter-1")
+	assert.NoError(t, err)
+
+	invitation.Status = InvitationExpired
+	renewed, err := invitation.RenewToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, renewed)
+	assert.Equal(t, HashInvitationToken(renewed), invitation.TokenHash)
+	assert.Equal(t, InvitationPending, invitation.Status)
+}
+
+func TestInvitation_IsExpired(t *testing.T) {
+	// An invitation is expired once its expiration time has passed
+	invitation := &Invitation{ExpiresAt: time.Now().Add(-time.Minute)}
+	assert.True(t, invitation.IsExpired(time.Now()))
+
+	invitation.ExpiresAt = time.Now().Add(time.Hour)
+	assert.False(t, invitation.IsExpired(time.Now()))
+}
+
+func TestInvitation_MatchesEmail(t *testing.T) {
+	// Email comparison ignores case and surrounding spaces
+	invitation := &Invitation{Email: "alice.johnson@example.com"}
+	assert.True(t, invitation.MatchesEmail(" Alice.Johnson@example.com "))
+	assert.False(t, invitation.MatchesEmail("bob.smith@example.com"))
+}
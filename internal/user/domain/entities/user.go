@@ -6,36 +6,50 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
 	"github.com/oklog/ulid/v2"
 )
 
 var (
-	ErrAllParamsRequired   = errors.New("all params are required, please try again")
-	ErrFirstNameIsRequired = errors.New("param: 'FirstName' is required, please try again")
-	ErrLastNameIsRequired  = errors.New("param: 'lastName' is required, please try again")
-	ErrEmailIsRequired     = errors.New("param: 'email' is required, please try again")
-	ErrPasswordIsRequired  = errors.New("param: 'password' is required, please try again")
-	ErrRoleIsRequired      = errors.New("param: 'role' is required, please try again")
-	ErrIncorrectRole       = errors.New("param: 'role' must be 'admin', 'super' or 'user', please try again")
-	ErrAtLeastOneParam     = errors.New("at least one param is required, please try again")
-	ErrPasswordTooShort    = errors.New("password must be at least 8 characters long, please try again")
-	ErrFirstNameTooShort   = errors.New("first name must be at least 3 characters long, please try again")
-	ErrLastNameTooShort    = errors.New("last name must be at least 3 characters long, please try again")
+	ErrAllParamsRequired   = errcode.New(errcode.ParamsRequired, "all params are required, please try again")
+	ErrFirstNameIsRequired = errcode.New(errcode.FirstNameRequired, "param: 'FirstName' is required, please try again")
+	ErrEmailIsRequired     = errcode.New(errcode.EmailRequired, "param: 'email' is required, please try again")
+	ErrPasswordIsRequired  = errcode.New(errcode.PasswordRequired, "param: 'password' is required, please try again")
+	ErrRoleIsRequired      = errcode.New(errcode.RoleRequired, "param: 'role' is required, please try again")
+	ErrIncorrectRole       = errcode.New(errcode.RoleInvalid, "param: 'role' must be 'admin', 'super' or 'user', please try again")
+	ErrAtLeastOneParam     = errcode.New(errcode.AtLeastOneField, "at least one param is required, please try again")
+	ErrPasswordTooShort    = errcode.New(errcode.PasswordTooShort, "password must be at least 8 characters long, please try again")
+	ErrFirstNameTooShort   = errcode.New(errcode.FirstNameTooShort, "first name must be at least 3 characters long, please try again")
+	ErrLastNameTooShort    = errcode.New(errcode.LastNameTooShort, "last name must be at least 3 characters long, please try again")
+	ErrRegionTooLong       = errcode.New(errcode.RegionTooLong, fmt.Sprintf("region must be at most %d characters long, please try again", MaxRegionLength))
+	ErrInvalidEmail        = errcode.New(errcode.EmailInvalid, "invalid email")
+	ErrInvalidID           = errcode.New(errcode.IDInvalid, "param: 'id' must be a valid ULID, please try again")
 )
 
 type User struct {
 	ID        string
 	FirstName string
-	LastName  string
-	Email     string
-	Password  string
-	Role      string
+	// LastName is optional. Like DeletedAt, its zero value means the user has
+	// none, which is stored as NULL.
+	LastName string
+	Email    string
+	Password string
+	Role     string
+	// Region is optional and stored as NULL when empty. Access policies
+	// compare it with the region of the caller.
+	Region    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt time.Time
+	// Version starts at 1 and is incremented by every write, so a client can
+	// tell whether the user changed since it read it.
+	Version int64
 }
 
-func NewUser(firstName string, lastName string, email string, password string) (*User, error) {
+// MaxRegionLength is the longest region a user can have.
+const MaxRegionLength = 50
+
+func NewUser(firstName string, lastName string, email string, password string, region string) (*User, error) {
 	user := &User{
 		ID:        ulid.Make().String(),
 		FirstName: firstName,
@@ -43,8 +57,10 @@ func NewUser(firstName string, lastName string, email string, password string) (
 		Email:     email,
 		Password:  password,
 		Role:      "user",
+		Region:    region,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
+		Version:   1,
 	}
 
 	if err := user.Validate(); err != nil {
@@ -82,12 +98,12 @@ func (u *User) Validate() error {
 		return ErrorValidation(ErrFirstNameTooShort)
 	}
 
-	if u.LastName == "" {
-		return ErrorValidation(ErrLastNameIsRequired)
+	if u.LastName != "" && len(u.LastName) < 3 {
+		return ErrorValidation(ErrLastNameTooShort)
 	}
 
-	if len(u.LastName) < 3 {
-		return ErrorValidation(ErrLastNameTooShort)
+	if len(u.Region) > MaxRegionLength {
+		return ErrorValidation(ErrRegionTooLong)
 	}
 
 	if u.Password == "" {
@@ -103,8 +119,37 @@ func (u *User) Validate() error {
 	}
 
 	if !IsValidEmail(u.Email) {
-		ErrInvalidEmail := fmt.Errorf("invalid email: %s, please try again with a valid email", u.Email)
-		return ErrorValidation(ErrInvalidEmail)
+		return ErrorValidation(fmt.Errorf("%w: %s, please try again with a valid email", ErrInvalidEmail, u.Email))
+	}
+
+	return nil
+}
+
+// ValidateProfile applies the Validate rules to the fields a user can change
+// after signing up, leaving the password out.
+func (u *User) ValidateProfile() error {
+	if u.FirstName == "" {
+		return ErrorValidation(ErrFirstNameIsRequired)
+	}
+
+	if len(u.FirstName) < 3 {
+		return ErrorValidation(ErrFirstNameTooShort)
+	}
+
+	if u.LastName != "" && len(u.LastName) < 3 {
+		return ErrorValidation(ErrLastNameTooShort)
+	}
+
+	if len(u.Region) > MaxRegionLength {
+		return ErrorValidation(ErrRegionTooLong)
+	}
+
+	if u.Email == "" {
+		return ErrorValidation(ErrEmailIsRequired)
+	}
+
+	if !IsValidEmail(u.Email) {
+		return ErrorValidation(fmt.Errorf("%w: %s, please try again with a valid email", ErrInvalidEmail, u.Email))
 	}
 
 	return nil
@@ -124,17 +169,48 @@ func (r *User) Patch() error {
 	}
 
 	if r.Email != "" && !IsValidEmail(r.Email) {
-		ErrInvalidEmail := fmt.Errorf("invalid email: %s, please try again with a valid email", r.Email)
-		return ErrorValidation(ErrInvalidEmail)
+		return ErrorValidation(fmt.Errorf("%w: %s, please try again with a valid email", ErrInvalidEmail, r.Email))
 	}
 	return nil
 }
 
+func (u *User) IsAdmin() bool {
+	return u.Role == "admin" || u.Role == "super"
+}
+
 func IsValidEmail(email string) bool {
 	re := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	return re.MatchString(email)
 }
 
+// IsValidationError reports whether err was raised by one of the validation
+// rules of this package.
+func IsValidationError(err error) bool {
+	if errors.Is(err, ErrInvalidEmail) {
+		return true
+	}
+
+	switch err {
+	case ErrAllParamsRequired, ErrFirstNameIsRequired, ErrEmailIsRequired,
+		ErrPasswordIsRequired, ErrRoleIsRequired, ErrIncorrectRole, ErrAtLeastOneParam,
+		ErrPasswordTooShort, ErrFirstNameTooShort, ErrLastNameTooShort, ErrRegionTooLong,
+		ErrOrganizationIsRequired, ErrInvitedByIsRequired, ErrInvalidID:
+		return true
+	}
+
+	return false
+}
+
+// ValidationField returns the name of the field a validation error is about,
+// or an empty string when the error is not tied to a single field.
+func ValidationField(err error) string {
+	if !IsValidationError(err) {
+		return ""
+	}
+
+	return errcode.FieldOf(err)
+}
+
 func ErrorValidation(err error) error {
 	return err
 }
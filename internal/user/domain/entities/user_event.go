@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+const (
+	UserCreated = "created"
+	UserUpdated = "updated"
+	UserDeleted = "deleted"
+)
+
+// UserEvent is one entry of the user change log. Sequence is assigned by the
+// store and grows with every appended event.
+type UserEvent struct {
+	Sequence   int64
+	Type       string
+	User       User
+	OccurredAt time.Time
+}
+
+// NewUserEvent records a change to user. The password is never copied into
+// the event.
+func NewUserEvent(eventType string, user *User) *UserEvent {
+	snapshot := *user
+	snapshot.Password = ""
+
+	return &UserEvent{
+		Type:       eventType,
+		User:       snapshot,
+		OccurredAt: time.Now(),
+	}
+}
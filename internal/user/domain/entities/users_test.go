@@ -1,6 +1,8 @@
 package entities
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,19 +10,19 @@ import (
 
 func TestNewUser_Valid(t *testing.T) {
 	// Create a new user with valid parameters
-	_, err := NewUser("Alice", "Johnson", "alice.johnson@example.com", "password123")
+	_, err := NewUser("Alice", "Johnson", "alice.johnson@example.com", "password123", "")
 	assert.NoError(t, err)
 }
 
 func TestNewUser_MissingFirstName(t *testing.T) {
 	// Attempt to create a user with missing first name
-	_, err := NewUser("", "Johnson", "alice.johnson@example.com", "password123")
+	_, err := NewUser("", "Johnson", "alice.johnson@example.com", "password123", "")
 	assert.EqualError(t, err, ErrFirstNameIsRequired.Error())
 }
 
 func TestNewUser_InvalidEmail(t *testing.T) {
 	// Attempt to create a user with an invalid email address
-	_, err := NewUser("Alice", "Johnson", "invalid-email", "password123")
+	_, err := NewUser("Alice", "Johnson", "invalid-email", "password123", "")
 	assert.EqualError(t, err, ErrorValidation(err).Error())
 }
 
@@ -130,14 +132,13 @@ func TestUser_Validate_ShortFirstName(t *testing.T) {
 }
 
 func TestUser_Validate_MissingLastName(t *testing.T) {
-	// Validate a user missing last name
+	// The last name is optional
 	user := &User{
 		FirstName: "Alice",
 		Email:     "alice.johnson@example.com",
 		Password:  "password123",
 	}
-	err := user.Validate()
-	assert.EqualError(t, err, ErrLastNameIsRequired.Error())
+	assert.NoError(t, user.Validate())
 }
 
 func TestUser_Validate_ShortLastName(t *testing.T) {
@@ -152,6 +153,19 @@ func TestUser_Validate_ShortLastName(t *testing.T) {
 	assert.EqualError(t, err, ErrLastNameTooShort.Error())
 }
 
+func TestUser_Validate_LongRegion(t *testing.T) {
+	// Validate a user with too long region
+	user := &User{
+		FirstName: "Alice",
+		LastName:  "Johnson",
+		Email:     "alice.johnson@example.com",
+		Password:  "password123",
+		Region:    strings.Repeat("r", MaxRegionLength+1),
+	}
+	err := user.Validate()
+	assert.EqualError(t, err, ErrRegionTooLong.Error())
+}
+
 func TestUser_Validate_InvalidEmail(t *testing.T) {
 	// Validate a user with an invalid email address
 	user := &User{
@@ -192,3 +206,45 @@ func TestUser_Patch_InvalidEmailPatch(t *testing.T) {
 	err := user.Patch()
 	assert.EqualError(t, err, ErrorValidation(err).Error())
 }
+
+func TestUser_ValidateProfile_IgnoresPassword(t *testing.T) {
+	// A profile without a password is valid
+	user := &User{
+		FirstName: "Bob",
+		LastName:  "Smith",
+		Email:     "bob.smith@example.com",
+	}
+	assert.NoError(t, user.ValidateProfile())
+}
+
+func TestUser_ValidateProfile_ClearedFields(t *testing.T) {
+	// Unlike Patch, an empty field is not skipped
+	user := &User{
+		LastName: "Smith",
+		Email:    "bob.smith@example.com",
+	}
+	assert.EqualError(t, user.ValidateProfile(), ErrFirstNameIsRequired.Error())
+
+	// but the last name may be cleared
+	user = &User{
+		FirstName: "Bob",
+		Email:     "bob.smith@example.com",
+	}
+	assert.NoError(t, user.ValidateProfile())
+}
+
+func TestIsValidationError(t *testing.T) {
+	// Errors raised by validation are recognised, others are not
+	_, err := NewUser("Alice", "Johnson", "invalid-email", "password123", "")
+	assert.True(t, IsValidationError(err))
+	assert.True(t, IsValidationError(ErrLastNameTooShort))
+	assert.False(t, IsValidationError(errors.New("connection refused")))
+}
+
+func TestValidationField(t *testing.T) {
+	// Validation errors point to the field they are about
+	_, err := NewUser("Alice", "Johnson", "invalid-email", "password123", "")
+	assert.Equal(t, "email", ValidationField(err))
+	assert.Equal(t, "first_name", ValidationField(ErrFirstNameTooShort))
+	assert.Equal(t, "", ValidationField(ErrAtLeastOneParam))
+}
@@ -0,0 +1,180 @@
+package errcode
+
+import "net/http"
+
+// StatusClientClosedRequest is the status of requests whose client went
+// away, as nginx logs them. Clients never receive it.
+const StatusClientClosedRequest = 499
+
+// Generic codes, not tied to a resource.
+const (
+	Internal                 Code = "internal"
+	Unavailable              Code = "unavailable"
+	DeadlineExceeded         Code = "deadline_exceeded"
+	Canceled                 Code = "canceled"
+	Forbidden                Code = "forbidden"
+	UnknownCode              Code = "error.code_unknown"
+	InvalidBody              Code = "request.body_invalid"
+	InvalidParameter         Code = "request.parameter_invalid"
+	BodyTooLarge             Code = "request.body_too_large"
+	UnsupportedMediaType     Code = "request.media_type_unsupported"
+	InvalidETag              Code = "request.etag_invalid"
+	InvalidReadMask          Code = "request.read_mask_invalid"
+	InvalidUpdateMask        Code = "request.update_mask_invalid"
+	IdempotencyKeyTooLong    Code = "request.idempotency_key_too_long"
+	IdempotencyKeyReused     Code = "request.idempotency_key_reused"
+	IdempotencyKeyInProgress Code = "request.idempotency_key_in_progress"
+)
+
+// User codes.
+const (
+	UserNotFound         Code = "user.not_found"
+	UserDeleted          Code = "user.deleted"
+	EmailTaken           Code = "user.email_taken"
+	VersionMismatch      Code = "user.version_mismatch"
+	ParamsRequired       Code = "user.validation.params_required"
+	AtLeastOneField      Code = "user.validation.at_least_one_field"
+	FirstNameRequired    Code = "user.validation.first_name_required"
+	FirstNameTooShort    Code = "user.validation.first_name_too_short"
+	LastNameRequired     Code = "user.validation.last_name_required" // No longer raised, last names are optional.
+	LastNameTooShort     Code = "user.validation.last_name_too_short"
+	RegionTooLong        Code = "user.validation.region_too_long"
+	EmailRequired        Code = "user.validation.email_required"
+	EmailInvalid         Code = "user.validation.email_invalid"
+	PasswordRequired     Code = "user.validation.password_required"
+	PasswordTooShort     Code = "user.validation.password_too_short"
+	RoleRequired         Code = "user.validation.role_required"
+	RoleInvalid          Code = "user.validation.role_invalid"
+	IDInvalid            Code = "user.validation.id_invalid"
+	UsersNotFound        Code = "user.list.not_found"
+	PageInvalid          Code = "user.list.page_invalid"
+	PageSizeInvalid      Code = "user.list.page_size_invalid"
+	CursorInvalid        Code = "user.list.cursor_invalid"
+	SortInvalid          Code = "user.list.sort_invalid"
+	OrderInvalid         Code = "user.list.order_invalid"
+	RoleFilterInvalid    Code = "user.list.role_invalid"
+	EmailDomainInvalid   Code = "user.list.email_domain_invalid"
+	CreatedRangeInvalid  Code = "user.list.created_range_invalid"
+	UpdatedRangeInvalid  Code = "user.list.updated_range_invalid"
+	DeletedInvalid       Code = "user.list.deleted_invalid"
+	SearchQueryInvalid   Code = "user.search.query_invalid"
+	SearchLimitInvalid   Code = "user.search.limit_invalid"
+	EventCursorInvalid   Code = "user.events.cursor_invalid"
+	IdsRequired          Code = "user.batch_get.ids_required"
+	TooManyIds           Code = "user.batch_get.too_many_ids"
+	OperationsRequired   Code = "user.batch.operations_required"
+	TooManyOperations    Code = "user.batch.too_many_operations"
+	BatchModeInvalid     Code = "user.batch.mode_invalid"
+	BatchOpInvalid       Code = "user.batch.op_invalid"
+	BatchOpIDRequired    Code = "user.batch.id_required"
+	BatchOpForbidden     Code = "user.batch.forbidden"
+	BatchOpNotApplied    Code = "user.batch.not_applied"
+	PatchInvalid         Code = "user.patch.invalid"
+	PatchTestFailed      Code = "user.patch.test_failed"
+	PatchNotApplicable   Code = "user.patch.not_applicable"
+	PatchReadOnlyField   Code = "user.patch.read_only_field"
+	ImportFormatInvalid  Code = "user.import.format_invalid"
+	ImportMappingInvalid Code = "user.import.mapping_invalid"
+	ImportFileInvalid    Code = "user.import.file_invalid"
+	ImportEmpty          Code = "user.import.empty"
+	ImportTooManyRows    Code = "user.import.too_many_rows"
+	ImportDuplicate      Code = "user.import.duplicate_email"
+	ImportJobNotFound    Code = "user.import.job_not_found"
+	ExportFormatInvalid  Code = "user.export.format_invalid"
+)
+
+// Invitation codes.
+const (
+	InvitationNotFound           Code = "invitation.not_found"
+	InvitationForbidden          Code = "invitation.forbidden"
+	InvitationAlreadyPending     Code = "invitation.already_pending"
+	InvitationNotPending         Code = "invitation.not_pending"
+	InvitationExpired            Code = "invitation.expired"
+	InvitationEmailMismatch      Code = "invitation.email_mismatch"
+	InvitationCredentialsInvalid Code = "invitation.invalid_credentials"
+	OrganizationRequired         Code = "invitation.validation.organization_id_required"
+	InvitedByRequired            Code = "invitation.validation.invited_by_required"
+)
+
+var catalogue = map[Code]Definition{
+	Internal:                 {Title: "Internal error", Status: http.StatusInternalServerError},
+	Unavailable:              {Title: "Service temporarily unavailable, please retry", Status: http.StatusServiceUnavailable},
+	DeadlineExceeded:         {Title: "Deadline exceeded", Status: http.StatusGatewayTimeout},
+	Canceled:                 {Title: "Request canceled", Status: StatusClientClosedRequest},
+	Forbidden:                {Title: "Operation not allowed", Status: http.StatusForbidden},
+	UnknownCode:              {Title: "Unknown error code", Status: http.StatusNotFound, Field: "code"},
+	InvalidBody:              {Title: "Invalid request body", Status: http.StatusBadRequest},
+	InvalidParameter:         {Title: "Invalid request parameter", Status: http.StatusBadRequest},
+	BodyTooLarge:             {Title: "Request body too large", Status: http.StatusRequestEntityTooLarge},
+	UnsupportedMediaType:     {Title: "Unsupported media type", Status: http.StatusUnsupportedMediaType},
+	InvalidETag:              {Title: "Invalid etag", Status: http.StatusBadRequest, Field: "etag"},
+	InvalidReadMask:          {Title: "Invalid read mask", Status: http.StatusBadRequest, Field: "read_mask"},
+	InvalidUpdateMask:        {Title: "Invalid update mask", Status: http.StatusBadRequest, Field: "update_mask"},
+	IdempotencyKeyTooLong:    {Title: "Idempotency key too long", Status: http.StatusBadRequest},
+	IdempotencyKeyReused:     {Title: "Idempotency key reused with another request", Status: http.StatusUnprocessableEntity},
+	IdempotencyKeyInProgress: {Title: "Request with this idempotency key in progress", Status: http.StatusConflict},
+
+	UserNotFound:         {Title: "User not found", Status: http.StatusNotFound},
+	UserDeleted:          {Title: "User deleted", Status: http.StatusGone},
+	EmailTaken:           {Title: "Email already taken", Status: http.StatusConflict, Field: "email"},
+	VersionMismatch:      {Title: "User changed since it was read", Status: http.StatusPreconditionFailed},
+	ParamsRequired:       {Title: "Required fields missing", Status: http.StatusBadRequest},
+	AtLeastOneField:      {Title: "At least one field required", Status: http.StatusBadRequest},
+	FirstNameRequired:    {Title: "First name required", Status: http.StatusBadRequest, Field: "first_name"},
+	FirstNameTooShort:    {Title: "First name too short", Status: http.StatusBadRequest, Field: "first_name"},
+	LastNameRequired:     {Title: "Last name required", Status: http.StatusBadRequest, Field: "last_name"},
+	LastNameTooShort:     {Title: "Last name too short", Status: http.StatusBadRequest, Field: "last_name"},
+	RegionTooLong:        {Title: "Region too long", Status: http.StatusBadRequest, Field: "region"},
+	EmailRequired:        {Title: "Email required", Status: http.StatusBadRequest, Field: "email"},
+	EmailInvalid:         {Title: "Invalid email", Status: http.StatusBadRequest, Field: "email"},
+	PasswordRequired:     {Title: "Password required", Status: http.StatusBadRequest, Field: "password"},
+	PasswordTooShort:     {Title: "Password too short", Status: http.StatusBadRequest, Field: "password"},
+	RoleRequired:         {Title: "Role required", Status: http.StatusBadRequest, Field: "role"},
+	RoleInvalid:          {Title: "Invalid role", Status: http.StatusBadRequest, Field: "role"},
+	IDInvalid:            {Title: "Invalid ID", Status: http.StatusBadRequest, Field: "id"},
+	UsersNotFound:        {Title: "No users found", Status: http.StatusBadRequest},
+	PageInvalid:          {Title: "Invalid page", Status: http.StatusBadRequest, Field: "page"},
+	PageSizeInvalid:      {Title: "Invalid page size", Status: http.StatusBadRequest, Field: "page_size"},
+	CursorInvalid:        {Title: "Invalid cursor", Status: http.StatusBadRequest, Field: "cursor"},
+	SortInvalid:          {Title: "Invalid sort field", Status: http.StatusBadRequest, Field: "sort"},
+	OrderInvalid:         {Title: "Invalid sort order", Status: http.StatusBadRequest, Field: "order"},
+	RoleFilterInvalid:    {Title: "Invalid role filter", Status: http.StatusBadRequest, Field: "role"},
+	EmailDomainInvalid:   {Title: "Invalid email domain filter", Status: http.StatusBadRequest, Field: "email_domain"},
+	CreatedRangeInvalid:  {Title: "Invalid created range", Status: http.StatusBadRequest, Field: "created_before"},
+	UpdatedRangeInvalid:  {Title: "Invalid updated range", Status: http.StatusBadRequest, Field: "updated_before"},
+	DeletedInvalid:       {Title: "Invalid deleted filter", Status: http.StatusBadRequest, Field: "deleted"},
+	SearchQueryInvalid:   {Title: "Invalid search text", Status: http.StatusBadRequest, Field: "q"},
+	SearchLimitInvalid:   {Title: "Invalid search limit", Status: http.StatusBadRequest, Field: "limit"},
+	EventCursorInvalid:   {Title: "Invalid event cursor", Status: http.StatusBadRequest, Field: "cursor"},
+	IdsRequired:          {Title: "IDs required", Status: http.StatusBadRequest, Field: "ids"},
+	TooManyIds:           {Title: "Too many IDs", Status: http.StatusBadRequest, Field: "ids"},
+	OperationsRequired:   {Title: "Operations required", Status: http.StatusBadRequest, Field: "operations"},
+	TooManyOperations:    {Title: "Too many operations", Status: http.StatusBadRequest, Field: "operations"},
+	BatchModeInvalid:     {Title: "Invalid batch mode", Status: http.StatusBadRequest, Field: "mode"},
+	BatchOpInvalid:       {Title: "Invalid batch operation", Status: http.StatusBadRequest, Field: "op"},
+	BatchOpIDRequired:    {Title: "Operation ID required", Status: http.StatusBadRequest, Field: "id"},
+	BatchOpForbidden:     {Title: "Operation not allowed", Status: http.StatusForbidden},
+	BatchOpNotApplied:    {Title: "Operation not applied", Status: http.StatusFailedDependency},
+	PatchInvalid:         {Title: "Invalid patch document", Status: http.StatusBadRequest},
+	PatchTestFailed:      {Title: "Patch test failed", Status: http.StatusConflict},
+	PatchNotApplicable:   {Title: "Patch cannot be applied to the user", Status: http.StatusUnprocessableEntity},
+	PatchReadOnlyField:   {Title: "Field cannot be patched", Status: http.StatusUnprocessableEntity},
+	ImportFormatInvalid:  {Title: "Invalid import format", Status: http.StatusBadRequest, Field: "format"},
+	ImportMappingInvalid: {Title: "Invalid import mapping", Status: http.StatusBadRequest, Field: "mapping"},
+	ImportFileInvalid:    {Title: "Invalid import file", Status: http.StatusBadRequest, Field: "file"},
+	ImportEmpty:          {Title: "Empty import file", Status: http.StatusBadRequest, Field: "file"},
+	ImportTooManyRows:    {Title: "Too many import rows", Status: http.StatusBadRequest, Field: "file"},
+	ImportDuplicate:      {Title: "Email repeated in the import file", Status: http.StatusConflict, Field: "email"},
+	ImportJobNotFound:    {Title: "Import job not found", Status: http.StatusNotFound},
+	ExportFormatInvalid:  {Title: "Invalid export format", Status: http.StatusBadRequest, Field: "format"},
+
+	InvitationNotFound:           {Title: "Invitation not found", Status: http.StatusNotFound},
+	InvitationForbidden:          {Title: "Only admins can manage invitations", Status: http.StatusForbidden},
+	InvitationAlreadyPending:     {Title: "Invitation already pending", Status: http.StatusConflict, Field: "email"},
+	InvitationNotPending:         {Title: "Invitation no longer pending", Status: http.StatusConflict},
+	InvitationExpired:            {Title: "Invitation expired", Status: http.StatusGone},
+	InvitationEmailMismatch:      {Title: "Invitation sent to another email", Status: http.StatusForbidden, Field: "email"},
+	InvitationCredentialsInvalid: {Title: "Invalid credentials", Status: http.StatusUnauthorized, Field: "password"},
+	OrganizationRequired:         {Title: "Organization required", Status: http.StatusBadRequest, Field: "organization_id"},
+	InvitedByRequired:            {Title: "Inviting user required", Status: http.StatusBadRequest, Field: "invited_by"},
+}
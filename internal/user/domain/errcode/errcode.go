@@ -0,0 +1,148 @@
+// Package errcode holds the catalogue of the stable, machine-readable codes
+// Titan reports errors with. The HTTP and gRPC APIs both answer with these
+// codes, so clients can tell errors apart without reading their messages.
+package errcode
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Domain names the catalogue where a protocol asks for it, such as the
+// domain of a gRPC ErrorInfo.
+const Domain = "titan"
+
+// Code is a stable error code. Codes are part of the API: once released, a
+// code keeps its meaning and is never renamed. New failures get new codes.
+type Code string
+
+// Definition is what the catalogue knows about a code.
+type Definition struct {
+	// Title is a short summary of the problem, the same for every error of
+	// the code.
+	Title string
+	// Status is the HTTP status of the code. The gRPC code is derived from
+	// it.
+	Status int
+	// Field is the request field to fix, when the code is about one.
+	Field string
+}
+
+// Definition returns the catalogue entry of c, or the one of Internal for
+// codes the catalogue does not know.
+func (c Code) Definition() Definition {
+	if definition, ok := catalogue[c]; ok {
+		return definition
+	}
+
+	return catalogue[Internal]
+}
+
+// Known reports whether c is in the catalogue.
+func (c Code) Known() bool {
+	_, ok := catalogue[c]
+	return ok
+}
+
+// Codes returns every code of the catalogue.
+func Codes() []Code {
+	codes := make([]Code, 0, len(catalogue))
+	for code := range catalogue {
+		codes = append(codes, code)
+	}
+
+	return codes
+}
+
+// Error is an error with a code. Its message is the detail shown to clients.
+type Error struct {
+	Code Code
+	// Field overrides the field of the code's definition.
+	Field   string
+	Message string
+	// Err is the cause, if any.
+	Err error
+}
+
+// New returns an error with code and message. Sentinel errors are declared
+// with it so that they can still be compared with == and errors.Is.
+func New(code Code, message string) error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Of returns the code of err: the code of the first *Error it wraps, or else
+// a code telling cancellation, an unreachable database or an internal error
+// apart.
+func Of(err error) Code {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case isUnavailable(err):
+		return Unavailable
+	}
+
+	return Internal
+}
+
+// FieldOf returns the request field err is about, or an empty string.
+func FieldOf(err error) string {
+	var coded *Error
+	if !errors.As(err, &coded) {
+		return ""
+	}
+
+	if coded.Field != "" {
+		return coded.Field
+	}
+
+	return coded.Code.Definition().Field
+}
+
+// sqlStater is implemented by driver errors that carry a SQLSTATE code, such
+// as *pq.Error.
+type sqlStater interface {
+	SQLState() string
+}
+
+// isUnavailable reports whether err means the database could not be reached,
+// as opposed to a query that failed.
+func isUnavailable(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	// SQLSTATE class 08 is a connection exception and 57P0x an operator
+	// intervention such as a server shutdown.
+	var stater sqlStater
+	if errors.As(err, &stater) {
+		state := stater.SQLState()
+		return strings.HasPrefix(state, "08") || strings.HasPrefix(state, "57P0")
+	}
+
+	return false
+}
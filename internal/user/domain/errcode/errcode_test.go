@@ -0,0 +1,74 @@
+package errcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogue_Definitions(t *testing.T) {
+	// Every code is lower case, dotted, and has a title and a status
+	format := regexp.MustCompile(`^[a-z_]+(\.[a-z_]+)*$`)
+
+	for _, code := range Codes() {
+		definition := code.Definition()
+		assert.Regexp(t, format, string(code))
+		assert.NotEmpty(t, definition.Title, code)
+		assert.GreaterOrEqual(t, definition.Status, http.StatusBadRequest, code)
+	}
+}
+
+func TestDefinition_UnknownCode(t *testing.T) {
+	// Unknown codes are reported as internal errors
+	assert.False(t, Code("user.unheard_of").Known())
+	assert.Equal(t, Internal.Definition(), Code("user.unheard_of").Definition())
+}
+
+func TestOf(t *testing.T) {
+	emailTaken := New(EmailTaken, "email taken")
+
+	cases := []struct {
+		name string
+		err  error
+		code Code
+	}{
+		{"sentinel", emailTaken, EmailTaken},
+		{"wrapped", fmt.Errorf("create: %w", emailTaken), EmailTaken},
+		{"dynamic", &Error{Code: ImportDuplicate, Message: "email is already used on line 2 of the file"}, ImportDuplicate},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), DeadlineExceeded},
+		{"canceled", context.Canceled, Canceled},
+		{"connection", &pq.Error{Code: "08006"}, Unavailable},
+		{"query", &pq.Error{Code: "42601"}, Internal},
+		{"unknown", errors.New("boom"), Internal},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.code, Of(c.err))
+		})
+	}
+}
+
+func TestFieldOf(t *testing.T) {
+	// The field of the error wins over the one of its code
+	assert.Equal(t, "email", FieldOf(fmt.Errorf("create: %w", New(EmailTaken, "email taken"))))
+	assert.Equal(t, "created_after", FieldOf(&Error{Code: InvalidParameter, Field: "created_after"}))
+	assert.Equal(t, "", FieldOf(New(UserNotFound, "user not found")))
+	assert.Equal(t, "", FieldOf(errors.New("boom")))
+}
+
+func TestError_Is(t *testing.T) {
+	// Sentinels keep their identity, and the cause of an error is kept
+	sentinel := New(UserNotFound, "user not found")
+	cause := errors.New("file too large")
+
+	assert.True(t, errors.Is(fmt.Errorf("get: %w", sentinel), sentinel))
+	assert.False(t, errors.Is(New(UserNotFound, "user not found"), sentinel))
+	assert.True(t, errors.Is(&Error{Code: BodyTooLarge, Err: cause}, cause))
+}
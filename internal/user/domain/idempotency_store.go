@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord is the outcome of a request sent with an Idempotency-Key.
+// StatusCode is 0, and Body empty, while the first request with the key is
+// still being handled. Keys are scoped to the Subject that sent them, so that
+// callers who happen to pick the same key do not see each other's responses.
+type IdempotencyRecord struct {
+	// Subject identifies the caller, empty for anonymous requests.
+	Subject string
+	Key     string
+	// Fingerprint identifies the request the key was first used with.
+	Fingerprint string
+	// Token identifies the reservation of the key. A retry of a request whose
+	// reservation expired sends the same fingerprint, so the token is what
+	// tells the two reservations apart.
+	Token      string
+	StatusCode int
+	Body       []byte
+	ExpiresAt  time.Time
+}
+
+// Pending reports whether the response of the record is not known yet.
+func (r *IdempotencyRecord) Pending() bool {
+	return r.StatusCode == 0
+}
+
+// IdempotencyStore keeps the responses of requests sent with an
+// Idempotency-Key until they expire.
+type IdempotencyStore interface {
+	// ReserveIdempotencyKey stores a pending record, unless a record for the
+	// same subject and key that has not expired by now exists. That record is
+	// returned instead, and nil when the key was reserved.
+	ReserveIdempotencyKey(record *IdempotencyRecord, now time.Time) (*IdempotencyRecord, error)
+	// CompleteIdempotencyKey stores the response of a reserved record. It
+	// leaves the key alone when it was taken over by another reservation
+	// since, even one of the same request.
+	CompleteIdempotencyKey(reserved *IdempotencyRecord, statusCode int, body []byte, expiresAt time.Time) error
+	// ReleaseIdempotencyKey forgets a reserved record, so that the request can
+	// be retried. Like CompleteIdempotencyKey, it only touches the key while
+	// it holds the token of reserved.
+	ReleaseIdempotencyKey(reserved *IdempotencyRecord) error
+	// DeleteExpiredIdempotencyKeys removes the records expired by now and
+	// returns how many there were.
+	DeleteExpiredIdempotencyKeys(now time.Time) (int64, error)
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+// ErrInvitationNotPending is returned when an invitation is consumed after it
+// stopped being pending.
+var ErrInvitationNotPending = errcode.New(errcode.InvitationNotPending, "invitation is no longer pending")
+
+type InvitationRepository interface {
+	CreateInvitation(invitation *entities.Invitation) error
+	FindInvitationById(id string) (*entities.Invitation, error)
+	FindInvitationByTokenHash(tokenHash string) (*entities.Invitation, error)
+	FindPendingInvitation(organizationID string, email string) (*entities.Invitation, error)
+	UpdateInvitation(invitation *entities.Invitation) error
+	// AcceptInvitation marks the invitation as accepted by its UserID, only
+	// while it is still pending, failing with ErrInvitationNotPending
+	// otherwise. Concurrent accepts of one invitation consume it once.
+	AcceptInvitation(invitation *entities.Invitation) error
+	AddMember(member *entities.Membership) error
+}
+
+// InvitationTransactor runs the writes of accepting an invitation as one
+// unit, as UserTransactor does for users.
+type InvitationTransactor interface {
+	WithinInvitationTransaction(fn func(invitations InvitationRepository, users UserRepository, events UserEventRepository) error) error
+}
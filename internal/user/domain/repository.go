@@ -2,13 +2,46 @@ package domain
 
 import (
 	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+var (
+	// ErrUserNotFound is returned by repositories when no active user matches.
+	ErrUserNotFound = errcode.New(errcode.UserNotFound, "user not found")
+	// ErrUserDeleted is returned when a user would be created with the ID of
+	// a deleted user, which is never reused.
+	ErrUserDeleted = errcode.New(errcode.UserDeleted, "the user with this id was deleted, its id cannot be reused")
+	// ErrVersionMismatch is returned when a user is written at another version
+	// than the one it was read at.
+	ErrVersionMismatch = errcode.New(errcode.VersionMismatch, "the user was changed by another request, read it again and retry")
 )
 
 type UserRepository interface {
 	CreateUser(user *entities.User) error
 	FindUserById(id string) (*entities.User, error)
 	FindUserByEmail(email string) (*entities.User, error)
-	ListUsers(page int) ([]*entities.User, error)
-	PatchUser(user *entities.User) error
-	DeleteUser(id string) error
+	// IsUserDeleted reports whether id is the ID of a deleted user.
+	IsUserDeleted(id string) (bool, error)
+	// FindUsersByIds returns the active users among ids in a single query.
+	// IDs without a match are left out of the result.
+	FindUsersByIds(ids []string) ([]*entities.User, error)
+	// ListUsers returns one page of the users matched by query, in the order
+	// it asks for.
+	ListUsers(query ListUsersQuery) ([]*entities.User, error)
+	// CountUsers returns the number of users matched by the filter of query,
+	// ignoring its pagination.
+	CountUsers(query ListUsersQuery) (int, error)
+	// ExportUsers calls fn with every user matched by the filter of query, in
+	// its order, without loading them all at once. Pagination fields are
+	// ignored. It stops at the first error fn returns and returns it.
+	ExportUsers(query ListUsersQuery, fn func(user *entities.User) error) error
+	// UpdateUser and DeleteUser increment the version of the user. When the
+	// given version is not 0 they only write the user at that version,
+	// failing with ErrVersionMismatch otherwise. UpdateUser sets user.Version
+	// to the new version.
+	//
+	// UpdateUser writes every mutable field of user, the role and empty
+	// values included: an empty last name or region is stored as absent.
+	UpdateUser(user *entities.User) error
+	DeleteUser(id string, version int64) error
 }
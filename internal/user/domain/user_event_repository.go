@@ -0,0 +1,10 @@
+package domain
+
+import (
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+type UserEventRepository interface {
+	AppendUserEvent(event *entities.UserEvent) error
+	ListUserEvents(afterSequence int64, limit int) ([]*entities.UserEvent, error)
+}
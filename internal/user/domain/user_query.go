@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+// UserSortField is a column users can be listed by. Only the fields below are
+// accepted, so they can be written into SQL as they are.
+type UserSortField string
+
+const (
+	SortByID        UserSortField = "id"
+	SortByCreatedAt UserSortField = "created_at"
+	SortByUpdatedAt UserSortField = "updated_at"
+	SortByEmail     UserSortField = "email"
+	SortByFirstName UserSortField = "first_name"
+	SortByLastName  UserSortField = "last_name"
+)
+
+// UserSortFields lists the accepted sort fields.
+var UserSortFields = []UserSortField{SortByID, SortByCreatedAt, SortByUpdatedAt, SortByEmail, SortByFirstName, SortByLastName}
+
+func (f UserSortField) IsValid() bool {
+	for _, field := range UserSortFields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsTime reports whether the field holds a timestamp, which cursors store as
+// RFC 3339 text.
+func (f UserSortField) IsTime() bool {
+	return f == SortByCreatedAt || f == SortByUpdatedAt
+}
+
+// Value returns the value of the field for user, as stored in a cursor.
+func (f UserSortField) Value(user *entities.User) string {
+	switch f {
+	case SortByCreatedAt:
+		return user.CreatedAt.Format(time.RFC3339Nano)
+	case SortByUpdatedAt:
+		return user.UpdatedAt.Format(time.RFC3339Nano)
+	case SortByEmail:
+		return user.Email
+	case SortByFirstName:
+		return user.FirstName
+	case SortByLastName:
+		return user.LastName
+	default:
+		return user.ID
+	}
+}
+
+// DeletedFilter selects users by their deleted state.
+type DeletedFilter string
+
+const (
+	// ExcludeDeleted is the default: only active users.
+	ExcludeDeleted DeletedFilter = ""
+	IncludeDeleted DeletedFilter = "include"
+	OnlyDeleted    DeletedFilter = "only"
+)
+
+// UserFilter narrows a list of users. Zero fields do not filter. Time ranges
+// include their start and exclude their end.
+type UserFilter struct {
+	Role          string
+	EmailDomain   string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	Deleted       DeletedFilter
+}
+
+// UserCursor is the position of the last user of a page: its value of the
+// sort field and its ID, which breaks ties.
+type UserCursor struct {
+	Value string
+	ID    string
+}
+
+// ListUsersQuery selects one page of users. After pages by keyset and should
+// be preferred; Offset is kept for page-number access. An empty SortBy sorts
+// by ID, which is creation order since IDs are ULIDs.
+type ListUsersQuery struct {
+	Filter     UserFilter
+	SortBy     UserSortField
+	Descending bool
+	After      *UserCursor
+	Offset     int
+	Limit      int
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+// MaxSearchTerms caps the words of a search query that are looked up.
+const MaxSearchTerms = 8
+
+// UserSearchHit is an active user matched by a search. A higher Rank is a
+// better match; ranks are only comparable within one search.
+type UserSearchHit struct {
+	User *entities.User
+	Rank float64
+	// Highlights holds first_name, last_name and email as HTML-escaped
+	// text, with the matched parts wrapped in <mark></mark>.
+	Highlights map[string]string
+}
+
+// UserSearchIndex finds active users by partial names and emails, best
+// matches first. Implementations may also tolerate misspellings.
+type UserSearchIndex interface {
+	SearchUsers(query string, limit int) ([]*UserSearchHit, error)
+}
+
+// SearchTerms splits a search query into lower-case words of letters and
+// digits, so that they can be used in any index syntax without escaping.
+func SearchTerms(query string) []string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(terms) > MaxSearchTerms {
+		terms = terms[:MaxSearchTerms]
+	}
+
+	return terms
+}
+
+// UserHighlights highlights terms in the searchable fields of user.
+func UserHighlights(user *entities.User, terms []string) map[string]string {
+	return map[string]string{
+		"first_name": HighlightTerms(user.FirstName, terms),
+		"last_name":  HighlightTerms(user.LastName, terms),
+		"email":      HighlightTerms(user.Email, terms),
+	}
+}
+
+// HighlightTerms escapes value for HTML and marks the start of every word
+// that begins with one of terms.
+func HighlightTerms(value string, terms []string) string {
+	var b strings.Builder
+
+	runes := []rune(value)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		word := string(runes[i:end])
+		matched := longestPrefix(strings.ToLower(word), terms)
+		if matched > 0 {
+			prefix := string([]rune(word)[:matched])
+			b.WriteString("<mark>" + html.EscapeString(prefix) + "</mark>")
+			b.WriteString(html.EscapeString(string([]rune(word)[matched:])))
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+
+		i = end
+	}
+
+	return b.String()
+}
+
+// longestPrefix returns the length in runes of the longest term that word
+// starts with, or 0.
+func longestPrefix(word string, terms []string) int {
+	longest := 0
+	for _, term := range terms {
+		if n := len([]rune(term)); n > longest && strings.HasPrefix(word, term) {
+			longest = n
+		}
+	}
+
+	return longest
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
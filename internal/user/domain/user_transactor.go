@@ -0,0 +1,8 @@
+package domain
+
+// UserTransactor runs several writes as one unit. The repositories given to
+// fn write inside a transaction, which is committed when fn returns nil and
+// rolled back otherwise.
+type UserTransactor interface {
+	WithinTransaction(fn func(users UserRepository, events UserEventRepository) error) error
+}
@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+var errInvalidETag = errcode.New(errcode.InvalidETag, "invalid etag, send back the etag of a previous response")
+
+// grpcCodes maps the HTTP statuses of the error catalogue to gRPC codes.
+// Every status of the catalogue must be listed; unknown ones are Internal.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:             codes.InvalidArgument,
+	http.StatusUnauthorized:           codes.Unauthenticated,
+	http.StatusForbidden:              codes.PermissionDenied,
+	http.StatusNotFound:               codes.NotFound,
+	http.StatusConflict:               codes.AlreadyExists,
+	http.StatusPreconditionFailed:     codes.Aborted,
+	http.StatusGone:                   codes.FailedPrecondition,
+	http.StatusUnprocessableEntity:    codes.FailedPrecondition,
+	http.StatusFailedDependency:       codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge:  codes.ResourceExhausted,
+	http.StatusUnsupportedMediaType:   codes.InvalidArgument,
+	errcode.StatusClientClosedRequest: codes.Canceled,
+	http.StatusServiceUnavailable:     codes.Unavailable,
+	http.StatusGatewayTimeout:         codes.DeadlineExceeded,
+	http.StatusInternalServerError:    codes.Internal,
+}
+
+// toStatus translates an error returned by a usecase into the gRPC status
+// every RPC answers with. The gRPC code is derived from the HTTP status the
+// catalogue gives the error code, for instance:
+//
+//   - NotFound for missing users or invitations
+//   - InvalidArgument with a BadRequest detail naming the field to fix
+//   - AlreadyExists for conflicts such as a taken email
+//   - Aborted when the etag sent no longer matches the user
+//   - Unavailable when the database cannot be reached, so clients may retry
+//   - DeadlineExceeded or Canceled when the call context ended
+//   - Internal for anything else, without leaking the underlying error
+//
+// Every status carries an ErrorInfo detail whose reason is the error code.
+// Errors that already carry a status are returned unchanged.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := errcode.Of(err)
+	grpcCode, ok := grpcCodes[code.Definition().Status]
+	if !ok {
+		grpcCode = codes.Internal
+	}
+
+	switch grpcCode {
+	case codes.InvalidArgument:
+		return invalidArgument(err, errcode.FieldOf(err))
+
+	case codes.Unavailable:
+		log.Printf("gRPC storage unavailable: %v", err)
+		return withErrorInfo(status.New(grpcCode, "the service is temporarily unavailable, please retry"), code)
+
+	case codes.Internal:
+		log.Printf("gRPC internal error: %v", err)
+		return withErrorInfo(status.New(grpcCode, "internal error"), code)
+	}
+
+	return withErrorInfo(status.New(grpcCode, err.Error()), code)
+}
+
+// invalidArgument is the InvalidArgument status of err, with a BadRequest
+// detail naming field.
+func invalidArgument(err error, field string) error {
+	return withErrorInfo(status.New(codes.InvalidArgument, err.Error()), errcode.Of(err), &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: field, Description: err.Error()},
+		},
+	})
+}
+
+// withErrorInfo adds an ErrorInfo detail with the error code to st, then the
+// other details.
+func withErrorInfo(st *status.Status, code errcode.Code, details ...protoadapt.MessageV1) error {
+	details = append([]protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: string(code), Domain: errcode.Domain}}, details...)
+
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
@@ -0,0 +1,96 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus_Codes(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"not found", usecase.ErrUserNotFound, codes.NotFound},
+		{"email taken", usecase.ErrEmailAlreadyExists, codes.AlreadyExists},
+		{"validation", entities.ErrFirstNameTooShort, codes.InvalidArgument},
+		{"page", usecase.ErrInvalidPageNumber, codes.InvalidArgument},
+		{"update mask", fmt.Errorf("%w: role", usecase.ErrInvalidUpdateMask), codes.InvalidArgument},
+		{"wrong password", usecase.ErrInvalidCredentials, codes.Unauthenticated},
+		{"expired", usecase.ErrInvitationExpired, codes.FailedPrecondition},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{"canceled", context.Canceled, codes.Canceled},
+		{"connection refused", &pq.Error{Code: "08006"}, codes.Unavailable},
+		{"shutdown", &pq.Error{Code: "57P01"}, codes.Unavailable},
+		{"syntax error", &pq.Error{Code: "42601"}, codes.Internal},
+		{"unknown", errors.New("boom"), codes.Internal},
+		{"status", status.Error(codes.PermissionDenied, "denied"), codes.PermissionDenied},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.code, status.Code(toStatus(c.err)))
+		})
+	}
+}
+
+// TestGrpcCodes_CoverCatalogue fails when an entry of the error catalogue has
+// a status without a gRPC code, which would be answered as Internal.
+func TestGrpcCodes_CoverCatalogue(t *testing.T) {
+	for _, code := range errcode.Codes() {
+		status := code.Definition().Status
+		_, ok := grpcCodes[status]
+		assert.True(t, ok, "%s: status %d has no gRPC code", code, status)
+	}
+
+	assert.Equal(t, codes.InvalidArgument, grpcCodes[errcode.UnsupportedMediaType.Definition().Status])
+}
+
+func TestToStatus_FieldViolations(t *testing.T) {
+	// Validation errors carry a BadRequest detail naming the field
+	_, err := entities.NewUser("Alice", "Johnson", "invalid-email", "password123", "")
+
+	st := status.Convert(toStatus(err))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Len(t, st.Details(), 2)
+
+	// The ErrorInfo detail comes first
+	badRequest, ok := st.Details()[1].(*errdetails.BadRequest)
+	assert.True(t, ok)
+	assert.Equal(t, "email", badRequest.FieldViolations[0].Field)
+}
+
+func TestToStatus_ErrorInfo(t *testing.T) {
+	// Every status names its error code in an ErrorInfo detail
+	for err, reason := range map[error]string{
+		usecase.ErrEmailAlreadyExists: "user.email_taken",
+		entities.ErrPasswordTooShort:  "user.validation.password_too_short",
+		errors.New("boom"):            "internal",
+	} {
+		st := status.Convert(toStatus(err))
+		if assert.NotEmpty(t, st.Details()) {
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			assert.True(t, ok)
+			assert.Equal(t, reason, info.GetReason())
+			assert.Equal(t, "titan", info.GetDomain())
+		}
+	}
+}
+
+func TestToStatus_InternalHidesCause(t *testing.T) {
+	// Internal failures never leak the underlying error to clients
+	st := status.Convert(toStatus(errors.New("pq: password authentication failed for user titan")))
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.NotContains(t, st.Message(), "password")
+}
@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+)
+
+// ExportUsers sends every matching user as it is read from the database. A
+// failure midway ends the stream with an error status, after the users
+// already sent.
+func (s *userGrpcServer) ExportUsers(req *pb.ExportUsersRequest, stream pb.UserService_ExportUsersServer) error {
+	if err := authorize(stream.Context(), s.policy, "user.export", nil); err != nil {
+		return err
+	}
+
+	mask, err := newReadMask(req.ReadMask, &pb.User{})
+	if err != nil {
+		return toStatus(err)
+	}
+
+	err = s.exportUsers.Execute(usecase.ExportUsersRequest{
+		Sort:  req.Sort,
+		Order: req.Order,
+		Filter: domain.UserFilter{
+			Role:          req.Role,
+			EmailDomain:   req.EmailDomain,
+			CreatedAfter:  fromTimestamp(req.CreatedAfter),
+			CreatedBefore: fromTimestamp(req.CreatedBefore),
+			UpdatedAfter:  fromTimestamp(req.UpdatedAfter),
+			UpdatedBefore: fromTimestamp(req.UpdatedBefore),
+			Deleted:       deletedFilter(req.Deleted),
+		},
+	}, func(user *dto.UserResponseDTO) error {
+		protoUser := toProtoUser(user)
+		mask.apply(protoUser)
+
+		return stream.Send(protoUser)
+	})
+
+	return toStatus(err)
+}
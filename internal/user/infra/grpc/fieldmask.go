@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+var errInvalidReadMask = errcode.New(errcode.InvalidReadMask, "invalid read mask")
+
+// readMask keeps the top-level fields named by a request's read_mask. A nil
+// readMask keeps everything.
+type readMask map[string]bool
+
+// newReadMask validates mask against the message type it will be applied to.
+func newReadMask(mask *fieldmaskpb.FieldMask, message proto.Message) (readMask, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, nil
+	}
+
+	fields := message.ProtoReflect().Descriptor().Fields()
+	keep := readMask{}
+
+	for _, path := range mask.GetPaths() {
+		// Nested paths such as created_at.seconds keep the whole field.
+		name, _, _ := strings.Cut(path, ".")
+		if fields.ByName(protoreflect.Name(name)) == nil {
+			return nil, fmt.Errorf("%w: unknown field %q", errInvalidReadMask, path)
+		}
+
+		keep[name] = true
+	}
+
+	return keep, nil
+}
+
+// apply clears every field of message that is not part of the mask.
+func (m readMask) apply(message proto.Message) {
+	if m == nil || message == nil {
+		return
+	}
+
+	reflected := message.ProtoReflect()
+	reflected.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !m[string(field.Name())] {
+			reflected.Clear(field)
+		}
+		return true
+	})
+}
+
+// updatePaths are the paths of an UpdateUser mask that name fields to write.
+// id and etag identify the user and the version the update expects, they are
+// not written: the gateway derives the mask from the body of PATCH /v1/users
+// and lists them whenever the body sets them, so they are dropped rather than
+// refused as not updatable.
+func updatePaths(mask *fieldmaskpb.FieldMask) []string {
+	var paths []string
+	for _, path := range mask.GetPaths() {
+		if path == "id" || path == "etag" {
+			continue
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths
+}
@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"testing"
+
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestReadMask_KeepsNamedFields(t *testing.T) {
+	mask, err := newReadMask(&fieldmaskpb.FieldMask{Paths: []string{"id", "email", "created_at.seconds"}}, &pb.User{})
+	require.NoError(t, err)
+
+	user := &pb.User{
+		Id:        "1",
+		FirstName: "John",
+		LastName:  "Lennon",
+		Email:     "john.lennon@example.com",
+		Role:      "user",
+		CreatedAt: timestamppb.Now(),
+		UpdatedAt: timestamppb.Now(),
+	}
+	mask.apply(user)
+
+	assert.Equal(t, "1", user.Id)
+	assert.Equal(t, "john.lennon@example.com", user.Email)
+	assert.NotNil(t, user.CreatedAt)
+	assert.Empty(t, user.FirstName)
+	assert.Empty(t, user.Role)
+	assert.Nil(t, user.UpdatedAt)
+}
+
+func TestReadMask_EmptyKeepsEverything(t *testing.T) {
+	mask, err := newReadMask(nil, &pb.User{})
+	require.NoError(t, err)
+
+	user := &pb.User{Id: "1", FirstName: "John"}
+	mask.apply(user)
+
+	assert.Equal(t, "John", user.FirstName)
+}
+
+func TestReadMask_UnknownField(t *testing.T) {
+	_, err := newReadMask(&fieldmaskpb.FieldMask{Paths: []string{"password"}}, &pb.User{})
+
+	st := status.Convert(toStatus(err))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Len(t, st.Details(), 2)
+
+	badRequest, ok := st.Details()[1].(*errdetails.BadRequest)
+	assert.True(t, ok)
+	assert.Equal(t, "read_mask", badRequest.FieldViolations[0].Field)
+}
@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultCallTimeout    = 30 * time.Second
+	DefaultMaxRecvMsgSize = 4 << 20
+	DefaultMaxSendMsgSize = 4 << 20
+)
+
+// ServerConfig selects the interceptors and limits applied to every call.
+// A nil Logger or Metrics and a zero DefaultTimeout switch that part of the
+// chain off.
+type ServerConfig struct {
+	Logger   *slog.Logger
+	Metrics  *Metrics
+	Recovery bool
+	// DefaultTimeout is the deadline given to unary calls whose client did
+	// not send one. Streams such as WatchUsers are meant to stay open and
+	// are left alone.
+	DefaultTimeout time.Duration
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+	// Identity decides which identity metadata to believe. When nil, every
+	// caller is anonymous.
+	Identity *policy.IdentityVerifier
+}
+
+// DefaultServerConfig enables the whole chain with the default limits.
+func DefaultServerConfig(logger *slog.Logger) ServerConfig {
+	return ServerConfig{
+		Logger:         logger,
+		Metrics:        NewMetrics(),
+		Recovery:       true,
+		DefaultTimeout: DefaultCallTimeout,
+		MaxRecvMsgSize: DefaultMaxRecvMsgSize,
+		MaxSendMsgSize: DefaultMaxSendMsgSize,
+	}
+}
+
+// ServerOptions turns the configuration into grpc.NewServer options. The
+// interceptors run in this order: metrics, logging, recovery, deadline,
+// identity, so a recovered panic is still counted and logged as Internal.
+func (c ServerConfig) ServerOptions() []grpc.ServerOption {
+	var (
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
+	)
+
+	if c.Metrics != nil {
+		unary = append(unary, c.Metrics.unaryInterceptor())
+		stream = append(stream, c.Metrics.streamInterceptor())
+	}
+
+	if c.Logger != nil {
+		unary = append(unary, loggingUnaryInterceptor(c.Logger))
+		stream = append(stream, loggingStreamInterceptor(c.Logger))
+	}
+
+	if c.Recovery {
+		unary = append(unary, recoveryUnaryInterceptor(c.Logger))
+		stream = append(stream, recoveryStreamInterceptor(c.Logger))
+	}
+
+	if c.DefaultTimeout > 0 {
+		unary = append(unary, deadlineUnaryInterceptor(c.DefaultTimeout))
+	}
+
+	unary = append(unary, identityUnaryInterceptor(c.Identity))
+	stream = append(stream, identityStreamInterceptor(c.Identity))
+
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+
+	if c.MaxRecvMsgSize > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(c.MaxRecvMsgSize))
+	}
+
+	if c.MaxSendMsgSize > 0 {
+		options = append(options, grpc.MaxSendMsgSize(c.MaxSendMsgSize))
+	}
+
+	return options
+}
+
+func recoveryUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(logger, info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func recoveryStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(logger, info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovered(logger *slog.Logger, method string, r interface{}) error {
+	if logger != nil {
+		logger.Error("gRPC panic recovered", "method", method, "panic", r, "stack", string(debug.Stack()))
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+func loggingUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+func loggingStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func logCall(ctx context.Context, logger *slog.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+
+	attrs := []any{
+		"method", method,
+		"code", code.String(),
+		"duration_ms", time.Since(start).Milliseconds(),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, "peer", p.Addr.String())
+	}
+	if err != nil {
+		attrs = append(attrs, "error", status.Convert(err).Message())
+	}
+
+	level := slog.LevelInfo
+	if code == codes.Internal || code == codes.Unknown || code == codes.Unavailable {
+		level = slog.LevelError
+	}
+
+	logger.Log(ctx, level, "gRPC call", attrs...)
+}
+
+func deadlineUnaryInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+// Metrics counts calls per method and status code. The counters are plain
+// expvar values, so they can be published under /debug/vars.
+type Metrics struct {
+	// Calls holds "<method> <code>" counters.
+	Calls *expvar.Map
+	// Latency holds the total milliseconds spent per method.
+	Latency *expvar.Map
+	// InFlight is the number of calls currently running.
+	InFlight *expvar.Int
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		Calls:    new(expvar.Map).Init(),
+		Latency:  new(expvar.Map).Init(),
+		InFlight: new(expvar.Int),
+	}
+}
+
+// Publish exposes the counters through expvar under name. It panics, like
+// expvar.Publish, if name is already taken.
+func (m *Metrics) Publish(name string) {
+	metrics := new(expvar.Map).Init()
+	metrics.Set("calls", m.Calls)
+	metrics.Set("latency_ms", m.Latency)
+	metrics.Set("in_flight", m.InFlight)
+
+	expvar.Publish(name, metrics)
+}
+
+func (m *Metrics) observe(method string, start time.Time, err error) {
+	m.Calls.Add(method+" "+status.Code(err).String(), 1)
+	m.Latency.Add(method, time.Since(start).Milliseconds())
+}
+
+func (m *Metrics) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		m.InFlight.Add(1)
+		defer m.InFlight.Add(-1)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+func (m *Metrics) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		m.InFlight.Add(1)
+		defer m.InFlight.Add(-1)
+
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observe(info.FullMethod, start, err)
+
+		return err
+	}
+}
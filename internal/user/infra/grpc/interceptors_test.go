@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/policy"
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// chainTestServer answers just enough RPCs to exercise the interceptors.
+type chainTestServer struct {
+	pb.UnimplementedUserServiceServer
+	deadline time.Duration
+	caller   policy.Identity
+}
+
+func (s *chainTestServer) GetUserByID(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	if req.Id == "panic" {
+		panic("boom")
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		s.deadline = time.Until(deadline)
+	}
+	s.caller = identityOf(ctx)
+
+	return &pb.GetUserResponse{Id: req.Id}, nil
+}
+
+func (s *chainTestServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	return &pb.User{Email: req.Email}, nil
+}
+
+func (s *chainTestServer) WatchUsers(req *pb.WatchUsersRequest, stream pb.UserService_WatchUsersServer) error {
+	panic("stream boom")
+}
+
+func startChainTestServer(t *testing.T, config ServerConfig) (pb.UserServiceClient, *chainTestServer) {
+	listener := bufconn.Listen(1 << 20)
+
+	server := grpc.NewServer(config.ServerOptions()...)
+	service := &chainTestServer{}
+	pb.RegisterUserServiceServer(server, service)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewUserServiceClient(conn), service
+}
+
+func TestInterceptors_RecoverPanics(t *testing.T) {
+	var logs bytes.Buffer
+	config := DefaultServerConfig(slog.New(slog.NewJSONHandler(&logs, nil)))
+	client, _ := startChainTestServer(t, config)
+
+	_, err := client.GetUserByID(context.Background(), &pb.GetUserRequest{Id: "panic"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	stream, err := client.WatchUsers(context.Background(), &pb.WatchUsersRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// The panic is still counted and logged once the handler is recovered.
+	assert.Equal(t, "1", config.Metrics.Calls.Get("/user.UserService/GetUserByID Internal").String())
+	assert.Equal(t, "1", config.Metrics.Calls.Get("/user.UserService/WatchUsers Internal").String())
+	assert.Contains(t, logs.String(), `"msg":"gRPC panic recovered"`)
+	assert.Contains(t, logs.String(), `"method":"/user.UserService/GetUserByID","code":"Internal"`)
+}
+
+func TestInterceptors_LogsAndCountsCalls(t *testing.T) {
+	var logs bytes.Buffer
+	config := DefaultServerConfig(slog.New(slog.NewJSONHandler(&logs, nil)))
+	client, _ := startChainTestServer(t, config)
+
+	_, err := client.GetUserByID(context.Background(), &pb.GetUserRequest{Id: "1"})
+	require.NoError(t, err)
+
+	_, err = client.ListUsers(context.Background(), &pb.ListUsersRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	assert.Equal(t, "1", config.Metrics.Calls.Get("/user.UserService/GetUserByID OK").String())
+	assert.Equal(t, "1", config.Metrics.Calls.Get("/user.UserService/ListUsers Unimplemented").String())
+	assert.Equal(t, int64(0), config.Metrics.InFlight.Value())
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"msg":"gRPC call","method":"/user.UserService/GetUserByID","code":"OK"`)
+}
+
+func TestInterceptors_DefaultDeadline(t *testing.T) {
+	config := ServerConfig{DefaultTimeout: 2 * time.Second}
+	client, service := startChainTestServer(t, config)
+
+	// Without a client deadline the default one is applied.
+	_, err := client.GetUserByID(context.Background(), &pb.GetUserRequest{Id: "1"})
+	require.NoError(t, err)
+	assert.InDelta(t, 2*time.Second, service.deadline, float64(500*time.Millisecond))
+
+	// A deadline sent by the client is kept.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = client.GetUserByID(ctx, &pb.GetUserRequest{Id: "1"})
+	require.NoError(t, err)
+	assert.Greater(t, service.deadline, 5*time.Second)
+}
+
+func TestInterceptors_Identity(t *testing.T) {
+	verifier := policy.NewIdentityVerifier("gateway-secret")
+	client, service := startChainTestServer(t, ServerConfig{Identity: verifier})
+
+	admin := policy.Identity{ID: "1", Role: "admin", Attributes: map[string]string{"region": "eu"}}
+	call := func(signature string) policy.Identity {
+		ctx := metadata.AppendToOutgoingContext(context.Background(),
+			"x-user-id", admin.ID, "x-user-role", admin.Role, "x-subject-region", "eu", "x-user-signature", signature)
+		_, err := client.GetUserByID(ctx, &pb.GetUserRequest{Id: "1"})
+		require.NoError(t, err)
+
+		return service.caller
+	}
+
+	assert.Equal(t, admin, call(verifier.Sign(admin)))
+
+	// Metadata the gateway did not sign is not believed.
+	assert.Equal(t, policy.Identity{}, call("forged"))
+}
+
+func TestInterceptors_MaxMessageSize(t *testing.T) {
+	config := ServerConfig{MaxRecvMsgSize: 1024}
+	client, _ := startChainTestServer(t, config)
+
+	_, err := client.CreateUser(context.Background(), &pb.CreateUserRequest{Email: "john@example.com"})
+	require.NoError(t, err)
+
+	_, err = client.CreateUser(context.Background(), &pb.CreateUserRequest{Email: strings.Repeat("a", 2048)})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
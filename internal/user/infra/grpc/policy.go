@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jonattasmoraes/titan/internal/policy"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+var errPermissionDenied = errcode.New(errcode.Forbidden, "you are not allowed to perform this operation")
+
+// authorize asks the policy engine about the current call. Without an engine
+// every call is allowed. Calls the HTTP API makes on behalf of its requests
+// carry the environment of the HTTP request instead of the one of the call.
+func authorize(ctx context.Context, engine *policy.Engine, action string, resource map[string]interface{}) error {
+	if engine == nil {
+		return nil
+	}
+
+	environment, ok := policy.EnvironmentFrom(ctx)
+	if !ok {
+		environment = map[string]interface{}{"transport": "grpc"}
+		if method, ok := grpc.Method(ctx); ok {
+			environment["method"] = method
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			environment["ip"] = p.Addr.String()
+		}
+	}
+
+	decision := engine.Evaluate(policy.Request{
+		Action:      action,
+		Subject:     identityOf(ctx).Subject(),
+		Resource:    resource,
+		Environment: environment,
+	})
+
+	if !decision.Allowed {
+		return toStatus(errPermissionDenied)
+	}
+
+	return nil
+}
+
+// authorizeRead is authorize for reading user. A denied read fails with
+// NotFound, as a missing user does, so that callers cannot tell the users
+// they may not read from the ones that do not exist.
+func authorizeRead(ctx context.Context, engine *policy.Engine, user *dto.UserResponseDTO) error {
+	if err := authorize(ctx, engine, "user.read", policy.UserResource(user)); err != nil {
+		return toStatus(usecase.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// identityOf is the caller of the call, anonymous without the identity
+// interceptors or a caller set by the HTTP API.
+func identityOf(ctx context.Context) policy.Identity {
+	return policy.IdentityFrom(ctx)
+}
+
+// authenticate decides who the caller is, from the identity metadata
+// verifier believes.
+func authenticate(ctx context.Context, verifier *policy.IdentityVerifier) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	identity := policy.Identity{
+		ID:   first(strings.ToLower(policy.HeaderUserID)),
+		Role: first(strings.ToLower(policy.HeaderUserRole)),
+	}
+
+	prefix := strings.ToLower(policy.HeaderSubjectPrefix)
+	for key, values := range md {
+		if len(values) > 0 && strings.HasPrefix(key, prefix) {
+			if identity.Attributes == nil {
+				identity.Attributes = map[string]string{}
+			}
+			identity.Attributes[strings.TrimPrefix(key, prefix)] = values[0]
+		}
+	}
+
+	signature := first(strings.ToLower(policy.HeaderUserSignature))
+
+	return policy.WithIdentity(ctx, verifier.Authenticate(identity, signature))
+}
+
+func identityUnaryInterceptor(verifier *policy.IdentityVerifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(authenticate(ctx, verifier), req)
+	}
+}
+
+func identityStreamInterceptor(verifier *policy.IdentityVerifier) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: authenticate(ss.Context(), verifier)})
+	}
+}
+
+// authenticatedStream is a stream whose context holds the caller.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jonattasmoraes/titan/internal/policy"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizeRead_DeniedLooksMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+version: "1"
+default: deny
+rules:
+  - name: self-service
+    effect: allow
+    actions: ["user.read"]
+    condition: subject.id == resource.id
+`), 0o644))
+
+	engine, err := policy.NewEngine(path, nil)
+	require.NoError(t, err)
+
+	ctx := policy.WithIdentity(context.Background(), policy.Identity{ID: "1", Role: "user"})
+
+	// Reading oneself is allowed
+	assert.NoError(t, authorizeRead(ctx, engine, &dto.UserResponseDTO{ID: "1"}))
+
+	// Reading another user fails as if the user did not exist
+	err = authorizeRead(ctx, engine, &dto.UserResponseDTO{ID: "2"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Without an engine every read is allowed
+	assert.NoError(t, authorizeRead(ctx, nil, &dto.UserResponseDTO{ID: "2"}))
+}
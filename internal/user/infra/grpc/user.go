@@ -2,28 +2,77 @@ package grpc
 
 import (
 	"context"
+	"time"
 
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
 	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
 	"github.com/jonattasmoraes/titan/internal/user/usecase"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/jonattasmoraes/titan/internal/utils"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type userGrpcServer struct {
 	pb.UnimplementedUserServiceServer
-	userService *usecase.GetUserByIdUsecase
+	createUser     *usecase.CreateUserUsecase
+	getUserById    *usecase.GetUserByIdUsecase
+	getUserByEmail *usecase.GetUserByEmailUsecase
+	batchGetUsers  *usecase.BatchGetUsersUsecase
+	listUsers      *usecase.ListUsersUsecase
+	exportUsers    *usecase.ExportUsersUsecase
+	updateUser     *usecase.UpdateUserUsecase
+	deleteUser     *usecase.DeleteUserUsecase
+	listUserEvents *usecase.ListUserEventsUsecase
+	policy         *policy.Engine
+	shutdown       context.Context
 }
 
-func NewUserGrpcServer(userService *usecase.GetUserByIdUsecase) *userGrpcServer {
+// NewUserGrpcServer serves the usecases over gRPC. WatchUsers streams end
+// when shutdown is done, so that they do not hold up the graceful stop of
+// the server they run on.
+func NewUserGrpcServer(
+	createUser *usecase.CreateUserUsecase,
+	getUserById *usecase.GetUserByIdUsecase,
+	getUserByEmail *usecase.GetUserByEmailUsecase,
+	batchGetUsers *usecase.BatchGetUsersUsecase,
+	listUsers *usecase.ListUsersUsecase,
+	exportUsers *usecase.ExportUsersUsecase,
+	updateUser *usecase.UpdateUserUsecase,
+	deleteUser *usecase.DeleteUserUsecase,
+	listUserEvents *usecase.ListUserEventsUsecase,
+	policy *policy.Engine,
+	shutdown context.Context,
+) *userGrpcServer {
 	return &userGrpcServer{
-		userService: userService,
+		createUser:     createUser,
+		getUserById:    getUserById,
+		getUserByEmail: getUserByEmail,
+		batchGetUsers:  batchGetUsers,
+		listUsers:      listUsers,
+		exportUsers:    exportUsers,
+		updateUser:     updateUser,
+		deleteUser:     deleteUser,
+		listUserEvents: listUserEvents,
+		policy:         policy,
+		shutdown:       shutdown,
 	}
 }
 
 func (s *userGrpcServer) GetUserByID(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
-	user, err := s.userService.Execute(req.Id)
+	mask, err := newReadMask(req.ReadMask, &pb.GetUserResponse{})
 	if err != nil {
-		return nil, status.Error(codes.NotFound, err.Error())
+		return nil, toStatus(err)
+	}
+
+	user, err := s.getUserById.Execute(req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := authorizeRead(ctx, s.policy, user); err != nil {
+		return nil, err
 	}
 
 	response := &pb.GetUserResponse{
@@ -31,7 +80,289 @@ func (s *userGrpcServer) GetUserByID(ctx context.Context, req *pb.GetUserRequest
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		Role:      user.Role,
+		Email:     user.Email,
+		CreatedAt: toTimestamp(user.CreatedTime),
+		UpdatedAt: toTimestamp(user.UpdatedTime),
+		Etag:      toETag(user.Version),
+		Region:    user.Region,
 	}
+	mask.apply(response)
 
 	return response, nil
 }
+
+func (s *userGrpcServer) GetUserByEmail(ctx context.Context, req *pb.GetUserByEmailRequest) (*pb.User, error) {
+	mask, err := newReadMask(req.ReadMask, &pb.User{})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	user, err := s.getUserByEmail.Execute(req.Email)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := authorizeRead(ctx, s.policy, user); err != nil {
+		return nil, err
+	}
+
+	response := toProtoUser(user)
+	mask.apply(response)
+
+	return response, nil
+}
+
+func (s *userGrpcServer) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	mask, err := newReadMask(req.ReadMask, &pb.User{})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	users, missing, err := s.batchGetUsers.Execute(req.Ids)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	// Users the caller may not read are reported missing, as GetUserByID
+	// answers NotFound for them.
+	response := &pb.BatchGetUsersResponse{MissingIds: missing}
+	for _, user := range users {
+		if err := authorizeRead(ctx, s.policy, user); err != nil {
+			response.MissingIds = append(response.MissingIds, user.ID)
+			continue
+		}
+
+		protoUser := toProtoUser(user)
+		mask.apply(protoUser)
+		response.Users = append(response.Users, protoUser)
+	}
+
+	return response, nil
+}
+
+func (s *userGrpcServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	resource := policy.UserResource(&dto.UserResponseDTO{Email: req.Email, Role: "user", Region: req.Region})
+	if err := authorize(ctx, s.policy, "user.create", resource); err != nil {
+		return nil, err
+	}
+
+	user, err := s.createUser.Execute(&dto.UserRequestDTO{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Password:  req.Password,
+		Region:    req.Region,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (s *userGrpcServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	if err := authorize(ctx, s.policy, "user.list", nil); err != nil {
+		return nil, err
+	}
+
+	mask, err := newReadMask(req.ReadMask, &pb.User{})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if req.Page < 0 {
+		return nil, toStatus(usecase.ErrInvalidPageNumber)
+	}
+
+	page, err := s.listUsers.Execute(usecase.ListUsersRequest{
+		Page:         int(req.Page),
+		PageSize:     int(req.PageSize),
+		Cursor:       req.Cursor,
+		IncludeTotal: req.IncludeTotal,
+		Sort:         req.Sort,
+		Order:        req.Order,
+		Filter: domain.UserFilter{
+			Role:          req.Role,
+			EmailDomain:   req.EmailDomain,
+			CreatedAfter:  fromTimestamp(req.CreatedAfter),
+			CreatedBefore: fromTimestamp(req.CreatedBefore),
+			UpdatedAfter:  fromTimestamp(req.UpdatedAfter),
+			UpdatedBefore: fromTimestamp(req.UpdatedBefore),
+			Deleted:       deletedFilter(req.Deleted),
+		},
+	})
+	if err == usecase.ErrUsersNotFound {
+		return &pb.ListUsersResponse{}, nil
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListUsersResponse{NextCursor: page.NextCursor}
+	if page.TotalCount != nil {
+		total := int64(*page.TotalCount)
+		response.TotalCount = &total
+	}
+
+	for _, user := range page.Users {
+		protoUser := toProtoUser(user)
+		mask.apply(protoUser)
+		response.Users = append(response.Users, protoUser)
+	}
+
+	return response, nil
+}
+
+func (s *userGrpcServer) PatchUser(ctx context.Context, req *pb.PatchUserRequest) (*pb.User, error) {
+	if err := s.authorizeTarget(ctx, "user.update", req.Id); err != nil {
+		return nil, err
+	}
+
+	version, err := fromETag(req.Etag, "etag")
+	if err != nil {
+		return nil, err
+	}
+
+	// A patch is an update of its non-empty fields, validated the same way.
+	user, err := s.updateUser.Execute(&entities.User{
+		ID:        req.Id,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Region:    req.Region,
+		Version:   version,
+	}, nil)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (s *userGrpcServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	if req.User == nil {
+		return nil, invalidArgument(entities.ErrAtLeastOneParam, "user")
+	}
+
+	if err := s.authorizeTarget(ctx, "user.update", req.User.Id); err != nil {
+		return nil, err
+	}
+
+	version, err := fromETag(req.User.Etag, "user.etag")
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.updateUser.Execute(&entities.User{
+		ID:        req.User.Id,
+		FirstName: req.User.FirstName,
+		LastName:  req.User.LastName,
+		Email:     req.User.Email,
+		Region:    req.User.Region,
+		Version:   version,
+	}, updatePaths(req.UpdateMask))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (s *userGrpcServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.User, error) {
+	if err := s.authorizeTarget(ctx, "user.delete", req.Id); err != nil {
+		return nil, err
+	}
+
+	version, err := fromETag(req.Etag, "etag")
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.deleteUser.Execute(req.Id, version)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+// authorizeTarget loads the user a call is about so the policy can look at
+// its attributes before anything is changed.
+func (s *userGrpcServer) authorizeTarget(ctx context.Context, action string, id string) error {
+	if s.policy == nil {
+		return nil
+	}
+
+	target, err := s.getUserById.Execute(id)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	return authorize(ctx, s.policy, action, policy.UserResource(target))
+}
+
+func toProtoUser(user *dto.UserResponseDTO) *pb.User {
+	return &pb.User{
+		Id:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: toTimestamp(user.CreatedTime),
+		UpdatedAt: toTimestamp(user.UpdatedTime),
+		Etag:      toETag(user.Version),
+		Region:    user.Region,
+		DeletedAt: toTimestamp(user.DeletedTime),
+	}
+}
+
+func toETag(version int64) string {
+	if version == 0 {
+		return ""
+	}
+
+	return utils.ETag(version)
+}
+
+// fromETag returns the version of an etag sent by a client, 0 when it is
+// empty.
+func fromETag(etag string, field string) (int64, error) {
+	if etag == "" {
+		return 0, nil
+	}
+
+	version, ok := utils.ParseETag(etag)
+	if !ok {
+		return 0, invalidArgument(errInvalidETag, field)
+	}
+
+	return version, nil
+}
+
+// toTimestamp converts a time of the usecases, nil when it is unset.
+func toTimestamp(value time.Time) *timestamppb.Timestamp {
+	if value.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(value)
+}
+
+// fromTimestamp returns the zero time, meaning no bound, for unset
+// timestamps.
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
+
+// deletedFilter accepts "exclude" as a name for the default filter.
+func deletedFilter(value string) domain.DeletedFilter {
+	if value == "exclude" {
+		return domain.ExcludeDeleted
+	}
+
+	return domain.DeletedFilter(value)
+}
@@ -0,0 +1,18 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToTimestamp(t *testing.T) {
+	// The instant is kept as is, whatever the zone of the server, with its
+	// fractional seconds
+	created := time.Date(2024, 6, 5, 13, 4, 5, 123456789, time.FixedZone("BRT", -3*60*60))
+	assert.True(t, created.Equal(toTimestamp(created).AsTime()))
+
+	// An unset time has no timestamp
+	assert.Nil(t, toTimestamp(time.Time{}))
+}
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	watchBatchSize    = 100
+	watchPollInterval = time.Second
+)
+
+var eventTypes = map[string]pb.UserEvent_EventType{
+	entities.UserCreated: pb.UserEvent_EVENT_TYPE_CREATED,
+	entities.UserUpdated: pb.UserEvent_EVENT_TYPE_UPDATED,
+	entities.UserDeleted: pb.UserEvent_EVENT_TYPE_DELETED,
+}
+
+// WatchUsers replays the change log after the request cursor and then polls
+// for new events until the client goes away or the server shuts down, which
+// ends the stream with UNAVAILABLE. Every event carries the cursor a
+// reconnecting client should send to continue where it stopped.
+func (s *userGrpcServer) WatchUsers(req *pb.WatchUsersRequest, stream pb.UserService_WatchUsersServer) error {
+	ctx := stream.Context()
+
+	if err := authorize(ctx, s.policy, "user.watch", nil); err != nil {
+		return err
+	}
+
+	cursor := req.Cursor
+
+	for {
+		events, next, err := s.listUserEvents.Execute(cursor, watchBatchSize)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		for _, event := range events {
+			err := stream.Send(&pb.UserEvent{
+				Cursor:     event.Cursor,
+				Type:       eventTypes[event.Type],
+				User:       toProtoUser(event.User),
+				OccurredAt: toTimestamp(event.OccurredTime),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		cursor = next
+
+		if len(events) == watchBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.shutdown.Done():
+			return status.Error(codes.Unavailable, "the server is shutting down, reconnect with the last cursor")
+		case <-time.After(watchPollInterval):
+		}
+	}
+}
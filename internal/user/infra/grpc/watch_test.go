@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type noUserEvents struct{}
+
+func (noUserEvents) AppendUserEvent(*entities.UserEvent) error { return nil }
+
+func (noUserEvents) ListUserEvents(int64, int) ([]*entities.UserEvent, error) { return nil, nil }
+
+// watchStream is a WatchUsers stream whose client never goes away.
+type watchStream struct {
+	grpc.ServerStream
+}
+
+func (watchStream) Context() context.Context { return context.Background() }
+
+func (watchStream) Send(*pb.UserEvent) error { return nil }
+
+func TestWatchUsers_EndsOnShutdown(t *testing.T) {
+	shutdown, stop := context.WithCancel(context.Background())
+	s := NewUserGrpcServer(nil, nil, nil, nil, nil, nil, nil, nil, usecase.NewListUserEventsUsecase(noUserEvents{}), nil, shutdown)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchUsers(&pb.WatchUsersRequest{}, watchStream{})
+	}()
+
+	stop()
+
+	select {
+	case err := <-done:
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	case <-time.After(time.Second):
+		t.Fatal("WatchUsers kept polling after the shutdown")
+	}
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/jonattasmoraes/titan/internal/utils"
+)
+
+func setUserETag(ctx *gin.Context, version int64) {
+	if version != 0 {
+		ctx.Header("ETag", utils.ETag(version))
+	}
+}
+
+// etagOf is the etag of an RPC request for version, empty when any version
+// will do.
+func etagOf(version int64) string {
+	if version == 0 {
+		return ""
+	}
+
+	return utils.ETag(version)
+}
+
+// entityTags returns the entity tags listed in the given header, which may be
+// repeated, and whether it was "*".
+func entityTags(ctx *gin.Context, header string) ([]string, bool) {
+	var tags []string
+	for _, value := range ctx.Request.Header.Values(header) {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" {
+				return nil, true
+			}
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags, false
+}
+
+// notModified answers 304 when the If-None-Match header of a GET lists the
+// current entity tag of the user. Weak tags match as well.
+func notModified(ctx *gin.Context, version int64) bool {
+	tags, wildcard := entityTags(ctx, "If-None-Match")
+
+	match := wildcard
+	for _, tag := range tags {
+		if strings.TrimPrefix(tag, "W/") == utils.ETag(version) {
+			match = true
+		}
+	}
+
+	if match {
+		setUserETag(ctx, version)
+		ctx.Status(http.StatusNotModified)
+	}
+
+	return match
+}
+
+// ifMatchVersion returns the version the If-Match header of a write expects
+// the user to be at, or 0 when any version will do. Only strong tags can
+// match. When several tags are listed, the current version is looked up and
+// used if it is one of them. When nothing can match, it answers 412 and
+// returns false.
+func (h *UserHandler) ifMatchVersion(ctx *gin.Context, id string) (int64, bool) {
+	tags, wildcard := entityTags(ctx, "If-Match")
+	if wildcard || len(ctx.Request.Header.Values("If-Match")) == 0 {
+		return 0, true
+	}
+
+	var versions []int64
+	for _, tag := range tags {
+		if version, ok := utils.ParseETag(tag); ok {
+			versions = append(versions, version)
+		}
+	}
+
+	switch len(versions) {
+	case 0:
+		sendError(ctx, usecase.ErrVersionMismatch)
+		return 0, false
+	case 1:
+		return versions[0], true
+	}
+
+	current, err := h.getUserById.Execute(id)
+	if err != nil {
+		// The usecase reports the missing user.
+		return versions[0], true
+	}
+
+	for _, version := range versions {
+		if version == current.Version {
+			return version, true
+		}
+	}
+
+	sendError(ctx, usecase.ErrVersionMismatch)
+	return 0, false
+}
@@ -0,0 +1,165 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	grpcserver "github.com/jonattasmoraes/titan/internal/user/infra/grpc"
+	httpserver "github.com/jonattasmoraes/titan/internal/user/infra/http"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/infra/server"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// testAPI serves the HTTP routes with the production handlers and usecases,
+// on an in-memory SQLite database.
+type testAPI struct {
+	*httptest.Server
+	users  domain.UserRepository
+	search *repository.MockUserSearchIndex
+}
+
+type testAPIConfig struct {
+	router server.RouterConfig
+	policy *policy.Engine
+}
+
+func newTestAPI(t *testing.T, config testAPIConfig) *testAPI {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	// Every connection to :memory: opens a database of its own.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+	CREATE TABLE users (
+		id TEXT PRIMARY KEY,
+		first_name TEXT,
+		last_name TEXT,
+		email TEXT,
+		password TEXT,
+		role TEXT,
+		region TEXT,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP,
+		version INTEGER NOT NULL DEFAULT 1
+	);
+	CREATE TABLE user_events (
+		sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_type TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		occurred_at TIMESTAMP NOT NULL
+	);
+	`)
+	require.NoError(t, err)
+
+	users := repository.NewSqlxRepository(db, db)
+	transactor := repository.NewSqlxUserTransactor(db)
+	search := &repository.MockUserSearchIndex{}
+
+	createUser := usecase.NewCreateUserUsecase(transactor)
+	getUserById := usecase.NewGetUserByIdUsecase(users)
+	updateUser := usecase.NewUpdateUserUsecase(transactor)
+
+	userGrpcServer := grpcserver.NewUserGrpcServer(
+		createUser,
+		getUserById,
+		usecase.NewGetUserByEmailUsecase(users),
+		usecase.NewBatchGetUsersUsecase(users),
+		usecase.NewListUsersUsecase(users),
+		usecase.NewExportUsersUsecase(users),
+		updateUser,
+		usecase.NewDeleteUserUsecase(transactor),
+		usecase.NewListUserEventsUsecase(repository.NewSqlxUserEventRepository(db, db)),
+		config.policy,
+		context.Background(),
+	)
+
+	userHandlers := httpserver.NewUserHandler(
+		userGrpcServer,
+		getUserById,
+		usecase.NewSearchUsersUsecase(search),
+		usecase.NewPatchUserDocumentUsecase(users, updateUser),
+		usecase.NewReplaceUserUsecase(transactor, false),
+		usecase.NewBatchWriteUsersUsecase(users, transactor),
+		usecase.NewImportUsersUsecase(users, createUser),
+		config.policy,
+		httpserver.NewIdempotency(repository.NewMemoryIdempotencyStore(), 0),
+	)
+
+	if config.router.Identity == nil {
+		config.router.Identity = policy.TrustIdentityHeaders
+	}
+
+	gin.SetMode(gin.TestMode)
+	api := httptest.NewServer(server.NewRouter(userHandlers, nil, nil, nil, config.router))
+	t.Cleanup(api.Close)
+
+	return &testAPI{Server: api, users: users, search: search}
+}
+
+// seed stores a user directly, with defaults for the empty fields.
+func (a *testAPI) seed(t *testing.T, user entities.User) *entities.User {
+	if user.ID == "" {
+		user.ID = ulid.Make().String()
+	}
+	if user.FirstName == "" {
+		user.FirstName = "John"
+	}
+	if user.LastName == "" {
+		user.LastName = "Lennon"
+	}
+	if user.Email == "" {
+		user.Email = fmt.Sprintf("%s@example.com", strings.ToLower(user.ID))
+	}
+	if user.Password == "" {
+		user.Password = "password123"
+	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	user.Version = 1
+
+	require.NoError(t, a.users.CreateUser(&user))
+
+	return &user
+}
+
+// do sends a request to the API and returns the response with its body read.
+func (a *testAPI) do(t *testing.T, method string, path string, body string, header http.Header) (*http.Response, string) {
+	req, err := http.NewRequest(method, a.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := a.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return res, string(data)
+}
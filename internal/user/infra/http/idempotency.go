@@ -0,0 +1,196 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var (
+	errIdempotencyKeyTooLong  = errcode.New(errcode.IdempotencyKeyTooLong, "Idempotency-Key must be at most 255 characters")
+	errIdempotencyKeyReused   = errcode.New(errcode.IdempotencyKeyReused, "Idempotency-Key was already used with a different request")
+	errIdempotencyKeyInFlight = errcode.New(errcode.IdempotencyKeyInProgress, "a request with this Idempotency-Key is still being processed, retry later")
+)
+
+// idempotencyLockTimeout bounds how long a key stays reserved by a request
+// whose response was never stored, for instance because the process died.
+const idempotencyLockTimeout = time.Minute
+
+// DefaultIdempotencyTTL is how long responses are replayed when no TTL is
+// configured.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// Idempotency lets clients safely retry requests that are not idempotent by
+// sending the same Idempotency-Key header. Keys are scoped to the caller.
+// The response to the first request is stored and replayed to the retries,
+// as long as they send the same request. Server errors are not stored, so
+// that they can be retried.
+type Idempotency struct {
+	store domain.IdempotencyStore
+	ttl   time.Duration
+}
+
+func NewIdempotency(store domain.IdempotencyStore, ttl time.Duration) *Idempotency {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	return &Idempotency{store: store, ttl: ttl}
+}
+
+// Handle is the middleware of the routes that accept an Idempotency-Key.
+// Requests without the header, or all requests when i is nil, go through
+// unchanged.
+func (i *Idempotency) Handle(ctx *gin.Context) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if i == nil || key == "" {
+		ctx.Next()
+		return
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		sendError(ctx, errIdempotencyKeyTooLong)
+		ctx.Abort()
+		return
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		sendError(ctx, invalidBody(err))
+		ctx.Abort()
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	fingerprint := requestFingerprint(ctx, body)
+
+	now := time.Now().UTC()
+	reserved := &domain.IdempotencyRecord{
+		Subject:     identityOf(ctx).ID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Token:       ulid.Make().String(),
+		ExpiresAt:   now.Add(idempotencyLockTimeout),
+	}
+	existing, err := i.store.ReserveIdempotencyKey(reserved, now)
+	if err != nil {
+		sendError(ctx, err)
+		ctx.Abort()
+		return
+	}
+
+	if existing != nil {
+		i.replay(ctx, existing, fingerprint)
+		ctx.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+	ctx.Writer = recorder
+
+	completed := false
+	defer func() {
+		if !completed {
+			if err := i.store.ReleaseIdempotencyKey(reserved); err != nil {
+				log.Printf("failed to release idempotency key: %v", err)
+			}
+		}
+	}()
+
+	ctx.Next()
+
+	if recorder.Status() >= http.StatusInternalServerError {
+		return
+	}
+
+	err = i.store.CompleteIdempotencyKey(reserved, recorder.Status(), recorder.body.Bytes(), time.Now().UTC().Add(i.ttl))
+	if err != nil {
+		log.Printf("failed to store idempotent response: %v", err)
+		return
+	}
+
+	completed = true
+}
+
+// replay answers a request whose key is already reserved.
+func (i *Idempotency) replay(ctx *gin.Context, record *domain.IdempotencyRecord, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		sendError(ctx, errIdempotencyKeyReused)
+		return
+	}
+
+	if record.Pending() {
+		sendError(ctx, errIdempotencyKeyInFlight)
+		return
+	}
+
+	contentType := "application/json"
+	if record.StatusCode >= http.StatusBadRequest {
+		contentType = problemContentType
+	}
+
+	ctx.Header(idempotentReplayedHeader, "true")
+	ctx.Data(record.StatusCode, contentType, record.Body)
+}
+
+// Purge deletes the expired responses every interval until ctx is done.
+func (i *Idempotency) Purge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := i.store.DeleteExpiredIdempotencyKeys(time.Now().UTC()); err != nil {
+				log.Printf("failed to delete expired idempotency keys: %v", err)
+			}
+		}
+	}
+}
+
+// requestFingerprint identifies a request by its method, path, caller and
+// body, so that a key reused for anything else is told apart.
+func requestFingerprint(ctx *gin.Context, body []byte) string {
+	hash := sha256.New()
+	for _, part := range []string{ctx.Request.Method, ctx.Request.URL.Path, identityOf(ctx).ID} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the body written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(data string) (int, error) {
+	r.body.WriteString(data)
+	return r.ResponseWriter.WriteString(data)
+}
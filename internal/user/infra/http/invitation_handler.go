@@ -0,0 +1,148 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/jonattasmoraes/titan/internal/utils"
+)
+
+type InvitationHandler struct {
+	createInvitation *usecase.CreateInvitationUsecase
+	resendInvitation *usecase.ResendInvitationUsecase
+	revokeInvitation *usecase.RevokeInvitationUsecase
+	acceptInvitation *usecase.AcceptInvitationUsecase
+}
+
+func NewInvitationHandler(
+	createInvitation *usecase.CreateInvitationUsecase,
+	resendInvitation *usecase.ResendInvitationUsecase,
+	revokeInvitation *usecase.RevokeInvitationUsecase,
+	acceptInvitation *usecase.AcceptInvitationUsecase,
+) *InvitationHandler {
+	return &InvitationHandler{
+		createInvitation: createInvitation,
+		resendInvitation: resendInvitation,
+		revokeInvitation: revokeInvitation,
+		acceptInvitation: acceptInvitation,
+	}
+}
+
+// @Tags Invitations
+// @Summary Invite a user to an organization
+// @Description Create an invitation for an email with a role. The token is only returned here and on resend.
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "ID of the admin sending the invitation"
+// @Param X-User-Signature header string true "Signature of the identity headers by the authenticating gateway"
+// @Param invitation body dto.InvitationRequestDTO true "Invitation"
+// @Success 201 {object} dto.InvitationResponseDTO
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 403 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /invitations [post]
+func (h *InvitationHandler) CreateInvitation(ctx *gin.Context) {
+	var request dto.InvitationRequestDTO
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		sendError(ctx, invalidBody(err))
+		return
+	}
+
+	request.InvitedBy = identityOf(ctx).ID
+
+	response, err := h.createInvitation.Execute(&request)
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	utils.SendSuccess(ctx, "create invitation", presentInvitation(ctx, response), http.StatusCreated)
+}
+
+// @Tags Invitations
+// @Summary Resend an invitation
+// @Description Issue a new token for a pending or expired invitation. An expired invitation is not revived while a newer one for the same email is pending.
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "ID of the admin resending the invitation"
+// @Param X-User-Signature header string true "Signature of the identity headers by the authenticating gateway"
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} dto.InvitationResponseDTO
+// @Failure 403 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /invitations/{id}/resend [post]
+func (h *InvitationHandler) ResendInvitation(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	response, err := h.resendInvitation.Execute(id, identityOf(ctx).ID)
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	utils.SendSuccess(ctx, "resend invitation", presentInvitation(ctx, response), http.StatusOK)
+}
+
+// @Tags Invitations
+// @Summary Revoke an invitation
+// @Description Revoke a pending invitation so its token can no longer be redeemed
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "ID of the admin revoking the invitation"
+// @Param X-User-Signature header string true "Signature of the identity headers by the authenticating gateway"
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} dto.InvitationResponseDTO
+// @Failure 403 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /invitations/{id}/revoke [post]
+func (h *InvitationHandler) RevokeInvitation(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	response, err := h.revokeInvitation.Execute(id, identityOf(ctx).ID)
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	utils.SendSuccess(ctx, "revoke invitation", presentInvitation(ctx, response), http.StatusOK)
+}
+
+// @Tags Invitations
+// @Summary Accept an invitation
+// @Description Redeem an invitation token, linking an existing account or creating a new one
+// @Accept  json
+// @Produce  json
+// @Param invitation body dto.AcceptInvitationRequestDTO true "Invitation token and account"
+// @Success 200 {object} dto.MembershipResponseDTO
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 401 {object} dto.ProblemResponse
+// @Failure 403 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 410 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(ctx *gin.Context) {
+	var request dto.AcceptInvitationRequestDTO
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		sendError(ctx, invalidBody(err))
+		return
+	}
+
+	response, err := h.acceptInvitation.Execute(&request)
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	utils.SendSuccess(ctx, "accept invitation", presentMembership(ctx, response), http.StatusOK)
+}
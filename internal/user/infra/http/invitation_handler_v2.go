@@ -0,0 +1,79 @@
+package http
+
+import "github.com/gin-gonic/gin"
+
+// The v2 handlers behave as their v1 counterparts; the Version middleware of
+// the /api/v2 routes makes them answer with the DTOs of v2.
+
+// @Tags Invitations v2
+// @Summary Invite a user to an organization
+// @Description Create an invitation, as POST /invitations does.
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "ID of the admin sending the invitation"
+// @Param X-User-Signature header string true "Signature of the identity headers by the authenticating gateway"
+// @Param invitation body dto.InvitationRequestDTO true "Invitation"
+// @Success 201 {object} dtov2.InvitationResponseDTO
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 403 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/invitations [post]
+func (h *InvitationHandler) CreateInvitationV2(ctx *gin.Context) {
+	h.CreateInvitation(ctx)
+}
+
+// @Tags Invitations v2
+// @Summary Resend an invitation
+// @Description Resend an invitation, as POST /invitations/{id}/resend does.
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "ID of the admin resending the invitation"
+// @Param X-User-Signature header string true "Signature of the identity headers by the authenticating gateway"
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} dtov2.InvitationResponseDTO
+// @Failure 403 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/invitations/{id}/resend [post]
+func (h *InvitationHandler) ResendInvitationV2(ctx *gin.Context) {
+	h.ResendInvitation(ctx)
+}
+
+// @Tags Invitations v2
+// @Summary Revoke an invitation
+// @Description Revoke an invitation, as POST /invitations/{id}/revoke does.
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "ID of the admin revoking the invitation"
+// @Param X-User-Signature header string true "Signature of the identity headers by the authenticating gateway"
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} dtov2.InvitationResponseDTO
+// @Failure 403 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/invitations/{id}/revoke [post]
+func (h *InvitationHandler) RevokeInvitationV2(ctx *gin.Context) {
+	h.RevokeInvitation(ctx)
+}
+
+// @Tags Invitations v2
+// @Summary Accept an invitation
+// @Description Accept an invitation, as POST /invitations/accept does.
+// @Accept  json
+// @Produce  json
+// @Param invitation body dto.AcceptInvitationRequestDTO true "Invitation token and account"
+// @Success 200 {object} dtov2.MembershipResponseDTO
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 401 {object} dto.ProblemResponse
+// @Failure 403 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 410 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitationV2(ctx *gin.Context) {
+	h.AcceptInvitation(ctx)
+}
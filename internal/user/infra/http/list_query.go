@@ -0,0 +1,95 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+)
+
+// parseListUsersRequest reads the pagination, filter and sort parameters of
+// GET /users. Values are checked by the usecase; only their syntax is checked
+// here.
+func parseListUsersRequest(ctx *gin.Context) (usecase.ListUsersRequest, error) {
+	request := usecase.ListUsersRequest{
+		Cursor: ctx.Query("cursor"),
+		Sort:   ctx.Query("sort"),
+		Order:  ctx.Query("order"),
+	}
+
+	filter, err := parseUserFilter(ctx)
+	if err != nil {
+		return request, err
+	}
+	request.Filter = filter
+
+	if value, ok := ctx.GetQuery("page"); ok {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return request, usecase.ErrInvalidPageNumber
+		}
+		request.Page = page
+	}
+
+	if value, ok := ctx.GetQuery("page_size"); ok {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			return request, usecase.ErrInvalidPageSize
+		}
+		request.PageSize = pageSize
+	}
+
+	if value, ok := ctx.GetQuery("include_total"); ok {
+		includeTotal, err := strconv.ParseBool(value)
+		if err != nil {
+			return request, invalidParameter("include_total", "invalid include_total, use 'true' or 'false'")
+		}
+		request.IncludeTotal = includeTotal
+	}
+
+	return request, nil
+}
+
+// parseUserFilter reads the filter parameters shared by GET /users and
+// GET /users/export.
+func parseUserFilter(ctx *gin.Context) (domain.UserFilter, error) {
+	filter := domain.UserFilter{
+		Role:        ctx.Query("role"),
+		EmailDomain: ctx.Query("email_domain"),
+	}
+
+	if deleted := ctx.Query("deleted"); deleted != "exclude" {
+		filter.Deleted = domain.DeletedFilter(deleted)
+	}
+
+	for name, target := range map[string]*time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+		"updated_after":  &filter.UpdatedAfter,
+		"updated_before": &filter.UpdatedBefore,
+	} {
+		value, ok := ctx.GetQuery(name)
+		if !ok {
+			continue
+		}
+
+		t, err := parseQueryTime(value)
+		if err != nil {
+			return filter, invalidParameter(name, fmt.Sprintf("invalid %s, use RFC 3339 or YYYY-MM-DD: %q", name, value))
+		}
+		*target = t
+	}
+
+	return filter, nil
+}
+
+func parseQueryTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
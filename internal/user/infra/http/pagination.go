@@ -0,0 +1,25 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// paginationLinks builds an RFC 8288 Link header with the first page and, if
+// there is one, the next page of the current request. Other query parameters
+// are kept so filters carry over.
+func paginationLinks(ctx *gin.Context, nextCursor string) string {
+	query := ctx.Request.URL.Query()
+	query.Del("cursor")
+	query.Del("page")
+
+	links := []string{`<` + ctx.Request.URL.Path + "?" + query.Encode() + `>; rel="first"`}
+
+	if nextCursor != "" {
+		query.Set("cursor", nextCursor)
+		links = append(links, `<`+ctx.Request.URL.Path+"?"+query.Encode()+`>; rel="next"`)
+	}
+
+	return strings.Join(links, ", ")
+}
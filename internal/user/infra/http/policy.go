@@ -0,0 +1,83 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+const identityKey = "identity"
+
+var errPermissionDenied = errcode.New(errcode.Forbidden, "you are not allowed to perform this operation")
+
+// authorize asks the policy engine about the current request and answers 403
+// when it is denied. Without an engine every request is allowed.
+func authorize(ctx *gin.Context, engine *policy.Engine, action string, resource map[string]interface{}) bool {
+	if !allowed(ctx, engine, action, resource) {
+		sendError(ctx, errPermissionDenied)
+		return false
+	}
+
+	return true
+}
+
+// allowed asks the policy engine about the current request without answering
+// it, for requests that hold several operations.
+func allowed(ctx *gin.Context, engine *policy.Engine, action string, resource map[string]interface{}) bool {
+	if engine == nil {
+		return true
+	}
+
+	decision := engine.Evaluate(policy.Request{
+		Action:      action,
+		Subject:     identityOf(ctx).Subject(),
+		Resource:    resource,
+		Environment: requestEnvironment(ctx),
+	})
+
+	return decision.Allowed
+}
+
+// requestEnvironment is the environment policies see the request in.
+func requestEnvironment(ctx *gin.Context) map[string]interface{} {
+	return map[string]interface{}{
+		"transport": "http",
+		"method":    ctx.Request.Method,
+		"path":      ctx.FullPath(),
+		"ip":        ctx.ClientIP(),
+	}
+}
+
+// Authenticate is the middleware that decides who the caller is, from the
+// identity headers verifier believes. Callers it does not believe are
+// anonymous.
+func Authenticate(verifier *policy.IdentityVerifier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity := policy.Identity{
+			ID:         ctx.GetHeader(policy.HeaderUserID),
+			Role:       ctx.GetHeader(policy.HeaderUserRole),
+			Attributes: map[string]string{},
+		}
+		for name, values := range ctx.Request.Header {
+			if len(values) > 0 && strings.HasPrefix(name, policy.HeaderSubjectPrefix) {
+				identity.Attributes[strings.TrimPrefix(name, policy.HeaderSubjectPrefix)] = values[0]
+			}
+		}
+		if len(identity.Attributes) == 0 {
+			identity.Attributes = nil
+		}
+
+		ctx.Set(identityKey, verifier.Authenticate(identity, ctx.GetHeader(policy.HeaderUserSignature)))
+	}
+}
+
+// identityOf is the caller of the request, anonymous on routes without the
+// Authenticate middleware.
+func identityOf(ctx *gin.Context) policy.Identity {
+	identity, _ := ctx.Get(identityKey)
+	caller, _ := identity.(policy.Identity)
+
+	return caller
+}
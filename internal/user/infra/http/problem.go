@@ -0,0 +1,131 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+const problemContentType = "application/problem+json"
+
+// errorsPath is where the error catalogue is served. The type of every
+// problem links to the entry of its code there.
+const errorsPath = "/api/errors"
+
+// sendError answers with err as an RFC 7807 problem. The status and title
+// come from the catalogue entry of its code. Server errors are logged with
+// the trace ID instead of being detailed, as their messages may leak
+// implementation details.
+func sendError(ctx *gin.Context, err error) {
+	code := errcode.Of(err)
+	definition := code.Definition()
+
+	problem := dto.ProblemResponse{
+		Type:     errorsPath + "/" + string(code),
+		Title:    definition.Title,
+		Status:   definition.Status,
+		Detail:   err.Error(),
+		Instance: ctx.Request.URL.Path,
+		Code:     string(code),
+		TraceID:  traceID(ctx),
+	}
+
+	if definition.Status >= http.StatusInternalServerError {
+		log.Printf("%s %s failed with %s, trace %s: %v", ctx.Request.Method, ctx.Request.URL.Path, code, problem.TraceID, err)
+		problem.Detail = ""
+	}
+
+	if field := errcode.FieldOf(err); field != "" {
+		problem.Errors = []dto.ProblemFieldError{{Field: field, Code: problem.Code, Detail: problem.Detail}}
+	}
+
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(definition.Status, problem)
+}
+
+// invalidBody is the error of a request body that could not be decoded.
+func invalidBody(err error) error {
+	return &errcode.Error{Code: errcode.InvalidBody, Message: err.Error(), Err: err}
+}
+
+// invalidParameter is the error of a query parameter with a malformed value.
+func invalidParameter(name string, message string) error {
+	return &errcode.Error{Code: errcode.InvalidParameter, Field: name, Message: message}
+}
+
+// traceID identifies the request in problems and logs: the trace ID of its
+// W3C traceparent header, else its X-Request-ID, else a random ID.
+func traceID(ctx *gin.Context) string {
+	// traceparent is version-trace_id-parent_id-flags.
+	parts := strings.Split(ctx.GetHeader("traceparent"), "-")
+	if len(parts) == 4 && len(parts[1]) == 32 {
+		if _, err := hex.DecodeString(parts[1]); err == nil {
+			return parts[1]
+		}
+	}
+
+	if id := ctx.GetHeader("X-Request-ID"); id != "" {
+		return id
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(id)
+}
+
+// @Tags Errors
+// @Summary List error codes
+// @Description List every code errors are reported with, by the HTTP and gRPC APIs alike. Codes are stable: match on them rather than on messages.
+// @Produce  json
+// @Success 200 {array} dto.ErrorCodeDTO
+// @Router /errors [get]
+func ListErrorCodes(ctx *gin.Context) {
+	codes := errcode.Codes()
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	response := make([]dto.ErrorCodeDTO, len(codes))
+	for i, code := range codes {
+		response[i] = errorCodeResponse(code)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Tags Errors
+// @Summary Get an error code
+// @Description Get the catalogue entry of an error code. The type of every problem links here.
+// @Produce  json
+// @Param code path string true "Error code, such as user.email_taken"
+// @Success 200 {object} dto.ErrorCodeDTO
+// @Failure 404 {object} dto.ProblemResponse
+// @Router /errors/{code} [get]
+func GetErrorCode(ctx *gin.Context) {
+	code := errcode.Code(ctx.Param("code"))
+	if !code.Known() {
+		sendError(ctx, errcode.New(errcode.UnknownCode, "no error has the code '"+string(code)+"'"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, errorCodeResponse(code))
+}
+
+func errorCodeResponse(code errcode.Code) dto.ErrorCodeDTO {
+	definition := code.Definition()
+
+	return dto.ErrorCodeDTO{
+		Code:   string(code),
+		Title:  definition.Title,
+		Status: definition.Status,
+		Field:  definition.Field,
+	}
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jonattasmoraes/titan/internal/policy"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/jonattasmoraes/titan/internal/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The /api operations that have an RPC are adapters over the gRPC server,
+// called in-process: they only translate the request, the response and the
+// status, so that /api and the gateway under /v1 cannot drift apart.
+
+// rpcContext is the context of the RPC serving the request, with the caller
+// and the environment of the request, so that policies see it as sent.
+func rpcContext(ctx *gin.Context) context.Context {
+	rpcCtx := policy.WithIdentity(ctx.Request.Context(), identityOf(ctx))
+
+	return policy.WithEnvironment(rpcCtx, requestEnvironment(ctx))
+}
+
+// fromStatus translates the status an RPC failed with back into the coded
+// error it was made from, so that sendError answers like the usecase had
+// failed in the handler.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+
+	coded := &errcode.Error{Code: errcode.Internal, Message: st.Message(), Err: err}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.Domain == errcode.Domain {
+				coded.Code = errcode.Code(detail.Reason)
+			}
+		case *errdetails.BadRequest:
+			if violations := detail.GetFieldViolations(); len(violations) > 0 {
+				coded.Field = violations[0].Field
+			}
+		}
+	}
+
+	return coded
+}
+
+// userFromProto is the user an RPC answered, as the usecases return it.
+func userFromProto(user *pb.User) *dto.UserResponseDTO {
+	response := &dto.UserResponseDTO{
+		ID:          user.Id,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Email:       user.Email,
+		Role:        user.Role,
+		Region:      user.Region,
+		CreatedTime: fromTimestamp(user.CreatedAt),
+		UpdatedTime: fromTimestamp(user.UpdatedAt),
+		DeletedTime: fromTimestamp(user.DeletedAt),
+	}
+
+	response.CreateAt = response.CreatedTime.Format("2006-01-02 15:04:05")
+	response.UpdateAt = response.UpdatedTime.Format("2006-01-02 15:04:05")
+	if !response.DeletedTime.IsZero() {
+		response.DeleteAt = response.DeletedTime.Format("2006-01-02 15:04:05")
+	}
+
+	if version, ok := utils.ParseETag(user.Etag); ok {
+		response.Version = version
+	}
+
+	return response
+}
+
+// userFromGetResponse is userFromProto for the response of GetUserByID.
+func userFromGetResponse(user *pb.GetUserResponse) *dto.UserResponseDTO {
+	return userFromProto(&pb.User{
+		Id:        user.Id,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Role:      user.Role,
+		Region:    user.Region,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		Etag:      user.Etag,
+	})
+}
+
+// listUsersRequestProto is the ListUsers request of a parsed GET /users.
+func listUsersRequestProto(request usecase.ListUsersRequest) *pb.ListUsersRequest {
+	filter := request.Filter
+
+	return &pb.ListUsersRequest{
+		Page:          clampInt32(request.Page),
+		PageSize:      clampInt32(request.PageSize),
+		Cursor:        request.Cursor,
+		IncludeTotal:  request.IncludeTotal,
+		Sort:          request.Sort,
+		Order:         request.Order,
+		Role:          filter.Role,
+		EmailDomain:   filter.EmailDomain,
+		CreatedAfter:  toTimestamp(filter.CreatedAfter),
+		CreatedBefore: toTimestamp(filter.CreatedBefore),
+		UpdatedAfter:  toTimestamp(filter.UpdatedAfter),
+		UpdatedBefore: toTimestamp(filter.UpdatedBefore),
+		Deleted:       string(filter.Deleted),
+	}
+}
+
+// clampInt32 saturates values out of the int32 range, which the usecases
+// refuse like any other value out of theirs.
+func clampInt32(value int) int32 {
+	switch {
+	case value > math.MaxInt32:
+		return math.MaxInt32
+	case value < math.MinInt32:
+		return math.MinInt32
+	}
+
+	return int32(value)
+}
+
+// toTimestamp is nil, meaning no bound, for the zero time.
+func toTimestamp(value time.Time) *timestamppb.Timestamp {
+	if value.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(value)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"google.golang.org/grpc"
+)
+
+// exportFlushRows is how many rows are written between flushes, so that the
+// client receives the export while it is read.
+const exportFlushRows = 100
+
+var errInvalidExportFormat = errcode.New(errcode.ExportFormatInvalid, "invalid format, use 'csv' or 'ndjson'")
+
+// exportContentTypes maps the export formats to their content type.
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+}
+
+// exportColumns are the CSV columns, named like the JSON fields of a user.
+// New columns go last, so that readers of older exports keep working.
+var exportColumns = []string{"id", "first_name", "last_name", "email", "role", "create_at", "update_at", "delete_at", "version", "region"}
+
+// exportColumnsV2 are exportColumns named like the JSON fields of a v2 user.
+var exportColumnsV2 = []string{"id", "first_name", "last_name", "email", "role", "created_at", "updated_at", "deleted_at", "version", "region"}
+
+// @Tags Users
+// @Summary Export users as CSV or NDJSON
+// @Description Stream every user matched by the filters, in the requested order, without pagination. Passwords are never exported. The response is gzip compressed when the request accepts it. An export that fails midway is cut off, so that it cannot be mistaken for a complete one.
+// @Produce  text/csv
+// @Produce  application/x-ndjson
+// @Param format query string false "Format of the file" Enums(csv, ndjson) default(csv)
+// @Param role query string false "Only users with this role" Enums(admin, super, user)
+// @Param email_domain query string false "Only users whose email is at this domain, e.g. example.com"
+// @Param created_after query string false "Only users created at or after this time, RFC 3339 or YYYY-MM-DD"
+// @Param created_before query string false "Only users created before this time, RFC 3339 or YYYY-MM-DD"
+// @Param updated_after query string false "Only users updated at or after this time, RFC 3339 or YYYY-MM-DD"
+// @Param updated_before query string false "Only users updated before this time, RFC 3339 or YYYY-MM-DD"
+// @Param deleted query string false "Deleted users to include" Enums(exclude, include, only) default(exclude)
+// @Param sort query string false "Field to sort by" Enums(id, created_at, updated_at, email, first_name, last_name) default(id)
+// @Param order query string false "Sort order" Enums(asc, desc) default(asc)
+// @Param Accept-Encoding header string false "gzip to compress the export"
+// @Success 200 {string} string "One user per row or line"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /users/export [get]
+func (h *UserHandler) ExportUsers(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", "csv")
+	if _, ok := exportContentTypes[format]; !ok {
+		sendError(ctx, errInvalidExportFormat)
+		return
+	}
+
+	filter, err := parseUserFilter(ctx)
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	export := &userExport{ctx: ctx, format: format}
+
+	err = h.users.ExportUsers(&pb.ExportUsersRequest{
+		Role:          filter.Role,
+		EmailDomain:   filter.EmailDomain,
+		CreatedAfter:  toTimestamp(filter.CreatedAfter),
+		CreatedBefore: toTimestamp(filter.CreatedBefore),
+		UpdatedAfter:  toTimestamp(filter.UpdatedAfter),
+		UpdatedBefore: toTimestamp(filter.UpdatedBefore),
+		Deleted:       string(filter.Deleted),
+		Sort:          ctx.Query("sort"),
+		Order:         ctx.Query("order"),
+	}, &exportStream{ctx: rpcContext(ctx), export: export})
+	err = fromStatus(err)
+	if err == nil {
+		err = export.close()
+	}
+	if err == nil {
+		return
+	}
+
+	if !export.started {
+		sendError(ctx, err)
+		return
+	}
+
+	// The status is already sent. Drop the connection, so that the client
+	// sees an error instead of an export that looks complete.
+	log.Printf("export users: stopped after %d rows: %v", export.rows, err)
+	if conn, _, err := ctx.Writer.Hijack(); err == nil {
+		conn.Close()
+	}
+	ctx.Abort()
+}
+
+// exportStream is the stream ExportUsers sends the users of an /api export
+// to, in-process.
+type exportStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	export *userExport
+}
+
+func (s *exportStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *exportStream) Send(user *pb.User) error {
+	return s.export.write(userFromProto(user))
+}
+
+// userExport writes users to the response in one of the export formats. The
+// headers are only sent with the first user, or on close for empty exports,
+// so that errors found before can still be answered with an error status.
+type userExport struct {
+	ctx     *gin.Context
+	format  string
+	gzip    *gzip.Writer
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+	rows    int
+}
+
+func (e *userExport) start() error {
+	e.started = true
+
+	header := e.ctx.Writer.Header()
+	header.Set("Content-Type", exportContentTypes[e.format])
+	header.Set("Content-Disposition", `attachment; filename="users.`+e.format+`"`)
+	header.Set("Vary", "Accept-Encoding")
+
+	var out io.Writer = e.ctx.Writer
+	if acceptsGzip(e.ctx.GetHeader("Accept-Encoding")) {
+		header.Set("Content-Encoding", "gzip")
+		e.gzip = gzip.NewWriter(e.ctx.Writer)
+		out = e.gzip
+	}
+
+	e.ctx.Status(http.StatusOK)
+	e.ctx.Writer.WriteHeaderNow()
+
+	if e.format == "csv" {
+		e.csv = csv.NewWriter(out)
+		if apiVersion(e.ctx) == APIVersion2 {
+			return e.csv.Write(exportColumnsV2)
+		}
+
+		return e.csv.Write(exportColumns)
+	}
+
+	e.json = json.NewEncoder(out)
+
+	return nil
+}
+
+func (e *userExport) write(user *dto.UserResponseDTO) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if e.csv != nil {
+		err = e.csv.Write([]string{
+			user.ID, user.FirstName, user.LastName, user.Email, user.Role,
+			user.CreateAt, user.UpdateAt, user.DeleteAt, strconv.FormatInt(user.Version, 10), user.Region,
+		})
+	} else {
+		err = e.json.Encode(presentUser(e.ctx, user))
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+
+	return nil
+}
+
+func (e *userExport) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+
+	if e.gzip != nil {
+		if err := e.gzip.Flush(); err != nil {
+			return err
+		}
+	}
+
+	e.ctx.Writer.Flush()
+
+	return nil
+}
+
+// close ends the export, sending the headers first if no user was written.
+func (e *userExport) close() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	if err := e.flush(); err != nil {
+		return err
+	}
+
+	if e.gzip != nil {
+		return e.gzip.Close()
+	}
+
+	return nil
+}
+
+// acceptsGzip reports whether an Accept-Encoding header accepts gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package http_test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportUsers(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	api.seed(t, entities.User{FirstName: "Ringo", Email: "ringo@titan.test", Password: "secret-password"})
+	api.seed(t, entities.User{FirstName: "John", Email: "john@titan.test"})
+	api.seed(t, entities.User{FirstName: "Paul", Email: "paul@example.com"})
+
+	export := func(query, acceptEncoding string) (*http.Response, string) {
+		var header http.Header
+		if acceptEncoding != "" {
+			header = http.Header{"Accept-Encoding": {acceptEncoding}}
+		}
+
+		res, data := api.do(t, http.MethodGet, "/api/users/export?"+query, "", header)
+		if res.Header.Get("Content-Encoding") != "gzip" {
+			return res, data
+		}
+
+		reader, err := gzip.NewReader(strings.NewReader(data))
+		require.NoError(t, err)
+
+		decoded, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		return res, string(decoded)
+	}
+
+	res, body := export("email_domain=titan.test&sort=first_name", "gzip")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+	assert.Equal(t, "text/csv; charset=utf-8", res.Header.Get("Content-Type"))
+	assert.NotContains(t, body, "secret-password")
+
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "id,first_name,last_name,email,role,create_at,update_at,delete_at,version,region", lines[0])
+	assert.Contains(t, lines[1], ",John,")
+	assert.Contains(t, lines[2], ",Ringo,")
+
+	res, body = export("format=ndjson&sort=first_name&order=desc", "identity")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Content-Encoding"))
+
+	var names []string
+	decoder := json.NewDecoder(strings.NewReader(body))
+	for decoder.More() {
+		var user struct {
+			FirstName string `json:"first_name"`
+		}
+		require.NoError(t, decoder.Decode(&user))
+		names = append(names, user.FirstName)
+	}
+	assert.Equal(t, []string{"Ringo", "Paul", "John"}, names)
+
+	// An empty export still has its header line.
+	_, body = export("role=admin", "")
+	assert.Equal(t, "id,first_name,last_name,email,role,create_at,update_at,delete_at,version,region\n", body)
+
+	res, _ = export("format=xlsx", "")
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, _ = export("sort=password", "")
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -1,215 +1,533 @@
 package http
 
 import (
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jonattasmoraes/titan/internal/policy"
 	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
-	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
 	"github.com/jonattasmoraes/titan/internal/user/usecase"
 	"github.com/jonattasmoraes/titan/internal/utils"
 )
 
+// UserHandler serves the /api routes. The operations that have an RPC are
+// served by users, the gRPC server, so that they behave the same on every
+// transport; the handler only translates requests and responses.
 type UserHandler struct {
-	createUser  *usecase.CreateUserUsecase
-	getUserById *usecase.GetUserByIdUsecase
-	listUsers   *usecase.ListUsersUsecase
-	patchUser   *usecase.PatchUserUsecase
-	deleteUser  *usecase.DeleteUserUsecase
+	users             pb.UserServiceServer
+	getUserById       *usecase.GetUserByIdUsecase
+	searchUsers       *usecase.SearchUsersUsecase
+	patchUserDocument *usecase.PatchUserDocumentUsecase
+	replaceUser       *usecase.ReplaceUserUsecase
+	batchWrite        *usecase.BatchWriteUsersUsecase
+	importUsers       *usecase.ImportUsersUsecase
+	policy            *policy.Engine
+	idempotency       *Idempotency
 }
 
 func NewUserHandler(
-	createUser *usecase.CreateUserUsecase,
+	users pb.UserServiceServer,
 	getUserById *usecase.GetUserByIdUsecase,
-	listUsers *usecase.ListUsersUsecase,
-	patchUser *usecase.PatchUserUsecase,
-	deleteUser *usecase.DeleteUserUsecase,
+	searchUsers *usecase.SearchUsersUsecase,
+	patchUserDocument *usecase.PatchUserDocumentUsecase,
+	replaceUser *usecase.ReplaceUserUsecase,
+	batchWrite *usecase.BatchWriteUsersUsecase,
+	importUsers *usecase.ImportUsersUsecase,
+	policy *policy.Engine,
+	idempotency *Idempotency,
 ) *UserHandler {
 	return &UserHandler{
-		createUser:  createUser,
-		getUserById: getUserById,
-		listUsers:   listUsers,
-		patchUser:   patchUser,
-		deleteUser:  deleteUser,
+		users:             users,
+		getUserById:       getUserById,
+		searchUsers:       searchUsers,
+		patchUserDocument: patchUserDocument,
+		replaceUser:       replaceUser,
+		batchWrite:        batchWrite,
+		importUsers:       importUsers,
+		policy:            policy,
+		idempotency:       idempotency,
 	}
 }
 
+// Idempotent replays the stored response of requests retried with the same
+// Idempotency-Key. It does nothing when the handler has no Idempotency.
+func (h *UserHandler) Idempotent(ctx *gin.Context) {
+	h.idempotency.Handle(ctx)
+}
+
 // @Tags Users
 // @Summary Create a new user
-// @Description Create a new user with the input payload
+// @Description Create a new user with the input payload. Retries sent with the same Idempotency-Key and body get the stored response of the first request back, with the Idempotent-Replayed header set.
 // @Accept  json
 // @Produce  json
 // @Param user body dto.UserRequestDTO true "User"
+// @Param Idempotency-Key header string false "Unique key of the request, at most 255 characters"
 // @Success 201 {object} dto.UserResponseDTO
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Header 201 {string} Idempotent-Replayed "true when the response is replayed"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 422 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
 // @Router /user [post]
 func (h *UserHandler) CreateUser(ctx *gin.Context) {
 	var request dto.UserRequestDTO
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		sendError(ctx, invalidBody(err))
 		return
 	}
 
-	user, err := h.createUser.Execute(&request)
+	user, err := h.users.CreateUser(rpcContext(ctx), &pb.CreateUserRequest{
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		Email:     request.Email,
+		Password:  request.Password,
+		Region:    request.Region,
+	})
 	if err != nil {
-		if err == entities.ErrorValidation(err) {
-			utils.SendError(ctx, http.StatusBadRequest, err.Error())
-			return
-		}
-
-		if err == usecase.ErrEmailAlreadyExists {
-			utils.SendError(ctx, http.StatusConflict, err.Error())
-			return
-		}
-
-		utils.SendError(ctx, http.StatusInternalServerError, err.Error())
+		sendError(ctx, fromStatus(err))
 		return
 	}
 
-	utils.SendSuccess(ctx, "create user", user, http.StatusCreated)
+	utils.SendSuccess(ctx, "create user", presentUser(ctx, userFromProto(user)), http.StatusCreated)
 }
 
 // @Tags Users
 // @Summary Get user by id
-// @Description Get user by id
+// @Description Get user by id. The ETag header holds the version of the user; send it in If-None-Match to get a 304 while the user is unchanged.
 // @Accept  json
 // @Produce  json
 // @Param id path string true "User ID"
+// @Param If-None-Match header string false "ETag of a previous response"
 // @Success 200 {object} dto.UserResponseDTO
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Header 200 {string} ETag "Version of the user"
+// @Success 304
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
 // @Router /user/{id} [get]
 func (h *UserHandler) GetUserById(ctx *gin.Context) {
-	id := ctx.Param("id")
-
-	request, err := h.getUserById.Execute(id)
+	response, err := h.users.GetUserByID(rpcContext(ctx), &pb.GetUserRequest{Id: ctx.Param("id")})
 	if err != nil {
-		if err == usecase.ErrUserNotFound {
-			utils.SendError(ctx, http.StatusNotFound, err.Error())
-			return
-		}
-		utils.SendError(ctx, http.StatusInternalServerError, err.Error())
+		sendError(ctx, fromStatus(err))
+		return
+	}
+
+	user := userFromGetResponse(response)
+
+	if notModified(ctx, user.Version) {
 		return
 	}
 
-	utils.SendSuccess(ctx, "get user by id", request, http.StatusOK)
+	setUserETag(ctx, user.Version)
+	utils.SendSuccess(ctx, "get user by id", presentUser(ctx, user), http.StatusOK)
 }
 
 // @Tags Users
 // @Summary List users
-// @Description List users
+// @Description List users, in creation order unless sorted otherwise. Follow next_cursor, or the Link header, to read the next page; page is still accepted for older clients.
 // @Accept  json
 // @Produce  json
-// @Param page query int true "Page number"
+// @Param cursor query string false "Cursor from a previous response"
+// @Param page_size query int false "Users per page, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of users"
+// @Param page query int false "Page number, ignored when cursor is set"
+// @Param sort query string false "Sort field" Enums(id, created_at, updated_at, email, first_name, last_name)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param role query string false "Only users with this role" Enums(admin, super, user)
+// @Param email_domain query string false "Only users whose email is at this domain"
+// @Param created_after query string false "Created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_after query string false "Updated at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_before query string false "Updated before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param deleted query string false "Deleted users to list" Enums(exclude, include, only)
 // @Success 200 {array} dto.UserResponseDTO
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Header 200 {string} Link "Links to the first and next pages (RFC 8288)"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
 // @Router /users [get]
 func (h *UserHandler) ListUsers(ctx *gin.Context) {
-	request := ctx.DefaultQuery("page", "1")
-
-	page, err := strconv.Atoi(request)
+	request, err := parseListUsersRequest(ctx)
 	if err != nil {
-		utils.SendError(ctx, http.StatusBadRequest, err.Error())
+		sendError(ctx, err)
 		return
 	}
 
-	users, err := h.listUsers.Execute(page)
+	response, err := h.users.ListUsers(rpcContext(ctx), listUsersRequestProto(request))
 	if err != nil {
-		if err == usecase.ErrInvalidPageNumber {
-			utils.SendError(ctx, http.StatusBadRequest, err.Error())
-			return
-		}
+		sendError(ctx, fromStatus(err))
+		return
+	}
 
-		if err == usecase.ErrUsersNotFound {
-			utils.SendError(ctx, http.StatusBadRequest, err.Error())
+	users := make([]*dto.UserResponseDTO, len(response.Users))
+	for i, user := range response.Users {
+		users[i] = userFromProto(user)
+	}
+
+	var totalCount *int
+	if response.TotalCount != nil {
+		total := int(*response.TotalCount)
+		totalCount = &total
+	}
+
+	ctx.Header("Link", paginationLinks(ctx, response.NextCursor))
+	utils.SendList(ctx, "list users", presentUsers(ctx, users), response.NextCursor, totalCount)
+}
+
+// @Tags Users
+// @Summary Search users
+// @Description Search active users by partial names and emails, best matches first. Misspellings are tolerated on Postgres. Highlights are HTML-escaped with the matches wrapped in <mark></mark>. Only the users the caller may read are returned, so there may be fewer results than the limit.
+// @Produce  json
+// @Param q query string true "Search text, 2 to 200 characters"
+// @Param limit query int false "Maximum number of results, at most 100" default(20)
+// @Success 200 {array} dto.UserSearchResultDTO
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /users/search [get]
+func (h *UserHandler) SearchUsers(ctx *gin.Context) {
+	limit := 0
+	if value, ok := ctx.GetQuery("limit"); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			sendError(ctx, usecase.ErrInvalidSearchLimit)
 			return
 		}
+		limit = parsed
+	}
 
-		utils.SendError(ctx, http.StatusInternalServerError, err.Error())
+	if !authorize(ctx, h.policy, "user.search", nil) {
 		return
 	}
 
-	utils.SendSuccess(ctx, "list users", users, http.StatusOK)
+	results, err := h.searchUsers.Execute(ctx.Query("q"), limit)
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	// Users the caller may not read are left out, as GET /user/{id} answers
+	// 404 for them.
+	readable := make([]*dto.UserSearchResultDTO, 0, len(results))
+	for _, result := range results {
+		if allowed(ctx, h.policy, "user.read", policy.UserResource(result.User)) {
+			readable = append(readable, result)
+		}
+	}
+
+	utils.SendSuccess(ctx, "search users", presentSearchResults(ctx, readable), http.StatusOK)
 }
 
 // @Tags Users
 // @Summary Patch user
-// @Description Patch user
+// @Description Patch user. With application/json, the non-empty fields of the body are applied. With application/merge-patch+json (RFC 7396) or application/json-patch+json (RFC 6902, test operations included), the patch is applied to the user as returned by GET, so fields can be cleared; only first_name, last_name (which null clears) and email can change, and role too for callers allowed user.update_role, and the result is validated as a whole. With If-Match, the user is only changed while its ETag matches.
 // @Accept  json
+// @Accept  application/merge-patch+json
+// @Accept  application/json-patch+json
 // @Produce  json
 // @Param id path string true "User ID"
-// @Param user body dto.UserDTO true "User"
+// @Param If-Match header string false "ETag the user must still have"
+// @Param user body dto.PatchRequestDTO true "User, merge patch or JSON patch"
 // @Success 200 {object} dto.UserResponseDTO
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 409 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 412 {object} dto.ProblemResponse
+// @Failure 415 {object} dto.ProblemResponse
+// @Failure 422 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
 // @Router /user/{id} [patch]
 func (h *UserHandler) PatchUser(ctx *gin.Context) {
 	var request dto.PatchRequestDTO
 
 	id := ctx.Param("id")
 
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	if format, ok := patchFormats[mediaType]; ok {
+		h.applyPatch(ctx, id, format)
+		return
+	}
+
+	if mediaType != "" && mediaType != "application/json" {
+		ctx.Header("Accept-Patch", acceptPatch)
+		sendError(ctx, errUnsupportedPatch)
+		return
+	}
+
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		sendError(ctx, invalidBody(err))
 		return
 	}
 
-	if h.patchUser == nil {
-		utils.SendError(ctx, http.StatusInternalServerError, "patchUser is nil")
+	version, ok := h.ifMatchVersion(ctx, id)
+	if !ok {
 		return
 	}
 
-	user := &entities.User{
-		ID:        id,
+	user, err := h.users.PatchUser(rpcContext(ctx), &pb.PatchUserRequest{
+		Id:        id,
 		FirstName: request.FirstName,
 		LastName:  request.LastName,
 		Email:     request.Email,
+		Region:    request.Region,
+		Etag:      etagOf(version),
+	})
+	if err != nil {
+		sendError(ctx, fromStatus(err))
+		return
 	}
 
-	response, err := h.patchUser.Execute(user)
+	response := userFromProto(user)
+
+	setUserETag(ctx, response.Version)
+	utils.SendSuccess(ctx, "patch user", presentUser(ctx, response), http.StatusOK)
+}
+
+// patchFormats maps the media types of patch documents to their format.
+var patchFormats = map[string]string{
+	"application/merge-patch+json": usecase.PatchFormatMerge,
+	"application/json-patch+json":  usecase.PatchFormatJSON,
+}
+
+// acceptPatch lists the media types PATCH accepts, for the Accept-Patch
+// header (RFC 5789).
+const acceptPatch = "application/json, application/merge-patch+json, application/json-patch+json"
+
+var errUnsupportedPatch = errcode.New(errcode.UnsupportedMediaType, "unsupported patch, use "+acceptPatch)
+
+// applyPatch applies a merge patch or a JSON patch to the user.
+func (h *UserHandler) applyPatch(ctx *gin.Context, id string, format string) {
+	patch, err := io.ReadAll(ctx.Request.Body)
 	if err != nil {
-		if err == usecase.ErrUserNotFound {
-			utils.SendError(ctx, http.StatusNotFound, err.Error())
-			return
-		}
-		if err == usecase.ErrEmailAlreadyExists {
-			utils.SendError(ctx, http.StatusConflict, err.Error())
-			return
-		}
+		sendError(ctx, invalidBody(err))
+		return
+	}
+
+	if !h.authorizeTarget(ctx, "user.update", id) {
+		return
+	}
 
-		utils.SendError(ctx, http.StatusInternalServerError, err.Error())
+	version, ok := h.ifMatchVersion(ctx, id)
+	if !ok {
 		return
 	}
 
-	utils.SendSuccess(ctx, "patch user", response, http.StatusOK)
+	response, err := h.patchUserDocument.Execute(id, version, format, patch, h.patchableFields(ctx, id))
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	setUserETag(ctx, response.Version)
+	utils.SendSuccess(ctx, "patch user", presentUser(ctx, response), http.StatusOK)
+}
+
+// patchableFields are the fields the caller may change with a patch document:
+// the role as well when the policy allows user.update_role on the user.
+func (h *UserHandler) patchableFields(ctx *gin.Context, id string) []string {
+	if h.policy == nil {
+		return usecase.PatchableUserFields
+	}
+
+	target, err := h.getUserById.Execute(id)
+	if err != nil || !allowed(ctx, h.policy, "user.update_role", policy.UserResource(target)) {
+		return usecase.UpdatableUserFields
+	}
+
+	return usecase.PatchableUserFields
+}
+
+// @Tags Users
+// @Summary Replace user
+// @Description Replace every mutable field of a user. The password is only used when the user is created, which happens for unknown IDs when PUT_CREATES_MISSING_USERS is enabled; the ID of a deleted user answers 410 instead. With If-Match, the user is only replaced while its ETag matches, and never created.
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag the user must still have"
+// @Param user body dto.UserRequestDTO true "User"
+// @Success 200 {object} dto.UserResponseDTO
+// @Success 201 {object} dto.UserResponseDTO
+// @Header 200,201 {string} ETag "New version of the user"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 410 {object} dto.ProblemResponse
+// @Failure 412 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /user/{id} [put]
+func (h *UserHandler) ReplaceUser(ctx *gin.Context) {
+	var request dto.UserRequestDTO
+
+	id := ctx.Param("id")
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		sendError(ctx, invalidBody(err))
+		return
+	}
+
+	if !h.authorizeReplace(ctx, id, &request) {
+		return
+	}
+
+	version, ok := h.ifMatchVersion(ctx, id)
+	if !ok {
+		return
+	}
+
+	response, created, err := h.replaceUser.Execute(id, version, &request)
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	setUserETag(ctx, response.Version)
+
+	if created {
+		utils.SendSuccess(ctx, "replace user", presentUser(ctx, response), http.StatusCreated)
+		return
+	}
+
+	utils.SendSuccess(ctx, "replace user", presentUser(ctx, response), http.StatusOK)
+}
+
+// authorizeReplace checks user.update against the current user, or
+// user.create when the PUT is going to create it.
+func (h *UserHandler) authorizeReplace(ctx *gin.Context, id string, request *dto.UserRequestDTO) bool {
+	if h.policy == nil {
+		return true
+	}
+
+	if h.replaceUser.CreatesMissing() {
+		_, err := h.getUserById.Execute(id)
+		if err == usecase.ErrUserNotFound {
+			resource := policy.UserResource(&dto.UserResponseDTO{ID: id, Email: request.Email, Role: "user", Region: request.Region})
+			return authorize(ctx, h.policy, "user.create", resource)
+		}
+	}
+
+	return h.authorizeTarget(ctx, "user.update", id)
 }
 
 // @Tags Users
 // @Summary Delete user
-// @Description Delete user
+// @Description Delete user. With If-Match, the user is only deleted while its ETag matches.
 // @Accept  json
 // @Produce  json
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag the user must still have"
 // @Success 204
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 412 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
 // @Router /user/{id} [delete]
 func (h *UserHandler) DeleteUser(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	response, err := h.deleteUser.Execute(id)
+	version, ok := h.ifMatchVersion(ctx, id)
+	if !ok {
+		return
+	}
+
+	response, err := h.users.DeleteUser(rpcContext(ctx), &pb.DeleteUserRequest{Id: id, Etag: etagOf(version)})
 	if err != nil {
-		if err == usecase.ErrUserNotFound {
-			utils.SendError(ctx, http.StatusNotFound, err.Error())
-			return
+		sendError(ctx, fromStatus(err))
+		return
+	}
+
+	utils.SendSuccess(ctx, "delete user", presentUser(ctx, userFromProto(response)), http.StatusOK)
+}
+
+// authorizeTarget loads the user an operation is about so the policy can look
+// at its attributes before anything is changed.
+func (h *UserHandler) authorizeTarget(ctx *gin.Context, action string, id string) bool {
+	if h.policy == nil {
+		return true
+	}
+
+	target, err := h.getUserById.Execute(id)
+	if err != nil {
+		sendError(ctx, err)
+		return false
+	}
+
+	return authorize(ctx, h.policy, action, policy.UserResource(target))
+}
+
+// batchOpActions maps the operations of a batch to their policy actions.
+var batchOpActions = map[string]string{
+	usecase.BatchOpCreate: "user.create",
+	usecase.BatchOpPatch:  "user.update",
+	usecase.BatchOpDelete: "user.delete",
+}
+
+// @Tags Users
+// @Summary Create, patch and delete users in one request
+// @Description Apply up to 500 create, patch and delete operations in order. In atomic mode, the default, they are all applied or none is: one failure rolls back the batch and the other operations report 424. In best_effort mode, each operation is applied on its own. Each result has the status the single-user endpoint would have answered; the response is 207 when any operation failed. Every operation is checked against the access policy like its single-user endpoint.
+// @Accept  json
+// @Produce  json
+// @Param batch body dto.BatchWriteRequestDTO true "Operations"
+// @Success 200 {array} dto.BatchWriteResultDTO
+// @Success 207 {array} dto.BatchWriteResultDTO
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /users:batch [post]
+func (h *UserHandler) BatchWriteUsers(ctx *gin.Context) {
+	var request dto.BatchWriteRequestDTO
+
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		sendError(ctx, invalidBody(err))
+		return
+	}
+
+	var authorizeOp usecase.BatchAuthorizer
+	if h.policy != nil {
+		authorizeOp = func(op string, target *dto.UserResponseDTO) bool {
+			return allowed(ctx, h.policy, batchOpActions[op], policy.UserResource(target))
 		}
-		utils.SendError(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	results, err := h.batchWrite.Execute(&request, authorizeOp)
+	if err != nil {
+		sendError(ctx, err)
 		return
 	}
 
-	utils.SendSuccess(ctx, "delete user", response, http.StatusOK)
+	status := http.StatusOK
+	response := make([]dto.BatchWriteResultDTO, len(results))
+
+	for i, result := range results {
+		op := request.Operations[i].Op
+		response[i] = dto.BatchWriteResultDTO{
+			Index:  i,
+			Op:     op,
+			Status: batchResultStatus(op, result.Err),
+			User:   result.User,
+		}
+
+		if result.Err != nil {
+			status = http.StatusMultiStatus
+			response[i].Error = result.Err.Error()
+			response[i].Code = string(errcode.Of(result.Err))
+			response[i].Field = errcode.FieldOf(result.Err)
+		}
+	}
+
+	utils.SendSuccess(ctx, "batch write users", presentBatchResults(ctx, response), status)
+}
+
+// batchResultStatus is the status the single-user endpoint of op would have
+// answered.
+func batchResultStatus(op string, err error) int {
+	switch {
+	case err == nil && op == usecase.BatchOpCreate:
+		return http.StatusCreated
+	case err == nil:
+		return http.StatusOK
+	}
+
+	return errcode.Of(err).Definition().Status
 }
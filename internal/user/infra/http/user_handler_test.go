@@ -0,0 +1,560 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	dtov2 "github.com/jonattasmoraes/titan/internal/user/domain/DTO/v2"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	httpserver "github.com/jonattasmoraes/titan/internal/user/infra/http"
+	"github.com/jonattasmoraes/titan/internal/user/infra/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceUser(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	user := api.seed(t, entities.User{})
+	api.seed(t, entities.User{Email: "taken@example.com"})
+
+	res, _ := api.do(t, http.MethodPut, "/api/user/"+user.ID, `{"first_name":"Ringo","last_name":"Starr","email":"ringo@example.com"}`, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	replaced, err := api.users.FindUserById(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Starr", replaced.LastName)
+	assert.Equal(t, "ringo@example.com", replaced.Email)
+
+	res, _ = api.do(t, http.MethodPut, "/api/user/"+user.ID, `{"last_name":"Starr","email":"ringo@example.com"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	// The last name is optional, so leaving it out clears it.
+	res, _ = api.do(t, http.MethodPut, "/api/user/"+user.ID, `{"first_name":"Ringo","email":"ringo@example.com"}`, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	replaced, err = api.users.FindUserById(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, replaced.LastName)
+
+	res, _ = api.do(t, http.MethodPut, "/api/user/"+user.ID, `{"first_name":"Ringo","last_name":"Starr","email":"taken@example.com"}`, nil)
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	res, _ = api.do(t, http.MethodPut, "/api/user/01HZ0000000000000000000000", `{"first_name":"Ringo","last_name":"Starr","email":"new@example.com"}`, nil)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestListUsers_Pagination(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	for i := 0; i < 3; i++ {
+		api.seed(t, entities.User{})
+	}
+
+	type page struct {
+		Data       []struct{ ID string } `json:"data"`
+		NextCursor string                `json:"next_cursor"`
+		TotalCount *int                  `json:"total_count"`
+	}
+
+	get := func(query string) (page, *http.Response) {
+		res, body := api.do(t, http.MethodGet, "/api/users?"+query, "", nil)
+
+		var decoded page
+		require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+
+		return decoded, res
+	}
+
+	first, res := get("page_size=2&include_total=true")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Len(t, first.Data, 2)
+	assert.Equal(t, 3, *first.TotalCount)
+	assert.Contains(t, res.Header.Get("Link"), `rel="next"`)
+
+	second, res := get("page_size=2&cursor=" + first.NextCursor)
+	assert.Len(t, second.Data, 1)
+	assert.Empty(t, second.NextCursor)
+	assert.Nil(t, second.TotalCount)
+	assert.NotContains(t, res.Header.Get("Link"), `rel="next"`)
+
+	legacy, _ := get("page=2&page_size=2")
+	assert.Equal(t, second.Data, legacy.Data)
+}
+
+func TestListUsers_FilterAndSort(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	api.seed(t, entities.User{Email: "ana@example.com"})
+	api.seed(t, entities.User{Email: "zoe@example.com"})
+	api.seed(t, entities.User{Email: "bob@other.org", Role: "admin"})
+
+	get := func(query string) ([]string, int) {
+		res, body := api.do(t, http.MethodGet, "/api/users?"+query, "", nil)
+
+		var decoded struct {
+			Data []struct{ Email string } `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+
+		var emails []string
+		for _, user := range decoded.Data {
+			emails = append(emails, user.Email)
+		}
+
+		return emails, res.StatusCode
+	}
+
+	emails, status := get("email_domain=example.com&sort=email&order=desc")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"zoe@example.com", "ana@example.com"}, emails)
+
+	emails, _ = get("role=admin")
+	assert.Equal(t, []string{"bob@other.org"}, emails)
+
+	_, status = get("sort=password")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestSearchUsers(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	john := api.seed(t, entities.User{FirstName: "John", LastName: "Lennon", Email: "john@example.com"})
+
+	api.search.On("SearchUsers", "len", mock.Anything).Return([]*domain.UserSearchHit{{
+		User:       john,
+		Rank:       1,
+		Highlights: map[string]string{"first_name": "John", "last_name": "<mark>Len</mark>non", "email": "john@example.com"},
+	}}, nil)
+
+	res, body := api.do(t, http.MethodGet, "/api/users/search?q=len", "", nil)
+
+	var decoded struct {
+		Data []struct {
+			User       struct{ Email string } `json:"user"`
+			Highlights map[string]string      `json:"highlights"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	require.Len(t, decoded.Data, 1)
+	assert.Equal(t, "john@example.com", decoded.Data[0].User.Email)
+	assert.Equal(t, "<mark>Len</mark>non", decoded.Data[0].Highlights["last_name"])
+
+	// Queries too short to search are rejected before reaching the index.
+	res, _ = api.do(t, http.MethodGet, "/api/users/search?q=j", "", nil)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	api.search.AssertNumberOfCalls(t, "SearchUsers", 1)
+}
+
+// TestSearchUsers_OnlyReadable verifies that searches leave out the users the
+// caller may not read.
+func TestSearchUsers_OnlyReadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+version: "1"
+default: deny
+rules:
+  - name: support-searches-users
+    effect: allow
+    actions: ["user.search"]
+    condition: subject.role == "support"
+  - name: support-reads-own-region
+    effect: allow
+    actions: ["user.read"]
+    condition: has(subject.region) && has(resource.region) && subject.region == resource.region
+`), 0o644))
+
+	engine, err := policy.NewEngine(path, nil)
+	require.NoError(t, err)
+
+	api := newTestAPI(t, testAPIConfig{policy: engine})
+	john := api.seed(t, entities.User{FirstName: "John", LastName: "Lennon", Region: "south"})
+	julia := api.seed(t, entities.User{FirstName: "Julia", LastName: "Lennon", Region: "north"})
+
+	api.search.On("SearchUsers", "len", mock.Anything).Return([]*domain.UserSearchHit{{User: john, Rank: 2}, {User: julia, Rank: 1}}, nil)
+
+	res, body := api.do(t, http.MethodGet, "/api/users/search?q=len", "", http.Header{"X-User-Role": {"support"}, "X-Subject-Region": {"south"}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var decoded struct {
+		Data []struct {
+			User struct{ ID string } `json:"user"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+	require.Len(t, decoded.Data, 1)
+	assert.Equal(t, john.ID, decoded.Data[0].User.ID)
+}
+
+func TestConditionalRequests(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	user := api.seed(t, entities.User{})
+
+	do := func(method string, header string, etag string, body string) *http.Response {
+		var headers http.Header
+		if header != "" {
+			headers = http.Header{header: {etag}}
+		}
+
+		res, _ := api.do(t, method, "/api/user/"+user.ID, body, headers)
+		return res
+	}
+
+	res := do(http.MethodGet, "", "", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `"1"`, res.Header.Get("ETag"))
+
+	res = do(http.MethodGet, "If-None-Match", `W/"1"`, "")
+	assert.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	res = do(http.MethodPatch, "If-Match", `"1"`, `{"first_name":"Ringo"}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `"2"`, res.Header.Get("ETag"))
+
+	// The second admin still holds version 1.
+	res = do(http.MethodPatch, "If-Match", `"1"`, `{"first_name":"George"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	res = do(http.MethodPut, "If-Match", `"1", "2"`, `{"first_name":"Ringo","last_name":"Starr","email":"ringo@example.com"}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `"3"`, res.Header.Get("ETag"))
+
+	res = do(http.MethodGet, "If-None-Match", `"2"`, "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = do(http.MethodDelete, "If-Match", `"2"`, "")
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	res = do(http.MethodDelete, "If-Match", `"3"`, "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestPatchUser_Documents(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	user := api.seed(t, entities.User{FirstName: "John", LastName: "Lennon"})
+
+	patch := func(contentType string, body string) (*http.Response, dto.UserResponseDTO) {
+		res, data := api.do(t, http.MethodPatch, "/api/user/"+user.ID, body, http.Header{"Content-Type": {contentType}})
+
+		var patched struct {
+			Data dto.UserResponseDTO `json:"data"`
+		}
+		if res.StatusCode == http.StatusOK {
+			require.NoError(t, json.Unmarshal([]byte(data), &patched))
+		}
+
+		return res, patched.Data
+	}
+
+	res, patched := patch("application/merge-patch+json", `{"first_name":"Ringo","last_name":"Starr"}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Ringo", patched.FirstName)
+	assert.Equal(t, "Starr", patched.LastName)
+	assert.Equal(t, `"2"`, res.Header.Get("ETag"))
+
+	res, _ = patch("application/json-patch+json", `[
+		{"op": "test", "path": "/first_name", "value": "John"},
+		{"op": "replace", "path": "/first_name", "value": "Paul"}
+	]`)
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	res, patched = patch("application/json-patch+json", `[
+		{"op": "test", "path": "/first_name", "value": "Ringo"},
+		{"op": "replace", "path": "/first_name", "value": "Paul"}
+	]`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Paul", patched.FirstName)
+
+	res, patched = patch("application/merge-patch+json", `{"last_name":null}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, patched.LastName)
+
+	stored, err := api.users.FindUserById(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.LastName)
+
+	// Without a policy every caller may change the role.
+	res, patched = patch("application/merge-patch+json", `{"role":"admin"}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "admin", patched.Role)
+
+	res, _ = patch("application/merge-patch+json", `{"id":"01HZ0000000000000000000000"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+	res, _ = patch("text/plain", `first_name=George`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+	assert.Contains(t, res.Header.Get("Accept-Patch"), "application/merge-patch+json")
+}
+
+func TestAPIVersions(t *testing.T) {
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	api := newTestAPI(t, testAPIConfig{router: server.RouterConfig{V1Deprecation: httpserver.Deprecation{
+		Since:  time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: sunset,
+	}}})
+	user := api.seed(t, entities.User{FirstName: "John", Region: "south"})
+
+	get := func(path string) (*http.Response, map[string]interface{}) {
+		res, body := api.do(t, http.MethodGet, path, "", nil)
+
+		var decoded struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+
+		return res, decoded.Data
+	}
+
+	for _, path := range []string{"/api/user/", "/api/v1/user/"} {
+		res, data := get(path + user.ID)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "@1780272000", res.Header.Get("Deprecation"))
+		assert.Equal(t, sunset.Format(http.TimeFormat), res.Header.Get("Sunset"))
+		assert.Contains(t, data, "create_at")
+	}
+
+	res, data := get("/api/v2/users/" + user.ID)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Deprecation"))
+	assert.Equal(t, "John", data["first_name"])
+	assert.Equal(t, "south", data["region"])
+	assert.Contains(t, data, "created_at")
+	assert.NotContains(t, data, "create_at")
+
+	// Writes answer with the DTOs of their version as well.
+	res, body := api.do(t, http.MethodPatch, "/api/v2/users/"+user.ID, `{"first_name":"Ringo"}`, nil)
+
+	var patched struct {
+		Data dtov2.UserResponseDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &patched))
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Ringo", patched.Data.FirstName)
+	assert.Equal(t, int64(2), patched.Data.Version)
+	assert.NotEmpty(t, patched.Data.UpdatedAt)
+}
+
+// TestAPIVersions_V2CoversV1 enforces that the v1 routes beyond the user
+// resource are in v2 too, answering with the DTOs of v2.
+func TestAPIVersions_V2CoversV1(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	john := api.seed(t, entities.User{FirstName: "John", Email: "john@example.com"})
+
+	data := func(body string) json.RawMessage {
+		var decoded struct {
+			Data json.RawMessage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+
+		return decoded.Data
+	}
+
+	api.search.On("SearchUsers", "joh", mock.Anything).Return([]*domain.UserSearchHit{{User: john, Rank: 1}}, nil)
+	res, body := api.do(t, http.MethodGet, "/api/v2/users/search?q=joh", "", nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var results []dtov2.UserSearchResultDTO
+	require.NoError(t, json.Unmarshal(data(body), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, john.ID, results[0].User.ID)
+	assert.NotEmpty(t, results[0].User.CreatedAt)
+
+	res, body = api.do(t, http.MethodGet, "/api/v2/users/export", "", nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.True(t, strings.HasPrefix(body, "id,first_name,last_name,email,role,created_at,updated_at,deleted_at,version,region\n"))
+
+	res, body = api.do(t, http.MethodGet, "/api/v2/users/export?format=ndjson", "", nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, body, `"created_at"`)
+	assert.NotContains(t, body, `"create_at"`)
+
+	file := "first_name,last_name,email,password\nGeorge,Harrison,george@example.com,password123\n"
+	res, body = api.do(t, http.MethodPost, "/api/v2/users/import", file, http.Header{"Content-Type": {"text/csv"}})
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var job dtov2.ImportJobDTO
+	require.NoError(t, json.Unmarshal(data(body), &job))
+	assert.Equal(t, 1, job.Succeeded)
+	assert.NotEmpty(t, job.CreatedAt)
+
+	res, body = api.do(t, http.MethodPost, "/api/v2/users:batch", `{"operations":[{"op":"patch","id":"`+john.ID+`","user":{"first_name":"Johnny"}}]}`, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var batch []dtov2.BatchWriteResultDTO
+	require.NoError(t, json.Unmarshal(data(body), &batch))
+	require.Len(t, batch, 1)
+	assert.Equal(t, "Johnny", batch[0].User.FirstName)
+	assert.Equal(t, int64(2), batch[0].User.Version)
+
+	// The custom method does not shadow the collection.
+	res, _ = api.do(t, http.MethodPost, "/api/v2/users", `{"first_name":"Paul","last_name":"McCartney","email":"paul@example.com","password":"password123"}`, nil)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+}
+
+func TestCreateUser_Idempotent(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+
+	post := func(key string, body string) (*http.Response, string) {
+		return api.do(t, http.MethodPost, "/api/user", body, http.Header{"Idempotency-Key": {key}})
+	}
+
+	body := `{"first_name":"Ringo","last_name":"Starr","email":"ringo@example.com","password":"password123"}`
+
+	res, created := post("signup-1", body)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Idempotent-Replayed"))
+
+	// The retry gets the same user instead of a conflict.
+	res, replayed := post("signup-1", body)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "true", res.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, created, replayed)
+
+	res, _ = post("signup-1", strings.Replace(body, "Ringo", "George", 1))
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+	// A new key is a new signup, for an email that is now taken.
+	res, _ = post("signup-2", body)
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	// Keys are scoped to the caller: another caller's signup-1 is a new signup.
+	res, _ = api.do(t, http.MethodPost, "/api/user", strings.Replace(body, "ringo@", "george@", 1), http.Header{
+		"Idempotency-Key": {"signup-1"},
+		"X-User-Id":       {"01J00000000000000000000002"},
+	})
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Idempotent-Replayed"))
+}
+
+func TestProblemDetails(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	api.seed(t, entities.User{FirstName: "John", Email: "john@example.com"})
+
+	body := `{"first_name":"Johnny","last_name":"Smith","email":"john@example.com","password":"password123"}`
+	res, data := api.do(t, http.MethodPost, "/api/user", body, http.Header{"X-Request-Id": {"req-42"}})
+
+	var problem dto.ProblemResponse
+	require.NoError(t, json.Unmarshal([]byte(data), &problem))
+
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+	assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+	assert.Equal(t, "/api/errors/user.email_taken", problem.Type)
+	assert.Equal(t, "user.email_taken", problem.Code)
+	assert.Equal(t, http.StatusConflict, problem.Status)
+	assert.Equal(t, "/api/user", problem.Instance)
+	assert.Equal(t, "req-42", problem.TraceID)
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "email", problem.Errors[0].Field)
+
+	// The type of the problem links to its catalogue entry.
+	res, data = api.do(t, http.MethodGet, problem.Type, "", nil)
+
+	var entry dto.ErrorCodeDTO
+	require.NoError(t, json.Unmarshal([]byte(data), &entry))
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, http.StatusConflict, entry.Status)
+}
+
+func TestBatchWriteUsers(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	john := api.seed(t, entities.User{FirstName: "John"})
+	paul := api.seed(t, entities.User{FirstName: "Paul"})
+
+	type result struct {
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+		Field  string `json:"field"`
+	}
+
+	batch := func(body string) (int, []result) {
+		res, data := api.do(t, http.MethodPost, "/api/users:batch", body, nil)
+
+		var response struct {
+			Data []result `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(data), &response))
+
+		return res.StatusCode, response.Data
+	}
+
+	// The invalid create rolls back the patch and the delete.
+	status, results := batch(`{"mode":"atomic","operations":[
+		{"op":"patch","id":"` + john.ID + `","user":{"first_name":"Johnny"}},
+		{"op":"delete","id":"` + paul.ID + `"},
+		{"op":"create","user":{"first_name":"George","last_name":"Harrison","email":"george","password":"password123"}}
+	]}`)
+	assert.Equal(t, http.StatusMultiStatus, status)
+	require.Len(t, results, 3)
+	assert.Equal(t, http.StatusFailedDependency, results[0].Status)
+	assert.Equal(t, http.StatusFailedDependency, results[1].Status)
+	assert.Equal(t, http.StatusBadRequest, results[2].Status)
+	assert.Equal(t, "email", results[2].Field)
+
+	user, err := api.users.FindUserById(john.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "John", user.FirstName)
+
+	status, results = batch(`{"mode":"best_effort","operations":[
+		{"op":"patch","id":"` + john.ID + `","user":{"first_name":"Johnny"}},
+		{"op":"delete","id":"01HZ0000000000000000000000"},
+		{"op":"create","user":{"first_name":"George","last_name":"Harrison","email":"george@example.com","password":"password123"}}
+	]}`)
+	assert.Equal(t, http.StatusMultiStatus, status)
+	require.Len(t, results, 3)
+	assert.Equal(t, http.StatusOK, results[0].Status)
+	assert.Equal(t, http.StatusNotFound, results[1].Status)
+	assert.Equal(t, http.StatusCreated, results[2].Status)
+
+	status, results = batch(`{"operations":[{"op":"delete","id":"` + paul.ID + `"}]}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, http.StatusOK, results[0].Status)
+
+	res, _ := api.do(t, http.MethodPost, "/api/users:purge", `{}`, nil)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+// TestPolicy_OperationsServedByRPC verifies that the operations served by the
+// gRPC server are authorized with the caller and the environment of the HTTP
+// request, and that their errors are answered as problems.
+func TestPolicy_OperationsServedByRPC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+version: "1"
+default: deny
+rules:
+  - name: admins-list-over-http
+    effect: allow
+    actions: ["user.list"]
+    condition: subject.role == "admin" && env.transport == "http" && env.path == "/api/users"
+  - name: self-service
+    effect: allow
+    actions: ["user.read"]
+    condition: subject.id == resource.id
+`), 0o644))
+
+	engine, err := policy.NewEngine(path, nil)
+	require.NoError(t, err)
+
+	api := newTestAPI(t, testAPIConfig{policy: engine})
+	user := api.seed(t, entities.User{})
+
+	res, _ := api.do(t, http.MethodGet, "/api/users", "", http.Header{"X-User-Role": {"admin"}})
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, body := api.do(t, http.MethodGet, "/api/users", "", http.Header{"X-User-Role": {"user"}})
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Contains(t, body, `"code":"`+string(errcode.Forbidden)+`"`)
+
+	res, _ = api.do(t, http.MethodGet, "/api/user/"+user.ID, "", http.Header{"X-User-Id": {user.ID}})
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// Users the caller may not read look missing.
+	res, body = api.do(t, http.MethodGet, "/api/user/"+user.ID, "", http.Header{"X-User-Id": {"someone-else"}})
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Contains(t, body, `"code":"`+string(errcode.UserNotFound)+`"`)
+}
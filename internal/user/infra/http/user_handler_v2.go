@@ -0,0 +1,214 @@
+package http
+
+import "github.com/gin-gonic/gin"
+
+// The v2 handlers behave as their v1 counterparts; the Version middleware of
+// the /api/v2 routes makes them answer with the DTOs of v2.
+
+// @Tags Users v2
+// @Summary Create a new user
+// @Description Create a new user, as POST /user does. Retries sent with the same Idempotency-Key and body get the stored response of the first request back.
+// @Accept  json
+// @Produce  json
+// @Param user body dto.UserRequestDTO true "User"
+// @Param Idempotency-Key header string false "Unique key of the request, at most 255 characters"
+// @Success 201 {object} dtov2.UserResponseDTO
+// @Header 201 {string} Idempotent-Replayed "true when the response is replayed"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 422 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/users [post]
+func (h *UserHandler) CreateUserV2(ctx *gin.Context) {
+	h.CreateUser(ctx)
+}
+
+// @Tags Users v2
+// @Summary Get user by id
+// @Description Get user by id, as GET /user/{id} does.
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param If-None-Match header string false "ETag of a previous response"
+// @Success 200 {object} dtov2.UserResponseDTO
+// @Header 200 {string} ETag "Version of the user"
+// @Success 304
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/users/{id} [get]
+func (h *UserHandler) GetUserByIdV2(ctx *gin.Context) {
+	h.GetUserById(ctx)
+}
+
+// @Tags Users v2
+// @Summary List users
+// @Description List users, as GET /users does.
+// @Produce  json
+// @Param cursor query string false "Cursor from a previous response"
+// @Param page_size query int false "Users per page, at most 100" default(10)
+// @Param include_total query bool false "Include the total number of users"
+// @Param sort query string false "Sort field" Enums(id, created_at, updated_at, email, first_name, last_name)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param role query string false "Only users with this role" Enums(admin, super, user)
+// @Param email_domain query string false "Only users whose email is at this domain"
+// @Param created_after query string false "Created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_after query string false "Updated at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_before query string false "Updated before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param deleted query string false "Deleted users to list" Enums(exclude, include, only)
+// @Success 200 {array} dtov2.UserResponseDTO
+// @Header 200 {string} Link "Links to the first and next pages (RFC 8288)"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/users [get]
+func (h *UserHandler) ListUsersV2(ctx *gin.Context) {
+	h.ListUsers(ctx)
+}
+
+// @Tags Users v2
+// @Summary Patch user
+// @Description Patch user, as PATCH /user/{id} does, with a partial user, a merge patch or a JSON patch.
+// @Accept  json
+// @Accept  application/merge-patch+json
+// @Accept  application/json-patch+json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag the user must still have"
+// @Param user body dto.PatchRequestDTO true "User, merge patch or JSON patch"
+// @Success 200 {object} dtov2.UserResponseDTO
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 412 {object} dto.ProblemResponse
+// @Failure 415 {object} dto.ProblemResponse
+// @Failure 422 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/users/{id} [patch]
+func (h *UserHandler) PatchUserV2(ctx *gin.Context) {
+	h.PatchUser(ctx)
+}
+
+// @Tags Users v2
+// @Summary Replace user
+// @Description Replace every mutable field of a user, as PUT /user/{id} does.
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag the user must still have"
+// @Param user body dto.UserRequestDTO true "User"
+// @Success 200 {object} dtov2.UserResponseDTO
+// @Success 201 {object} dtov2.UserResponseDTO
+// @Header 200,201 {string} ETag "New version of the user"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 409 {object} dto.ProblemResponse
+// @Failure 410 {object} dto.ProblemResponse
+// @Failure 412 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/users/{id} [put]
+func (h *UserHandler) ReplaceUserV2(ctx *gin.Context) {
+	h.ReplaceUser(ctx)
+}
+
+// @Tags Users v2
+// @Summary Delete user
+// @Description Delete user, as DELETE /user/{id} does.
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag the user must still have"
+// @Success 200 {object} dtov2.UserResponseDTO
+// @Failure 404 {object} dto.ProblemResponse
+// @Failure 412 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/users/{id} [delete]
+func (h *UserHandler) DeleteUserV2(ctx *gin.Context) {
+	h.DeleteUser(ctx)
+}
+
+// @Tags Users v2
+// @Summary Search users
+// @Description Search users, as GET /users/search does.
+// @Produce  json
+// @Param q query string true "Search text, 2 to 200 characters"
+// @Param limit query int false "Maximum number of results, at most 100" default(20)
+// @Success 200 {array} dtov2.UserSearchResultDTO
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/users/search [get]
+func (h *UserHandler) SearchUsersV2(ctx *gin.Context) {
+	h.SearchUsers(ctx)
+}
+
+// @Tags Users v2
+// @Summary Export users as CSV or NDJSON
+// @Description Export users, as GET /users/export does. The CSV columns and the NDJSON lines are named like the fields of a v2 user.
+// @Produce  text/csv
+// @Produce  application/x-ndjson
+// @Param format query string false "Format of the file" Enums(csv, ndjson) default(csv)
+// @Param role query string false "Only users with this role" Enums(admin, super, user)
+// @Param email_domain query string false "Only users whose email is at this domain, e.g. example.com"
+// @Param created_after query string false "Only users created at or after this time, RFC 3339 or YYYY-MM-DD"
+// @Param created_before query string false "Only users created before this time, RFC 3339 or YYYY-MM-DD"
+// @Param updated_after query string false "Only users updated at or after this time, RFC 3339 or YYYY-MM-DD"
+// @Param updated_before query string false "Only users updated before this time, RFC 3339 or YYYY-MM-DD"
+// @Param deleted query string false "Deleted users to include" Enums(exclude, include, only) default(exclude)
+// @Param sort query string false "Field to sort by" Enums(id, created_at, updated_at, email, first_name, last_name) default(id)
+// @Param order query string false "Sort order" Enums(asc, desc) default(asc)
+// @Param Accept-Encoding header string false "gzip to compress the export"
+// @Success 200 {string} string "One user per row or line"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/users/export [get]
+func (h *UserHandler) ExportUsersV2(ctx *gin.Context) {
+	h.ExportUsers(ctx)
+}
+
+// @Tags Users v2
+// @Summary Import users from a CSV or NDJSON file
+// @Description Import users, as POST /users/import does.
+// @Accept  text/csv
+// @Accept  application/x-ndjson
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file false "File, when sent as a form"
+// @Param format query string false "Format of the file, taken from the content type or the file name by default" Enums(csv, ndjson)
+// @Param mapping query string false "Columns, or keys, of the user fields, such as first_name=Given name,email=Work email"
+// @Param dry_run query bool false "Only validate the rows"
+// @Success 200 {object} dtov2.ImportJobDTO
+// @Success 202 {object} dtov2.ImportJobDTO
+// @Header 202 {string} Location "URL of the import job"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 413 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Failure 503 {object} dto.ProblemResponse
+// @Router /v2/users/import [post]
+func (h *UserHandler) ImportUsersV2(ctx *gin.Context) {
+	h.ImportUsers(ctx)
+}
+
+// @Tags Users v2
+// @Summary Get an import job
+// @Description Get an import job, as GET /users/import/{id} does.
+// @Produce  json
+// @Param id path string true "Import job ID"
+// @Success 200 {object} dtov2.ImportJobDTO
+// @Failure 404 {object} dto.ProblemResponse
+// @Router /v2/users/import/{id} [get]
+func (h *UserHandler) GetImportJobV2(ctx *gin.Context) {
+	h.GetImportJob(ctx)
+}
+
+// @Tags Users v2
+// @Summary Create, patch and delete users in one request
+// @Description Apply a batch of operations, as POST /users:batch does.
+// @Accept  json
+// @Produce  json
+// @Param batch body dto.BatchWriteRequestDTO true "Operations"
+// @Success 200 {array} dtov2.BatchWriteResultDTO
+// @Success 207 {array} dtov2.BatchWriteResultDTO
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Router /v2/users:batch [post]
+func (h *UserHandler) BatchWriteUsersV2(ctx *gin.Context) {
+	h.BatchWriteUsers(ctx)
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/jonattasmoraes/titan/internal/utils"
+)
+
+// maxImportBytes caps the size of an import file.
+const maxImportBytes = 10 << 20
+
+// importFormats maps file extensions and content types to import formats.
+var importFormats = map[string]string{
+	"csv":                     usecase.ImportFormatCSV,
+	"ndjson":                  usecase.ImportFormatNDJSON,
+	"jsonl":                   usecase.ImportFormatNDJSON,
+	"text/csv":                usecase.ImportFormatCSV,
+	"application/x-ndjson":    usecase.ImportFormatNDJSON,
+	"application/ndjson":      usecase.ImportFormatNDJSON,
+	"application/jsonl":       usecase.ImportFormatNDJSON,
+	"application/x-jsonlines": usecase.ImportFormatNDJSON,
+}
+
+// @Tags Users
+// @Summary Import users from a CSV or NDJSON file
+// @Description Create users from a CSV file with a header line, or an NDJSON file with one object per line, sent as the body or as the file field of a form. Every row is validated like a sign-up, and emails that are taken or repeated in the file are rejected. With dry_run, nothing is written and the report tells which rows would be created. Files of up to 100 rows are imported within the request; larger ones, up to 10000 rows, are answered with 202 and imported in the background, poll the Location header for progress. While the service shuts down, they are answered with 503.
+// @Accept  text/csv
+// @Accept  application/x-ndjson
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file false "File, when sent as a form"
+// @Param format query string false "Format of the file, taken from the content type or the file name by default" Enums(csv, ndjson)
+// @Param mapping query string false "Columns, or keys, of the user fields, such as first_name=Given name,email=Work email"
+// @Param dry_run query bool false "Only validate the rows"
+// @Success 200 {object} dto.ImportJobDTO
+// @Success 202 {object} dto.ImportJobDTO
+// @Header 202 {string} Location "URL of the import job"
+// @Failure 400 {object} dto.ProblemResponse
+// @Failure 413 {object} dto.ProblemResponse
+// @Failure 500 {object} dto.ProblemResponse
+// @Failure 503 {object} dto.ProblemResponse
+// @Router /users/import [post]
+func (h *UserHandler) ImportUsers(ctx *gin.Context) {
+	if !authorize(ctx, h.policy, "user.import", nil) {
+		return
+	}
+
+	dryRun := false
+	if value, ok := ctx.GetQuery("dry_run"); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			sendError(ctx, invalidParameter("dry_run", "invalid dry_run, use 'true' or 'false'"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	mapping, err := usecase.ParseImportMapping(ctx.Query("mapping"))
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBytes)
+
+	file, name, err := importFile(ctx)
+	if err != nil {
+		sendImportError(ctx, err)
+		return
+	}
+	defer file.Close()
+
+	records, err := usecase.ParseImportFile(file, importFormat(ctx, name), mapping)
+	if err != nil {
+		sendImportError(ctx, err)
+		return
+	}
+
+	job, err := h.importUsers.Execute(records, dryRun)
+	if err != nil {
+		sendImportError(ctx, err)
+		return
+	}
+
+	if job.Status == usecase.ImportJobRunning {
+		ctx.Header("Location", strings.TrimSuffix(ctx.Request.URL.Path, "/")+"/"+job.ID)
+		utils.SendSuccess(ctx, "import users", presentImportJob(ctx, job), http.StatusAccepted)
+		return
+	}
+
+	utils.SendSuccess(ctx, "import users", presentImportJob(ctx, job), http.StatusOK)
+}
+
+// @Tags Users
+// @Summary Get an import job
+// @Description Get the progress and the report of an import. Finished imports are kept for an hour.
+// @Produce  json
+// @Param id path string true "Import job ID"
+// @Success 200 {object} dto.ImportJobDTO
+// @Failure 404 {object} dto.ProblemResponse
+// @Router /users/import/{id} [get]
+func (h *UserHandler) GetImportJob(ctx *gin.Context) {
+	if !authorize(ctx, h.policy, "user.import", nil) {
+		return
+	}
+
+	job, err := h.importUsers.Job(ctx.Param("id"))
+	if err != nil {
+		sendError(ctx, err)
+		return
+	}
+
+	utils.SendSuccess(ctx, "get import job", presentImportJob(ctx, job), http.StatusOK)
+}
+
+// importFile returns the uploaded file of a form, or else the body, with the
+// name of the file when there is one.
+func importFile(ctx *gin.Context) (io.ReadCloser, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return ctx.Request.Body, "", nil
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return file, header.Filename, nil
+}
+
+// importFormat picks the format from the format parameter, the extension of
+// the file name or the content type, in that order.
+func importFormat(ctx *gin.Context, name string) string {
+	if format, ok := ctx.GetQuery("format"); ok {
+		if known, ok := importFormats[format]; ok {
+			return known
+		}
+		return format
+	}
+
+	if format, ok := importFormats[strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")]; ok {
+		return format
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+
+	return importFormats[mediaType]
+}
+
+// sendImportError reports the files that could not be read like the errors
+// of their content.
+func sendImportError(ctx *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		sendError(ctx, &errcode.Error{Code: errcode.BodyTooLarge, Message: "the file is larger than 10 MB", Err: err})
+		return
+	}
+
+	if err == http.ErrMissingFile {
+		sendError(ctx, &errcode.Error{Code: errcode.ImportFileInvalid, Message: err.Error(), Err: err})
+		return
+	}
+
+	sendError(ctx, err)
+}
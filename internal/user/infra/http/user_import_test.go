@@ -0,0 +1,72 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportUsers(t *testing.T) {
+	api := newTestAPI(t, testAPIConfig{})
+	api.seed(t, entities.User{FirstName: "John", Email: "john@example.com"})
+
+	type job struct {
+		ID        string `json:"id"`
+		Status    string `json:"status"`
+		Succeeded int    `json:"succeeded"`
+		Failed    int    `json:"failed"`
+		Rows      []struct {
+			Line   int    `json:"line"`
+			Status string `json:"status"`
+			Field  string `json:"field"`
+		} `json:"rows"`
+	}
+
+	file := "Given name,last_name,email,password\n" +
+		"George,Harrison,george@example.com,password123\n" +
+		"Ringo,Starr,ringo,password123\n" +
+		"John,Lennon,john@example.com,password123\n"
+
+	importUsers := func(query string) (int, job) {
+		res, body := api.do(t, http.MethodPost, "/api/users/import?"+query, file, http.Header{"Content-Type": {"text/csv"}})
+
+		var response struct {
+			Data job `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &response))
+
+		return res.StatusCode, response.Data
+	}
+
+	status, report := importUsers("dry_run=true&mapping=first_name=Given+name")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "completed", report.Status)
+	assert.Equal(t, 1, report.Succeeded)
+	assert.Equal(t, 2, report.Failed)
+	require.Len(t, report.Rows, 3)
+	assert.Equal(t, 2, report.Rows[0].Line)
+	assert.Equal(t, "valid", report.Rows[0].Status)
+	assert.Equal(t, "invalid", report.Rows[1].Status)
+	assert.Equal(t, "email", report.Rows[1].Field)
+	assert.Equal(t, "duplicate", report.Rows[2].Status)
+
+	// The dry run created nothing.
+	count, err := api.users.CountUsers(domain.ListUsersQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	status, report = importUsers("mapping=first_name=Given+name")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "created", report.Rows[0].Status)
+
+	res, _ := api.do(t, http.MethodGet, "/api/users/import/"+report.ID, "", nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	status, _ = importUsers("mapping=first_name=Missing")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	dtov2 "github.com/jonattasmoraes/titan/internal/user/domain/DTO/v2"
+)
+
+// The versions of the HTTP API. The routes under the bare /api group are v1.
+const (
+	APIVersion1 = "v1"
+	APIVersion2 = "v2"
+)
+
+const (
+	apiVersionKey = "api_version"
+	deprecatedKey = "api_deprecated"
+)
+
+// Deprecation announces that routes are going away. The zero Deprecation
+// leaves routes undeprecated.
+type Deprecation struct {
+	// Since is when the routes were deprecated.
+	Since time.Time
+	// Sunset is when the routes stop answering, zero while it is not planned.
+	Sunset time.Time
+}
+
+// Version is the middleware of the routes of an API version. Responses are
+// rendered with the DTOs of the version, and each one is logged with it, so
+// the remaining usage of a deprecated version can be tracked.
+func Version(version string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(apiVersionKey, version)
+
+		start := time.Now()
+		ctx.Next()
+
+		log.Printf("api_version=%s deprecated=%t method=%s route=%s status=%d latency=%s",
+			version, ctx.GetBool(deprecatedKey), ctx.Request.Method, ctx.FullPath(), ctx.Writer.Status(), time.Since(start))
+	}
+}
+
+// Deprecated is the middleware of deprecated routes. It sends the
+// Deprecation header (RFC 9745) and, once planned, the Sunset header
+// (RFC 8594). It does nothing for the zero Deprecation.
+func Deprecated(deprecation Deprecation) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if deprecation.Since.IsZero() {
+			return
+		}
+
+		ctx.Set(deprecatedKey, true)
+		ctx.Header("Deprecation", "@"+strconv.FormatInt(deprecation.Since.Unix(), 10))
+
+		if !deprecation.Sunset.IsZero() {
+			ctx.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+		}
+	}
+}
+
+// apiVersion is the API version of the request, v1 for routes without one.
+func apiVersion(ctx *gin.Context) string {
+	if version := ctx.GetString(apiVersionKey); version != "" {
+		return version
+	}
+
+	return APIVersion1
+}
+
+// presentUser is user as the API version of the request renders it.
+func presentUser(ctx *gin.Context, user *dto.UserResponseDTO) interface{} {
+	if apiVersion(ctx) != APIVersion2 {
+		return user
+	}
+
+	return userV2(user)
+}
+
+// presentUsers is presentUser for lists.
+func presentUsers(ctx *gin.Context, users []*dto.UserResponseDTO) interface{} {
+	if apiVersion(ctx) != APIVersion2 {
+		return users
+	}
+
+	response := make([]*dtov2.UserResponseDTO, len(users))
+	for i, user := range users {
+		response[i] = userV2(user)
+	}
+
+	return response
+}
+
+// presentSearchResults is presentUser for search results.
+func presentSearchResults(ctx *gin.Context, results []*dto.UserSearchResultDTO) interface{} {
+	if apiVersion(ctx) != APIVersion2 {
+		return results
+	}
+
+	response := make([]*dtov2.UserSearchResultDTO, len(results))
+	for i, result := range results {
+		response[i] = &dtov2.UserSearchResultDTO{User: userV2(result.User), Rank: result.Rank, Highlights: result.Highlights}
+	}
+
+	return response
+}
+
+// presentBatchResults is presentUser for the results of a batch.
+func presentBatchResults(ctx *gin.Context, results []dto.BatchWriteResultDTO) interface{} {
+	if apiVersion(ctx) != APIVersion2 {
+		return results
+	}
+
+	response := make([]dtov2.BatchWriteResultDTO, len(results))
+	for i, result := range results {
+		response[i] = dtov2.BatchWriteResultDTO{
+			Index:  result.Index,
+			Op:     result.Op,
+			Status: result.Status,
+			User:   userV2(result.User),
+			Error:  result.Error,
+			Code:   result.Code,
+			Field:  result.Field,
+		}
+	}
+
+	return response
+}
+
+// presentImportJob is job as the API version of the request renders it.
+func presentImportJob(ctx *gin.Context, job *dto.ImportJobDTO) interface{} {
+	if apiVersion(ctx) != APIVersion2 {
+		return job
+	}
+
+	return &dtov2.ImportJobDTO{
+		ID:            job.ID,
+		Status:        job.Status,
+		DryRun:        job.DryRun,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		Succeeded:     job.Succeeded,
+		Failed:        job.Failed,
+		Rows:          job.Rows,
+		CreatedAt:     job.CreateAt,
+		FinishedAt:    job.FinishedAt,
+	}
+}
+
+// presentInvitation is invitation as the API version of the request renders
+// it.
+func presentInvitation(ctx *gin.Context, invitation *dto.InvitationResponseDTO) interface{} {
+	if apiVersion(ctx) != APIVersion2 {
+		return invitation
+	}
+
+	return &dtov2.InvitationResponseDTO{
+		ID:             invitation.ID,
+		OrganizationID: invitation.OrganizationID,
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		Status:         invitation.Status,
+		Token:          invitation.Token,
+		ExpiresAt:      invitation.ExpiresAt,
+		CreatedAt:      invitation.CreateAt,
+		UpdatedAt:      invitation.UpdateAt,
+	}
+}
+
+// presentMembership is presentUser for the membership of an accepted
+// invitation.
+func presentMembership(ctx *gin.Context, membership *dto.MembershipResponseDTO) interface{} {
+	if apiVersion(ctx) != APIVersion2 {
+		return membership
+	}
+
+	return &dtov2.MembershipResponseDTO{
+		OrganizationID: membership.OrganizationID,
+		Role:           membership.Role,
+		User:           userV2(membership.User),
+	}
+}
+
+func userV2(user *dto.UserResponseDTO) *dtov2.UserResponseDTO {
+	if user == nil {
+		return nil
+	}
+
+	return &dtov2.UserResponseDTO{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Role:      user.Role,
+		Region:    user.Region,
+		CreatedAt: user.CreateAt,
+		UpdatedAt: user.UpdateAt,
+		DeletedAt: user.DeleteAt,
+		Version:   user.Version,
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+)
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[memoryIdempotencyKey]domain.IdempotencyRecord
+}
+
+type memoryIdempotencyKey struct {
+	subject string
+	key     string
+}
+
+func memoryIdempotencyKeyOf(record *domain.IdempotencyRecord) memoryIdempotencyKey {
+	return memoryIdempotencyKey{subject: record.Subject, key: record.Key}
+}
+
+// NewMemoryIdempotencyStore keeps idempotency records in the memory of the
+// process. Records are lost on restart and are not shared between replicas,
+// so it is meant for single instances and tests.
+func NewMemoryIdempotencyStore() domain.IdempotencyStore {
+	return &memoryIdempotencyStore{records: map[memoryIdempotencyKey]domain.IdempotencyRecord{}}
+}
+
+func (s *memoryIdempotencyStore) ReserveIdempotencyKey(record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memoryIdempotencyKeyOf(record)
+
+	if existing, ok := s.records[key]; ok && existing.ExpiresAt.After(now) {
+		existing.Body = append([]byte(nil), existing.Body...)
+		return &existing, nil
+	}
+
+	s.records[key] = domain.IdempotencyRecord{
+		Subject:     record.Subject,
+		Key:         record.Key,
+		Fingerprint: record.Fingerprint,
+		Token:       record.Token,
+		ExpiresAt:   record.ExpiresAt,
+	}
+
+	return nil, nil
+}
+
+func (s *memoryIdempotencyStore) CompleteIdempotencyKey(reserved *domain.IdempotencyRecord, statusCode int, body []byte, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memoryIdempotencyKeyOf(reserved)
+
+	record, ok := s.records[key]
+	if !ok || record.Token != reserved.Token {
+		return nil
+	}
+
+	record.StatusCode = statusCode
+	record.Body = append([]byte(nil), body...)
+	record.ExpiresAt = expiresAt
+	s.records[key] = record
+
+	return nil
+}
+
+func (s *memoryIdempotencyStore) ReleaseIdempotencyKey(reserved *domain.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memoryIdempotencyKeyOf(reserved)
+
+	if record, ok := s.records[key]; ok && record.Token == reserved.Token {
+		delete(s.records, key)
+	}
+
+	return nil
+}
+
+func (s *memoryIdempotencyStore) DeleteExpiredIdempotencyKeys(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for key, record := range s.records {
+		if !record.ExpiresAt.After(now) {
+			delete(s.records, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
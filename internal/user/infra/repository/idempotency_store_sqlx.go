@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+)
+
+type idempotencyStoreSqlx struct {
+	writer *sqlx.DB
+	reader *sqlx.DB
+}
+
+func NewSqlxIdempotencyStore(writer, reader *sqlx.DB) domain.IdempotencyStore {
+	return &idempotencyStoreSqlx{writer: writer, reader: reader}
+}
+
+// ReserveIdempotencyKey inserts a pending record, or takes over an expired
+// one, in a single statement so that concurrent requests with the same
+// subject and key cannot both reserve it.
+//
+// The upsert syntax is supported by both Postgres and SQLite.
+func (r *idempotencyStoreSqlx) ReserveIdempotencyKey(record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	query := `
+	INSERT INTO idempotency_keys (subject, idempotency_key, fingerprint, token, status_code, body, expires_at)
+	VALUES ($1, $2, $3, $4, 0, NULL, $5)
+	ON CONFLICT (subject, idempotency_key) DO UPDATE
+	SET fingerprint = excluded.fingerprint, token = excluded.token, status_code = 0, body = NULL, expires_at = excluded.expires_at
+	WHERE idempotency_keys.expires_at <= $6
+	`
+
+	result, err := r.writer.Exec(query, record.Subject, record.Key, record.Fingerprint, record.Token, record.ExpiresAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if reserved == 1 {
+		return nil, nil
+	}
+
+	// Read from the writer, the record may be too recent for a replica.
+	query = `
+	SELECT subject, idempotency_key, fingerprint, token, status_code, body, expires_at
+	FROM idempotency_keys
+	WHERE subject = $1 AND idempotency_key = $2
+	`
+
+	var (
+		existing domain.IdempotencyRecord
+		body     sql.NullString
+	)
+
+	err = r.writer.QueryRow(query, record.Subject, record.Key).Scan(
+		&existing.Subject,
+		&existing.Key,
+		&existing.Fingerprint,
+		&existing.Token,
+		&existing.StatusCode,
+		&body,
+		&existing.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.Body = []byte(body.String)
+
+	return &existing, nil
+}
+
+// CompleteIdempotencyKey stores the response of a reserved record, unless
+// another request took the key over.
+func (r *idempotencyStoreSqlx) CompleteIdempotencyKey(reserved *domain.IdempotencyRecord, statusCode int, body []byte, expiresAt time.Time) error {
+	query := `
+	UPDATE idempotency_keys
+	SET status_code = $1, body = $2, expires_at = $3
+	WHERE subject = $4 AND idempotency_key = $5 AND token = $6
+	`
+
+	_, err := r.writer.Exec(query, statusCode, string(body), expiresAt, reserved.Subject, reserved.Key, reserved.Token)
+
+	return err
+}
+
+// ReleaseIdempotencyKey deletes a reserved record, unless another request
+// took the key over.
+func (r *idempotencyStoreSqlx) ReleaseIdempotencyKey(reserved *domain.IdempotencyRecord) error {
+	query := `
+	DELETE FROM idempotency_keys
+	WHERE subject = $1 AND idempotency_key = $2 AND token = $3
+	`
+
+	_, err := r.writer.Exec(query, reserved.Subject, reserved.Key, reserved.Token)
+
+	return err
+}
+
+// DeleteExpiredIdempotencyKeys deletes the records expired by now.
+func (r *idempotencyStoreSqlx) DeleteExpiredIdempotencyKeys(now time.Time) (int64, error) {
+	result, err := r.writer.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
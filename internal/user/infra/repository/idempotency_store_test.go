@@ -0,0 +1,145 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupIdempotencyTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to connect to SQLite in-memory database: %v", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE idempotency_keys (
+		subject TEXT NOT NULL DEFAULT '',
+		idempotency_key TEXT NOT NULL,
+		fingerprint TEXT NOT NULL,
+		token TEXT NOT NULL DEFAULT '',
+		status_code INTEGER NOT NULL DEFAULT 0,
+		body TEXT,
+		expires_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (subject, idempotency_key)
+	)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create idempotency_keys table: %v", err)
+	}
+
+	return db
+}
+
+func TestIdempotencyStores(t *testing.T) {
+	db := setupIdempotencyTestDB(t)
+	defer db.Close()
+
+	for name, store := range map[string]domain.IdempotencyStore{
+		"sqlx":   repository.NewSqlxIdempotencyStore(db, db),
+		"memory": repository.NewMemoryIdempotencyStore(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			// Each fingerprint is sent once, so it doubles as the token.
+			record := func(key, fingerprint string) *domain.IdempotencyRecord {
+				return &domain.IdempotencyRecord{Subject: "john", Key: key, Fingerprint: fingerprint, Token: fingerprint, ExpiresAt: now.Add(time.Minute)}
+			}
+
+			// The first request reserves the key.
+			existing, err := store.ReserveIdempotencyKey(record("key-1", "a"), now)
+			require.NoError(t, err)
+			assert.Nil(t, existing)
+
+			// A concurrent retry sees the pending record.
+			existing, err = store.ReserveIdempotencyKey(record("key-1", "a"), now)
+			require.NoError(t, err)
+			require.NotNil(t, existing)
+			assert.True(t, existing.Pending())
+
+			// Once complete, retries get the response.
+			require.NoError(t, store.CompleteIdempotencyKey(record("key-1", "a"), 201, []byte(`{"id":"1"}`), now.Add(time.Hour)))
+
+			existing, err = store.ReserveIdempotencyKey(record("key-1", "b"), now.Add(30*time.Minute))
+			require.NoError(t, err)
+			require.NotNil(t, existing)
+			assert.Equal(t, "a", existing.Fingerprint)
+			assert.Equal(t, 201, existing.StatusCode)
+			assert.Equal(t, `{"id":"1"}`, string(existing.Body))
+
+			// An expired record is taken over.
+			existing, err = store.ReserveIdempotencyKey(record("key-1", "b"), now.Add(time.Hour))
+			require.NoError(t, err)
+			assert.Nil(t, existing)
+
+			// A released key can be reserved again.
+			require.NoError(t, store.ReleaseIdempotencyKey(record("key-1", "b")))
+			existing, err = store.ReserveIdempotencyKey(record("key-1", "c"), now)
+			require.NoError(t, err)
+			assert.Nil(t, existing)
+
+			// Another caller has keys of its own.
+			other := record("key-1", "d")
+			other.Subject = "paul"
+			existing, err = store.ReserveIdempotencyKey(other, now)
+			require.NoError(t, err)
+			assert.Nil(t, existing)
+
+			_, err = store.ReserveIdempotencyKey(record("key-2", "e"), now.Add(-time.Hour))
+			require.NoError(t, err)
+
+			deleted, err := store.DeleteExpiredIdempotencyKeys(now.Add(2 * time.Minute))
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), deleted)
+		})
+	}
+}
+
+// TestIdempotencyStores_TakenOver verifies that a request whose reservation
+// expired and was taken over by a retry of the same request does not complete
+// or release the key of the retry.
+func TestIdempotencyStores_TakenOver(t *testing.T) {
+	db := setupIdempotencyTestDB(t)
+	defer db.Close()
+
+	for name, store := range map[string]domain.IdempotencyStore{
+		"sqlx":   repository.NewSqlxIdempotencyStore(db, db),
+		"memory": repository.NewMemoryIdempotencyStore(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+			slow := &domain.IdempotencyRecord{Subject: "john", Key: "key-1", Fingerprint: "a", Token: "1", ExpiresAt: now.Add(time.Minute)}
+			existing, err := store.ReserveIdempotencyKey(slow, now)
+			require.NoError(t, err)
+			require.Nil(t, existing)
+
+			later := &domain.IdempotencyRecord{Subject: "john", Key: "key-1", Fingerprint: "a", Token: "2", ExpiresAt: now.Add(3 * time.Minute)}
+			existing, err = store.ReserveIdempotencyKey(later, now.Add(2*time.Minute))
+			require.NoError(t, err)
+			require.Nil(t, existing)
+
+			require.NoError(t, store.CompleteIdempotencyKey(slow, 201, []byte(`{"id":"1"}`), now.Add(time.Hour)))
+			require.NoError(t, store.ReleaseIdempotencyKey(slow))
+
+			existing, err = store.ReserveIdempotencyKey(later, now.Add(2*time.Minute))
+			require.NoError(t, err)
+			require.NotNil(t, existing)
+			assert.Equal(t, "2", existing.Token)
+			assert.True(t, existing.Pending())
+
+			require.NoError(t, store.CompleteIdempotencyKey(later, 201, []byte(`{"id":"2"}`), now.Add(time.Hour)))
+
+			existing, err = store.ReserveIdempotencyKey(later, now.Add(2*time.Minute))
+			require.NoError(t, err)
+			require.NotNil(t, existing)
+			assert.Equal(t, `{"id":"2"}`, string(existing.Body))
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+type invitationRepoSqlx struct {
+	writer sqlxConn
+	reader sqlxConn
+}
+
+func NewSqlxInvitationRepository(writer, reader *sqlx.DB) domain.InvitationRepository {
+	return &invitationRepoSqlx{writer: writer, reader: reader}
+}
+
+const invitationColumns = `id, organization_id, email, role, token_hash, status, invited_by, user_id,
+	expires_at, accepted_at, revoked_at, created_at, updated_at`
+
+// CreateInvitation inserts a new invitation into the database.
+//
+// Only the token hash is persisted, the raw token never reaches the database.
+func (r *invitationRepoSqlx) CreateInvitation(invitation *entities.Invitation) error {
+	query := `
+	INSERT INTO invitations (` + invitationColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := r.writer.Exec(query,
+		invitation.ID,
+		invitation.OrganizationID,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.Status,
+		invitation.InvitedBy,
+		nullString(invitation.UserID),
+		invitation.ExpiresAt,
+		nullTime(invitation.AcceptedAt),
+		nullTime(invitation.RevokedAt),
+		invitation.CreatedAt,
+		invitation.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindInvitationById retrieves an invitation by its ID.
+//
+// It returns nil without an error when no invitation matches.
+func (r *invitationRepoSqlx) FindInvitationById(id string) (*entities.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE id = $1`
+
+	return r.findInvitation(query, id)
+}
+
+// FindInvitationByTokenHash retrieves an invitation by the hash of its token.
+//
+// It returns nil without an error when no invitation matches.
+func (r *invitationRepoSqlx) FindInvitationByTokenHash(tokenHash string) (*entities.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE token_hash = $1`
+
+	return r.findInvitation(query, tokenHash)
+}
+
+// FindPendingInvitation retrieves the pending invitation for an email in an
+// organization, if there is one.
+func (r *invitationRepoSqlx) FindPendingInvitation(organizationID string, email string) (*entities.Invitation, error) {
+	query := `
+	SELECT ` + invitationColumns + `
+	FROM invitations
+	WHERE organization_id = $1 AND email = $2 AND status = $3
+	ORDER BY created_at DESC
+	LIMIT 1
+	`
+
+	return r.findInvitation(query, organizationID, email, entities.InvitationPending)
+}
+
+// UpdateInvitation persists the token, status and lifecycle timestamps of an invitation.
+func (r *invitationRepoSqlx) UpdateInvitation(invitation *entities.Invitation) error {
+	query := `
+	UPDATE invitations
+	SET token_hash = $1, status = $2, user_id = $3, expires_at = $4,
+		accepted_at = $5, revoked_at = $6, updated_at = $7
+	WHERE id = $8
+	`
+
+	_, err := r.writer.Exec(query,
+		invitation.TokenHash,
+		invitation.Status,
+		nullString(invitation.UserID),
+		invitation.ExpiresAt,
+		nullTime(invitation.AcceptedAt),
+		nullTime(invitation.RevokedAt),
+		invitation.UpdatedAt,
+		invitation.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AcceptInvitation marks a pending invitation as accepted. The status is
+// checked by the update itself, so that of two concurrent accepts only the
+// first one changes a row.
+func (r *invitationRepoSqlx) AcceptInvitation(invitation *entities.Invitation) error {
+	query := `
+	UPDATE invitations
+	SET status = $1, user_id = $2, accepted_at = $3, updated_at = $4
+	WHERE id = $5 AND status = $6
+	`
+
+	result, err := r.writer.Exec(query,
+		entities.InvitationAccepted,
+		nullString(invitation.UserID),
+		nullTime(invitation.AcceptedAt),
+		invitation.UpdatedAt,
+		invitation.ID,
+		entities.InvitationPending,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrInvitationNotPending
+	}
+
+	invitation.Status = entities.InvitationAccepted
+
+	return nil
+}
+
+// AddMember links a user to an organization with the given role. Linking a
+// user that is already a member updates the role instead.
+func (r *invitationRepoSqlx) AddMember(member *entities.Membership) error {
+	query := `
+	INSERT INTO organization_members (organization_id, user_id, role, created_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (organization_id, user_id) DO UPDATE SET role = excluded.role
+	`
+
+	_, err := r.writer.Exec(query, member.OrganizationID, member.UserID, member.Role, member.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *invitationRepoSqlx) findInvitation(query string, args ...interface{}) (*entities.Invitation, error) {
+	var (
+		invitation entities.Invitation
+		userID     sql.NullString
+		acceptedAt sql.NullTime
+		revokedAt  sql.NullTime
+	)
+
+	err := r.reader.QueryRow(query, args...).Scan(
+		&invitation.ID,
+		&invitation.OrganizationID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.Status,
+		&invitation.InvitedBy,
+		&userID,
+		&invitation.ExpiresAt,
+		&acceptedAt,
+		&revokedAt,
+		&invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.UserID = userID.String
+	invitation.AcceptedAt = acceptedAt.Time
+	invitation.RevokedAt = revokedAt.Time
+
+	return &invitation, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,223 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupInvitationTestDB(t *testing.T) *sqlx.DB {
+	db := setupTestDB(t)
+
+	// Create invitations and organization_members tables
+	_, err := db.Exec(`
+	CREATE TABLE invitations (
+		id TEXT PRIMARY KEY,
+		organization_id TEXT,
+		email TEXT,
+		role TEXT,
+		token_hash TEXT UNIQUE,
+		status TEXT,
+		invited_by TEXT,
+		user_id TEXT,
+		expires_at TIMESTAMP,
+		accepted_at TIMESTAMP,
+		revoked_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP
+	);
+	CREATE TABLE organization_members (
+		organization_id TEXT,
+		user_id TEXT,
+		role TEXT,
+		created_at TIMESTAMP,
+		PRIMARY KEY (organization_id, user_id)
+	)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create invitations tables: %v", err)
+	}
+
+	return db
+}
+
+func TestCreateInvitation(t *testing.T) {
+	db := setupInvitationTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxInvitationRepository(db, db)
+
+	invitation, token, err := entities.NewInvitation("org-1", "john.lennon@example.com", "user", "admin-1")
+	assert.Nil(t, err)
+
+	err = repo.CreateInvitation(invitation)
+	assert.Nil(t, err)
+
+	found, err := repo.FindInvitationByTokenHash(entities.HashInvitationToken(token))
+	assert.Nil(t, err)
+	assert.NotNil(t, found)
+
+	assert.Equal(t, invitation.ID, found.ID)
+	assert.Equal(t, invitation.OrganizationID, found.OrganizationID)
+	assert.Equal(t, invitation.Email, found.Email)
+	assert.Equal(t, invitation.Role, found.Role)
+	assert.Equal(t, entities.InvitationPending, found.Status)
+	assert.True(t, found.AcceptedAt.IsZero())
+	assert.Empty(t, found.UserID)
+}
+
+func TestFindInvitationById_NotFound(t *testing.T) {
+	db := setupInvitationTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxInvitationRepository(db, db)
+
+	found, err := repo.FindInvitationById("missing")
+	assert.Nil(t, err)
+	assert.Nil(t, found)
+}
+
+func TestUpdateInvitation(t *testing.T) {
+	db := setupInvitationTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxInvitationRepository(db, db)
+
+	invitation, _, err := entities.NewInvitation("org-1", "john.lennon@example.com", "user", "admin-1")
+	assert.Nil(t, err)
+
+	err = repo.CreateInvitation(invitation)
+	assert.Nil(t, err)
+
+	pending, err := repo.FindPendingInvitation("org-1", "john.lennon@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, invitation.ID, pending.ID)
+
+	invitation.Status = entities.InvitationAccepted
+	invitation.UserID = "user-1"
+	invitation.AcceptedAt = time.Now()
+
+	err = repo.UpdateInvitation(invitation)
+	assert.Nil(t, err)
+
+	found, err := repo.FindInvitationById(invitation.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, entities.InvitationAccepted, found.Status)
+	assert.Equal(t, "user-1", found.UserID)
+	assert.False(t, found.AcceptedAt.IsZero())
+
+	pending, err = repo.FindPendingInvitation("org-1", "john.lennon@example.com")
+	assert.Nil(t, err)
+	assert.Nil(t, pending)
+}
+
+func TestAddMember(t *testing.T) {
+	db := setupInvitationTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxInvitationRepository(db, db)
+
+	member := &entities.Membership{
+		OrganizationID: "org-1",
+		UserID:         "user-1",
+		Role:           "user",
+		CreatedAt:      time.Now(),
+	}
+
+	err := repo.AddMember(member)
+	assert.Nil(t, err)
+
+	member.Role = "admin"
+	err = repo.AddMember(member)
+	assert.Nil(t, err)
+
+	var role string
+	err = db.Get(&role, "SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2", "org-1", "user-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", role)
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	db := setupInvitationTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxInvitationRepository(db, db)
+
+	invitation, _, err := entities.NewInvitation("org-1", "john.lennon@example.com", "user", "admin-1")
+	assert.Nil(t, err)
+
+	err = repo.CreateInvitation(invitation)
+	assert.Nil(t, err)
+
+	invitation.UserID = "user-1"
+	invitation.AcceptedAt = time.Now()
+
+	err = repo.AcceptInvitation(invitation)
+	assert.Nil(t, err)
+	assert.Equal(t, entities.InvitationAccepted, invitation.Status)
+
+	found, err := repo.FindInvitationById(invitation.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, entities.InvitationAccepted, found.Status)
+	assert.Equal(t, "user-1", found.UserID)
+
+	// The invitation is consumed once, even by a caller that read it as pending
+	stale := *invitation
+	stale.Status = entities.InvitationPending
+	stale.UserID = "user-2"
+
+	err = repo.AcceptInvitation(&stale)
+	assert.Equal(t, domain.ErrInvitationNotPending, err)
+
+	found, err = repo.FindInvitationById(invitation.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "user-1", found.UserID)
+}
+
+func TestInvitationTransactor(t *testing.T) {
+	db := setupInvitationTestDB(t)
+	defer db.Close()
+
+	// Every connection to :memory: is a new database.
+	db.SetMaxOpenConns(1)
+
+	transactor := repository.NewSqlxInvitationTransactor(db)
+	invitations := repository.NewSqlxInvitationRepository(db, db)
+	users := repository.NewSqlxRepository(db, db)
+
+	invitation, _, err := entities.NewInvitation("org-1", "john.lennon@example.com", "user", "admin-1")
+	assert.Nil(t, err)
+	assert.Nil(t, invitations.CreateInvitation(invitation))
+
+	// An invitation no longer pending rolls back the account and the membership.
+	invitation.Status = entities.InvitationRevoked
+	assert.Nil(t, invitations.UpdateInvitation(invitation))
+
+	err = transactor.WithinInvitationTransaction(func(invitations domain.InvitationRepository, users domain.UserRepository, events domain.UserEventRepository) error {
+		user := &entities.User{ID: "1", FirstName: "John", LastName: "Lennon", Email: "john.lennon@example.com", Role: "user", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		if err := users.CreateUser(user); err != nil {
+			return err
+		}
+
+		if err := invitations.AddMember(&entities.Membership{OrganizationID: "org-1", UserID: "1", Role: "user", CreatedAt: time.Now()}); err != nil {
+			return err
+		}
+
+		invitation.UserID = user.ID
+		return invitations.AcceptInvitation(invitation)
+	})
+	assert.Equal(t, domain.ErrInvitationNotPending, err)
+
+	_, err = users.FindUserById("1")
+	assert.Equal(t, domain.ErrUserNotFound, err)
+
+	var members int
+	assert.Nil(t, db.Get(&members, "SELECT COUNT(*) FROM organization_members"))
+	assert.Equal(t, 0, members)
+}
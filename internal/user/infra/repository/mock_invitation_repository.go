@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) CreateInvitation(invitation *entities.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) FindInvitationById(id string) (*entities.Invitation, error) {
+	args := m.Called(id)
+	return args.Get(0).(*entities.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) FindInvitationByTokenHash(tokenHash string) (*entities.Invitation, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*entities.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) FindPendingInvitation(organizationID string, email string) (*entities.Invitation, error) {
+	args := m.Called(organizationID, email)
+	return args.Get(0).(*entities.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) UpdateInvitation(invitation *entities.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) AcceptInvitation(invitation *entities.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) AddMember(member *entities.Membership) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
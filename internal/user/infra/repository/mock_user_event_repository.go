@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserEventRepository) AppendUserEvent(event *entities.UserEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockUserEventRepository) ListUserEvents(afterSequence int64, limit int) ([]*entities.UserEvent, error) {
+	args := m.Called(afterSequence, limit)
+	return args.Get(0).([]*entities.UserEvent), args.Error(1)
+}
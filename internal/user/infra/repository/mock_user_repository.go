@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"github.com/jonattasmoraes/titan/internal/user/domain"
 	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,17 +25,44 @@ func (m *MockUserRepository) FindUserByEmail(email string) (*entities.User, erro
 	return args.Get(0).(*entities.User), args.Error(1)
 }
 
-func (m *MockUserRepository) ListUsers(page int) ([]*entities.User, error) {
-	args := m.Called(page)
+func (m *MockUserRepository) IsUserDeleted(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) FindUsersByIds(ids []string) ([]*entities.User, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]*entities.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ListUsers(query domain.ListUsersQuery) ([]*entities.User, error) {
+	args := m.Called(query)
 	return args.Get(0).([]*entities.User), args.Error(1)
 }
 
-func (m *MockUserRepository) PatchUser(user *entities.User) error {
+func (m *MockUserRepository) CountUsers(query domain.ListUsersQuery) (int, error) {
+	args := m.Called(query)
+	return args.Int(0), args.Error(1)
+}
+
+// ExportUsers calls fn with each of the users the mock returns, which must
+// be a []*entities.User.
+func (m *MockUserRepository) ExportUsers(query domain.ListUsersQuery, fn func(user *entities.User) error) error {
+	args := m.Called(query)
+	for _, user := range args.Get(0).([]*entities.User) {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUser(user *entities.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) DeleteUser(id string) error {
-	args := m.Called(id)
+func (m *MockUserRepository) DeleteUser(id string, version int64) error {
+	args := m.Called(id, version)
 	return args.Error(0)
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserSearchIndex struct {
+	mock.Mock
+}
+
+func (m *MockUserSearchIndex) SearchUsers(query string, limit int) ([]*domain.UserSearchHit, error) {
+	args := m.Called(query, limit)
+	return args.Get(0).([]*domain.UserSearchHit), args.Error(1)
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserTransactor runs fn with Users and Events. The error given to On is
+// returned when fn succeeds, as a failed commit would be.
+type MockUserTransactor struct {
+	mock.Mock
+	Users  domain.UserRepository
+	Events domain.UserEventRepository
+}
+
+func (m *MockUserTransactor) WithinTransaction(fn func(users domain.UserRepository, events domain.UserEventRepository) error) error {
+	args := m.Called()
+	if err := fn(m.Users, m.Events); err != nil {
+		return err
+	}
+	return args.Error(0)
+}
+
+// MockInvitationTransactor is MockUserTransactor for invitations.
+type MockInvitationTransactor struct {
+	mock.Mock
+	Invitations domain.InvitationRepository
+	Users       domain.UserRepository
+	Events      domain.UserEventRepository
+}
+
+func (m *MockInvitationTransactor) WithinInvitationTransaction(fn func(invitations domain.InvitationRepository, users domain.UserRepository, events domain.UserEventRepository) error) error {
+	args := m.Called()
+	if err := fn(m.Invitations, m.Users, m.Events); err != nil {
+		return err
+	}
+	return args.Error(0)
+}
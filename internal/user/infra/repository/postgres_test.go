@@ -0,0 +1,270 @@
+package repository_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	_ "github.com/lib/pq"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPostgresTestDB migrates a schema of its own with the migrations in
+// schemas, so that the tests run against the tables the service uses. The
+// tests are skipped unless TEST_POSTGRES_DSN points at a Postgres database
+// (make test-postgres).
+func setupPostgresTestDB(t *testing.T) *sqlx.DB {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	admin, err := sqlx.Connect("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close() })
+
+	schema := "titan_test_" + strings.ToLower(ulid.Make().String())
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	// Every connection of the pool starts in the schema.
+	separator := " "
+	if strings.Contains(dsn, "://") {
+		separator = "&"
+		if !strings.Contains(dsn, "?") {
+			separator = "?"
+		}
+	}
+	db, err := sqlx.Connect("postgres", dsn+separator+"search_path="+schema+",public")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := filepath.Glob(filepath.Join("..", "..", "..", "..", "schemas", "*.up.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	sort.Strings(migrations)
+
+	for _, migration := range migrations {
+		statements, err := os.ReadFile(migration)
+		require.NoError(t, err)
+
+		_, err = db.Exec(string(statements))
+		require.NoError(t, err, "migration %s", filepath.Base(migration))
+	}
+
+	return db
+}
+
+func newPostgresTestUser(name string) *entities.User {
+	return &entities.User{
+		ID:        ulid.Make().String(),
+		FirstName: name,
+		LastName:  "Lennon",
+		Email:     strings.ToLower(name) + "@example.com",
+		Password:  "password",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Version:   1,
+	}
+}
+
+func TestPostgresUserTransactor(t *testing.T) {
+	db := setupPostgresTestDB(t)
+
+	transactor := repository.NewSqlxUserTransactor(db)
+	users := repository.NewSqlxRepository(db, db)
+	events := repository.NewSqlxUserEventRepository(db, db)
+
+	// A failure rolls back the user and its event.
+	failure := errors.New("failure")
+	john := newPostgresTestUser("John")
+	err := transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+		require.NoError(t, users.CreateUser(john))
+		require.NoError(t, events.AppendUserEvent(entities.NewUserEvent(entities.UserCreated, john)))
+		return failure
+	})
+	assert.Equal(t, failure, err)
+
+	_, err = users.FindUserById(john.ID)
+	assert.Equal(t, domain.ErrUserNotFound, err)
+
+	logged, err := events.ListUserEvents(0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, logged)
+
+	// Success commits both.
+	paul := newPostgresTestUser("Paul")
+	err = transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+		if err := users.CreateUser(paul); err != nil {
+			return err
+		}
+		return events.AppendUserEvent(entities.NewUserEvent(entities.UserCreated, paul))
+	})
+	require.NoError(t, err)
+
+	found, err := users.FindUserById(paul.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Paul", found.FirstName)
+
+	logged, err = events.ListUserEvents(0, 10)
+	require.NoError(t, err)
+	require.Len(t, logged, 1)
+	assert.Equal(t, paul.ID, logged[0].User.ID)
+	assert.Equal(t, entities.UserCreated, logged[0].Type)
+}
+
+// TestPostgresUserEvents_CommitOrder verifies that an event never becomes
+// visible after one with a greater sequence, which would make a reader that
+// follows the log by sequence skip it.
+func TestPostgresUserEvents_CommitOrder(t *testing.T) {
+	db := setupPostgresTestDB(t)
+
+	transactor := repository.NewSqlxUserTransactor(db)
+	events := repository.NewSqlxUserEventRepository(db, db)
+
+	appended := make(chan struct{})
+	release := make(chan struct{})
+
+	var (
+		wg    sync.WaitGroup
+		first *entities.UserEvent
+		later *entities.UserEvent
+	)
+
+	// The first transaction appends its event and stays open.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		err := transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+			first = entities.NewUserEvent(entities.UserCreated, newPostgresTestUser("John"))
+			if err := events.AppendUserEvent(first); err != nil {
+				return err
+			}
+
+			close(appended)
+			<-release
+			return nil
+		})
+		assert.NoError(t, err)
+	}()
+
+	<-appended
+
+	// An append outside of it waits for it to end.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		later = entities.NewUserEvent(entities.UserCreated, newPostgresTestUser("Paul"))
+		assert.NoError(t, events.AppendUserEvent(later))
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("the append did not wait for the open transaction")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Nothing is visible yet, so a reader cannot move past the first event.
+	logged, err := events.ListUserEvents(0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, logged)
+
+	close(release)
+	wg.Wait()
+	<-done
+
+	assert.Greater(t, later.Sequence, first.Sequence)
+
+	logged, err = events.ListUserEvents(0, 10)
+	require.NoError(t, err)
+	require.Len(t, logged, 2)
+	assert.Equal(t, []int64{first.Sequence, later.Sequence}, []int64{logged[0].Sequence, logged[1].Sequence})
+}
+
+func TestPostgresUserEvents_Append(t *testing.T) {
+	db := setupPostgresTestDB(t)
+
+	events := repository.NewSqlxUserEventRepository(db, db)
+	user := newPostgresTestUser("John")
+
+	for i := 0; i < 3; i++ {
+		event := entities.NewUserEvent(entities.UserUpdated, user)
+		require.NoError(t, events.AppendUserEvent(event), "append %d", i)
+		assert.NotZero(t, event.Sequence)
+	}
+
+	logged, err := events.ListUserEvents(0, 2)
+	require.NoError(t, err)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "John", logged[0].User.FirstName)
+	assert.Empty(t, logged[0].User.Password)
+
+	rest, err := events.ListUserEvents(logged[1].Sequence, 10)
+	require.NoError(t, err)
+	assert.Len(t, rest, 1)
+}
+
+func TestPostgresUserSearchIndex(t *testing.T) {
+	db := setupPostgresTestDB(t)
+
+	users := repository.NewSqlxRepository(db, db)
+	index := repository.NewPostgresUserSearchIndex(db)
+
+	john := newPostgresTestUser("John")
+	johnny := newPostgresTestUser("Johnny")
+	johnny.LastName = "Cash"
+	ringo := newPostgresTestUser("Ringo")
+	ringo.Email = "ringo<b>@example.com"
+	for _, user := range []*entities.User{john, johnny, ringo} {
+		require.NoError(t, users.CreateUser(user))
+	}
+
+	searchIds := func(query string) []string {
+		hits, err := index.SearchUsers(query, 10)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, hit := range hits {
+			ids = append(ids, hit.User.ID)
+		}
+		return ids
+	}
+
+	// Partial words match by prefix, in any field.
+	assert.ElementsMatch(t, []string{john.ID, johnny.ID}, searchIds("joh"))
+
+	// Every term has to match for the full-text rank; the best match comes first.
+	ids := searchIds("johnny cash")
+	require.NotEmpty(t, ids)
+	assert.Equal(t, johnny.ID, ids[0])
+
+	// Misspellings match through the trigram similarity.
+	assert.Contains(t, searchIds("ringp"), ringo.ID)
+
+	hits, err := index.SearchUsers("ringo", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Greater(t, hits[0].Rank, 0.0)
+	assert.Equal(t, "<mark>ringo</mark>&lt;b&gt;@example.com", hits[0].Highlights["email"])
+
+	// Deleted users are left out.
+	require.NoError(t, users.DeleteUser(john.ID, 0))
+	assert.Equal(t, []string{johnny.ID}, searchIds("john"))
+
+	assert.Empty(t, searchIds("paul"))
+}
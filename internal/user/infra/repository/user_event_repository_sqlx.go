@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+type userEventRepoSqlx struct {
+	writer sqlxConn
+	reader sqlxConn
+}
+
+func NewSqlxUserEventRepository(writer, reader *sqlx.DB) domain.UserEventRepository {
+	return &userEventRepoSqlx{writer: writer, reader: reader}
+}
+
+// userEventPayload is the JSON snapshot of the user stored with each event.
+type userEventPayload struct {
+	ID        string    `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Region    string    `json:"region,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// userEventsLock is the key of the advisory lock that serializes appends to
+// the change log on Postgres.
+const userEventsLock = 7_301_029
+
+// AppendUserEvent stores an event in the change log and sets its sequence.
+//
+// Readers follow the log by sequence, so an event must never become visible
+// after one with a greater sequence. A BIGSERIAL alone does not guarantee it:
+// two transactions can take sequences 1 and 2 and commit 2 first, and a
+// reader that saw 2 would never read 1. On Postgres, each append therefore
+// takes a transaction-level advisory lock before its sequence, held until
+// the transaction of the append ends, so sequences are committed in order.
+// SQLite runs one write transaction at a time already.
+//
+// The queries rely on RETURNING, which both Postgres and SQLite support.
+func (r *userEventRepoSqlx) AppendUserEvent(event *entities.UserEvent) error {
+	payload, err := json.Marshal(userEventPayload{
+		ID:        event.User.ID,
+		FirstName: event.User.FirstName,
+		LastName:  event.User.LastName,
+		Email:     event.User.Email,
+		Role:      event.User.Role,
+		Region:    event.User.Region,
+		CreatedAt: event.User.CreatedAt,
+		UpdatedAt: event.User.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.writer.DriverName() == "postgres" {
+		// One statement, so that the lock is held until the insert commits
+		// even when the writer is not in a transaction.
+		query := `
+		WITH lock AS (SELECT pg_advisory_xact_lock($5))
+		INSERT INTO user_events (event_type, user_id, payload, occurred_at)
+		SELECT $1::varchar, $2::varchar, $3::text, $4::timestamp FROM lock
+		RETURNING sequence
+		`
+
+		return r.writer.QueryRow(query, event.Type, event.User.ID, string(payload), event.OccurredAt, userEventsLock).Scan(&event.Sequence)
+	}
+
+	query := `
+	INSERT INTO user_events (event_type, user_id, payload, occurred_at)
+	VALUES ($1, $2, $3, $4)
+	RETURNING sequence
+	`
+
+	return r.writer.QueryRow(query, event.Type, event.User.ID, string(payload), event.OccurredAt).Scan(&event.Sequence)
+}
+
+// ListUserEvents retrieves up to limit events recorded after afterSequence,
+// oldest first.
+func (r *userEventRepoSqlx) ListUserEvents(afterSequence int64, limit int) ([]*entities.UserEvent, error) {
+	query := `
+	SELECT sequence, event_type, payload, occurred_at
+	FROM user_events
+	WHERE sequence > $1
+	ORDER BY sequence
+	LIMIT $2
+	`
+
+	rows, err := r.reader.Query(query, afterSequence, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entities.UserEvent
+
+	for rows.Next() {
+		var (
+			event   entities.UserEvent
+			payload string
+		)
+
+		err := rows.Scan(&event.Sequence, &event.Type, &payload, &event.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		var snapshot userEventPayload
+		if err := json.Unmarshal([]byte(payload), &snapshot); err != nil {
+			return nil, err
+		}
+
+		event.User = entities.User{
+			ID:        snapshot.ID,
+			FirstName: snapshot.FirstName,
+			LastName:  snapshot.LastName,
+			Email:     snapshot.Email,
+			Role:      snapshot.Role,
+			Region:    snapshot.Region,
+			CreatedAt: snapshot.CreatedAt,
+			UpdatedAt: snapshot.UpdatedAt,
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,73 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupUserEventTestDB(t *testing.T) *sqlx.DB {
+	db := setupTestDB(t)
+
+	// Create user_events table
+	_, err := db.Exec(`
+	CREATE TABLE user_events (
+		sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_type TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		occurred_at TIMESTAMP NOT NULL
+	)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create user_events table: %v", err)
+	}
+
+	return db
+}
+
+func TestAppendAndListUserEvents(t *testing.T) {
+	db := setupUserEventTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxUserEventRepository(db, db)
+
+	user := &entities.User{
+		ID:        "1",
+		FirstName: "John",
+		LastName:  "Lennon",
+		Email:     "john.lennon@example.com",
+		Password:  "password",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	created := entities.NewUserEvent(entities.UserCreated, user)
+	err := repo.AppendUserEvent(created)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), created.Sequence)
+
+	deleted := entities.NewUserEvent(entities.UserDeleted, user)
+	err = repo.AppendUserEvent(deleted)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), deleted.Sequence)
+
+	events, err := repo.ListUserEvents(0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, entities.UserCreated, events[0].Type)
+	assert.Equal(t, user.Email, events[0].User.Email)
+	assert.Empty(t, events[0].User.Password)
+
+	// Resuming after the first event returns only the second one
+	events, err = repo.ListUserEvents(created.Sequence, 10)
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, entities.UserDeleted, events[0].Type)
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+// UserLoader wraps a UserRepository and coalesces concurrent FindUserById
+// calls: lookups arriving within the wait window are answered by a single
+// FindUsersByIds query. Every other method goes straight to the wrapped
+// repository.
+type UserLoader struct {
+	domain.UserRepository
+
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	batch *userBatch
+}
+
+type userBatch struct {
+	ids   []string
+	once  sync.Once
+	done  chan struct{}
+	users map[string]*entities.User
+	err   error
+}
+
+// NewUserLoader returns a loader that waits up to wait for more lookups before
+// querying, or less when maxBatch IDs are already queued.
+func NewUserLoader(repo domain.UserRepository, wait time.Duration, maxBatch int) *UserLoader {
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+
+	return &UserLoader{UserRepository: repo, wait: wait, maxBatch: maxBatch}
+}
+
+// FindUserById joins the pending batch, or starts a new one, and waits for it
+// to be loaded. Each caller gets its own copy of the user.
+func (l *UserLoader) FindUserById(id string) (*entities.User, error) {
+	l.mu.Lock()
+
+	batch := l.batch
+	if batch == nil {
+		batch = &userBatch{done: make(chan struct{})}
+		l.batch = batch
+		time.AfterFunc(l.wait, func() { l.dispatch(batch) })
+	}
+
+	batch.ids = append(batch.ids, id)
+	full := len(batch.ids) >= l.maxBatch
+	if full {
+		l.batch = nil
+	}
+
+	l.mu.Unlock()
+
+	if full {
+		go l.dispatch(batch)
+	}
+
+	<-batch.done
+
+	if batch.err != nil {
+		return nil, batch.err
+	}
+
+	user, ok := batch.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+
+	found := *user
+	return &found, nil
+}
+
+func (l *UserLoader) dispatch(batch *userBatch) {
+	batch.once.Do(func() {
+		l.mu.Lock()
+		if l.batch == batch {
+			l.batch = nil
+		}
+		ids := unique(batch.ids)
+		l.mu.Unlock()
+
+		users, err := l.UserRepository.FindUsersByIds(ids)
+
+		batch.users = make(map[string]*entities.User, len(users))
+		for _, user := range users {
+			batch.users[user.ID] = user
+		}
+		batch.err = err
+
+		close(batch.done)
+	})
+}
+
+func unique(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	var result []string
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
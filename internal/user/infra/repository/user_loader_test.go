@@ -0,0 +1,60 @@
+package repository_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserLoader_CoalescesConcurrentLookups(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockRepo.On("FindUsersByIds", mock.Anything).Return([]*entities.User{
+		{ID: "1", FirstName: "John"},
+		{ID: "2", FirstName: "Paul"},
+	}, nil).Once()
+
+	loader := repository.NewUserLoader(mockRepo, 20*time.Millisecond, 100)
+
+	ids := []string{"1", "2", "1", "3"}
+	users := make([]*entities.User, len(ids))
+	errs := make([]error, len(ids))
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			users[i], errs[i] = loader.FindUserById(id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	mockRepo.AssertNumberOfCalls(t, "FindUsersByIds", 1)
+	requested := mockRepo.Calls[0].Arguments.Get(0).([]string)
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, requested)
+
+	assert.Equal(t, "John", users[0].FirstName)
+	assert.Equal(t, "Paul", users[1].FirstName)
+	assert.Equal(t, "John", users[2].FirstName)
+	assert.NotSame(t, users[0], users[2])
+	assert.Equal(t, domain.ErrUserNotFound, errs[3])
+}
+
+func TestUserLoader_DispatchesFullBatchRightAway(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockRepo.On("FindUsersByIds", []string{"1"}).Return([]*entities.User{{ID: "1"}}, nil)
+
+	// A window far longer than the test proves the full batch did not wait.
+	loader := repository.NewUserLoader(mockRepo, time.Hour, 1)
+
+	user, err := loader.FindUserById("1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", user.ID)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+)
+
+// userQueryWhere turns the filter of query, and its cursor when withCursor is
+// set, into a WHERE clause with numbered parameters. Parameters are numbered
+// in the order they first appear, which both Postgres and SQLite need.
+func userQueryWhere(query domain.ListUsersQuery, withCursor bool) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	filter := query.Filter
+
+	switch filter.Deleted {
+	case domain.ExcludeDeleted:
+		conditions = append(conditions, "deleted_at IS NULL")
+	case domain.OnlyDeleted:
+		conditions = append(conditions, "deleted_at IS NOT NULL")
+	}
+
+	if filter.Role != "" {
+		conditions = append(conditions, "role = "+param(filter.Role))
+	}
+
+	if filter.EmailDomain != "" {
+		conditions = append(conditions, "LOWER(email) LIKE "+param("%@"+strings.ToLower(filter.EmailDomain)))
+	}
+
+	ranges := []struct {
+		column   string
+		operator string
+		value    time.Time
+	}{
+		{"created_at", ">=", filter.CreatedAfter},
+		{"created_at", "<", filter.CreatedBefore},
+		{"updated_at", ">=", filter.UpdatedAfter},
+		{"updated_at", "<", filter.UpdatedBefore},
+	}
+	for _, r := range ranges {
+		if !r.value.IsZero() {
+			conditions = append(conditions, r.column+" "+r.operator+" "+param(r.value))
+		}
+	}
+
+	if withCursor && query.After != nil {
+		column, direction := userQueryOrder(query)
+
+		operator := ">"
+		if direction == "DESC" {
+			operator = "<"
+		}
+
+		if column == "id" {
+			conditions = append(conditions, "id "+operator+" "+param(query.After.ID))
+		} else {
+			var value interface{} = query.After.Value
+			if query.SortBy.IsTime() {
+				t, err := time.Parse(time.RFC3339Nano, query.After.Value)
+				if err != nil {
+					return "", nil, err
+				}
+				value = t
+			}
+
+			v := param(value)
+			conditions = append(conditions,
+				"("+column+" "+operator+" "+v+" OR ("+column+" = "+v+" AND id "+operator+" "+param(query.After.ID)+"))")
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", args, nil
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// userQueryOrderBy returns the ORDER BY clause of query. Ties on the sort
+// field are broken by ID.
+func userQueryOrderBy(query domain.ListUsersQuery) string {
+	column, direction := userQueryOrder(query)
+	if column == "id" {
+		return " ORDER BY id " + direction
+	}
+
+	return " ORDER BY " + column + " " + direction + ", id " + direction
+}
+
+// userQueryOrder returns the expression and direction to sort by. The column
+// is one of domain.UserSortFields, never user input. Names and emails may be
+// NULL, which no comparison of the cursor matches and each database orders
+// differently, so they sort as the empty string, which is also the value
+// cursors hold for them.
+func userQueryOrder(query domain.ListUsersQuery) (string, string) {
+	column := "id"
+	if query.SortBy != "" && query.SortBy.IsValid() {
+		column = string(query.SortBy)
+	}
+
+	if column != "id" && !query.SortBy.IsTime() {
+		column = "COALESCE(" + column + ", '')"
+	}
+
+	if query.Descending {
+		return column, "DESC"
+	}
+
+	return column, "ASC"
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserQueryOrderBy(t *testing.T) {
+	tests := []struct {
+		query domain.ListUsersQuery
+		want  string
+	}{
+		{domain.ListUsersQuery{}, " ORDER BY id ASC"},
+		{domain.ListUsersQuery{Descending: true}, " ORDER BY id DESC"},
+		{domain.ListUsersQuery{SortBy: domain.SortByCreatedAt}, " ORDER BY created_at ASC, id ASC"},
+		{domain.ListUsersQuery{SortBy: domain.SortByEmail, Descending: true}, " ORDER BY COALESCE(email, '') DESC, id DESC"},
+		{domain.ListUsersQuery{SortBy: domain.SortByLastName}, " ORDER BY COALESCE(last_name, '') ASC, id ASC"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, userQueryOrderBy(tt.query), "sort %q", tt.query.SortBy)
+	}
+}
+
+func TestUserQueryWhere_Cursor(t *testing.T) {
+	where, args, err := userQueryWhere(domain.ListUsersQuery{
+		SortBy: domain.SortByEmail,
+		After:  &domain.UserCursor{Value: "", ID: "2"},
+	}, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, " WHERE deleted_at IS NULL AND (COALESCE(email, '') > $1 OR (COALESCE(email, '') = $1 AND id > $2))", where)
+	assert.Equal(t, []interface{}{"", "2"}, args)
+
+	// The cursor is left out of counts and exports.
+	where, args, err = userQueryWhere(domain.ListUsersQuery{After: &domain.UserCursor{ID: "2"}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, " WHERE deleted_at IS NULL", where)
+	assert.Empty(t, args)
+}
+
+// TestUserQuery_KeysetOverNulls pages through users sorted by a column that
+// holds NULLs, one user per page, and expects to see every user once.
+func TestUserQuery_KeysetOverNulls(t *testing.T) {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+	CREATE TABLE users (id TEXT PRIMARY KEY, email TEXT, deleted_at TIMESTAMP);
+	INSERT INTO users (id, email) VALUES ('1', 'b@example.com'), ('2', NULL), ('3', 'a@example.com'), ('4', NULL);
+	`)
+	require.NoError(t, err)
+
+	emails := map[string]string{"1": "b@example.com", "3": "a@example.com"}
+
+	for _, descending := range []bool{false, true} {
+		query := domain.ListUsersQuery{SortBy: domain.SortByEmail, Descending: descending}
+
+		var seen []string
+		for page := 0; page < 10; page++ {
+			where, args, err := userQueryWhere(query, true)
+			require.NoError(t, err)
+
+			var id string
+			err = db.QueryRow("SELECT id FROM users"+where+userQueryOrderBy(query)+" LIMIT 1", args...).Scan(&id)
+			if err != nil {
+				break
+			}
+
+			seen = append(seen, id)
+			query.After = &domain.UserCursor{Value: emails[id], ID: id}
+		}
+
+		if descending {
+			assert.Equal(t, []string{"1", "3", "4", "2"}, seen)
+		} else {
+			assert.Equal(t, []string{"2", "4", "3", "1"}, seen)
+		}
+	}
+}
+
+func TestPostgresSearchStatement(t *testing.T) {
+	statement, args := postgresSearchStatement(domain.SearchTerms("John LEN@exam"), 20)
+
+	assert.Equal(t, postgresUserSearchStatement, statement)
+	assert.Equal(t, []interface{}{"john:* & len:* & exam:*", "john len exam", 20}, args)
+	assert.Contains(t, statement, "to_tsquery('simple', $1)")
+	assert.Contains(t, statement, "$2 <% search_text")
+	assert.Contains(t, statement, "deleted_at IS NULL")
+	assert.Contains(t, statement, "LIMIT $3")
+}
+
+func TestSqliteSearchStatement(t *testing.T) {
+	statement, args := sqliteSearchStatement(domain.SearchTerms("John LEN@exam"), 20)
+
+	assert.Equal(t, sqliteUserSearchStatement, statement)
+	assert.Equal(t, []interface{}{`"john"* "len"* "exam"*`, 20}, args)
+	assert.Contains(t, statement, "users_fts MATCH $1")
+	assert.Contains(t, statement, "u.deleted_at IS NULL")
+	assert.Contains(t, statement, "LIMIT $2")
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
 	"strconv"
 	"strings"
@@ -9,11 +10,12 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/jonattasmoraes/titan/internal/user/domain"
 	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/lib/pq"
 )
 
 type repoSqlx struct {
-	writer *sqlx.DB
-	reader *sqlx.DB
+	writer sqlxConn
+	reader sqlxConn
 }
 
 func NewSqlxRepository(writer, reader *sqlx.DB) domain.UserRepository {
@@ -28,15 +30,17 @@ func NewSqlxRepository(writer, reader *sqlx.DB) domain.UserRepository {
 // - error: an error if the insertion operation fails, otherwise nil.
 func (r *repoSqlx) CreateUser(user *entities.User) error {
 	query := `
-	INSERT INTO users (id, first_name, last_name, email, password, role, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	INSERT INTO users (id, first_name, last_name, email, password, role, region, created_at, updated_at, version)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 1)
 	`
 
-	_, err := r.writer.Exec(query, user.ID, user.FirstName, user.LastName, user.Email, user.Password, user.Role, user.CreatedAt, user.UpdatedAt)
+	_, err := r.writer.Exec(query, user.ID, user.FirstName, nullString(user.LastName), user.Email, user.Password, user.Role, nullString(user.Region), user.CreatedAt, user.UpdatedAt)
 	if err != nil {
 		return err
 	}
 
+	user.Version = 1
+
 	return nil
 }
 
@@ -47,7 +51,7 @@ func (r *repoSqlx) CreateUser(user *entities.User) error {
 // or an error if the retrieval operation fails.
 func (r *repoSqlx) FindUserById(id string) (*entities.User, error) {
 	query := `
-	SELECT id, first_name, last_name, email, password, role, created_at, updated_at
+	SELECT id, first_name, last_name, email, password, role, region, created_at, updated_at, version
 	FROM users
 	WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -59,27 +63,33 @@ func (r *repoSqlx) FindUserById(id string) (*entities.User, error) {
 	defer rows.Close()
 
 	var user entities.User
+	var lastName, region sql.NullString
 	found := false
 
 	for rows.Next() {
 		err := rows.Scan(
 			&user.ID,
 			&user.FirstName,
-			&user.LastName,
+			&lastName,
 			&user.Email,
 			&user.Password,
 			&user.Role,
+			&region,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.Version,
 		)
 		if err != nil {
 			return nil, err
 		}
+
+		user.LastName = lastName.String
+		user.Region = region.String
 		found = true
 	}
 
 	if !found {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 
 	return &user, nil
@@ -94,7 +104,7 @@ func (r *repoSqlx) FindUserById(id string) (*entities.User, error) {
 // - error: an error if the retrieval operation fails, otherwise nil.
 func (r *repoSqlx) FindUserByEmail(email string) (*entities.User, error) {
 	query := `
-	SELECT id, first_name, last_name, email, password, role, created_at, updated_at
+	SELECT id, first_name, last_name, email, password, role, region, created_at, updated_at, version
 	FROM users
 	WHERE email = $1 AND deleted_at IS NULL
 	`
@@ -106,44 +116,95 @@ func (r *repoSqlx) FindUserByEmail(email string) (*entities.User, error) {
 	defer rows.Close()
 
 	var user entities.User
+	var lastName, region sql.NullString
 
 	for rows.Next() {
 		err := rows.Scan(
 			&user.ID,
 			&user.FirstName,
-			&user.LastName,
+			&lastName,
 			&user.Email,
 			&user.Password,
 			&user.Role,
+			&region,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.Version,
 		)
 		if err != nil {
 			return nil, err
 		}
+
+		user.LastName = lastName.String
+		user.Region = region.String
 	}
 
 	return &user, nil
 }
 
-// ListUsers retrieves a list of users from the database based on the provided page number.
+// IsUserDeleted reports whether id belongs to a deleted user, whose row
+// still holds the ID.
 //
 // Parameters:
-// - page: an integer representing the page number for pagination.
+// - id: a string representing the ID to look up.
 // Returns:
-// - []*entities.User: a slice of User entities representing the retrieved users.
+// - bool: true when a deleted user has the ID.
 // - error: an error if the retrieval operation fails, otherwise nil.
-func (r *repoSqlx) ListUsers(page int) ([]*entities.User, error) {
-	offset := (page - 1) * 10
-
+func (r *repoSqlx) IsUserDeleted(id string) (bool, error) {
 	query := `
-	SELECT id, first_name, last_name, email, role, created_at, updated_at
+	SELECT COUNT(*)
 	FROM users
-	WHERE deleted_at IS NULL
-	LIMIT 10 OFFSET $1
+	WHERE id = $1 AND deleted_at IS NOT NULL
 	`
 
-	rows, err := r.writer.Query(query, offset)
+	var count int
+	if err := r.reader.QueryRow(query, id).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// FindUsersByIds retrieves every active user whose ID is in ids with a single
+// query. Postgres receives the IDs as one array parameter; other drivers, such
+// as the SQLite store used in tests, get an IN list instead.
+//
+// Parameters:
+// - ids: the IDs to look up. IDs without a match are left out of the result.
+// Returns:
+// - []*entities.User: the users found, in no particular order.
+// - error: an error if the retrieval operation fails, otherwise nil.
+func (r *repoSqlx) FindUsersByIds(ids []string) ([]*entities.User, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var (
+		query strings.Builder
+		args  []interface{}
+	)
+
+	query.WriteString(`
+	SELECT id, first_name, last_name, email, password, role, region, created_at, updated_at, version
+	FROM users
+	WHERE deleted_at IS NULL AND `)
+
+	if r.reader.DriverName() == "postgres" {
+		query.WriteString("id = ANY($1)")
+		args = append(args, pq.Array(ids))
+	} else {
+		query.WriteString("id IN (")
+		for i, id := range ids {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString("$" + strconv.Itoa(i+1))
+			args = append(args, id)
+		}
+		query.WriteString(")")
+	}
+
+	rows, err := r.reader.Query(query.String(), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -153,92 +214,235 @@ func (r *repoSqlx) ListUsers(page int) ([]*entities.User, error) {
 
 	for rows.Next() {
 		var user entities.User
+		var lastName, region sql.NullString
 		err := rows.Scan(
 			&user.ID,
 			&user.FirstName,
-			&user.LastName,
+			&lastName,
 			&user.Email,
+			&user.Password,
 			&user.Role,
+			&region,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.Version,
 		)
 		if err != nil {
 			return nil, err
 		}
 
+		user.LastName = lastName.String
+		user.Region = region.String
+
 		users = append(users, &user)
 	}
 
-	return users, nil
+	return users, rows.Err()
 }
 
-// PatchUser updates the specified user in the database with the provided fields.
+// ListUsers retrieves one page of the users matched by query.
 //
 // Parameters:
-// - user: a pointer to an entities.User struct representing the user to be updated.
-//
+// - query: the filter, the sort order and the page to read, either after a
+// cursor (keyset) or at an offset.
 // Returns:
-// - error: an error if the update operation fails, otherwise nil.
-func (r *repoSqlx) PatchUser(user *entities.User) error {
-	var (
-		query strings.Builder
-		args  []interface{}
-	)
+// - []*entities.User: a slice of User entities representing the retrieved users.
+// - error: an error if the retrieval operation fails, otherwise nil.
+func (r *repoSqlx) ListUsers(query domain.ListUsersQuery) ([]*entities.User, error) {
+	where, args, err := userQueryWhere(query, true)
+	if err != nil {
+		return nil, err
+	}
 
-	query.WriteString("UPDATE users SET ")
+	statement := listUsersStatement + where + userQueryOrderBy(query)
 
-	argIndex := 1
+	args = append(args, query.Limit)
+	statement += " LIMIT $" + strconv.Itoa(len(args))
 
-	if user.FirstName != "" {
-		query.WriteString("first_name = $" + strconv.Itoa(argIndex) + ", ")
-		args = append(args, user.FirstName)
-		argIndex++
+	if query.Offset > 0 {
+		args = append(args, query.Offset)
+		statement += " OFFSET $" + strconv.Itoa(len(args))
 	}
 
-	if user.LastName != "" {
-		query.WriteString("last_name = $" + strconv.Itoa(argIndex) + ", ")
-		args = append(args, user.LastName)
-		argIndex++
+	rows, err := r.reader.Query(statement, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
+
+	var users []*entities.User
 
-	if user.Email != "" {
-		query.WriteString("email = $" + strconv.Itoa(argIndex) + ", ")
-		args = append(args, user.Email)
-		argIndex++
+	for rows.Next() {
+		user, err := scanListedUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
 	}
 
-	updateTime := time.Now()
-	query.WriteString("updated_at = $" + strconv.Itoa(argIndex))
-	args = append(args, updateTime)
-	argIndex++
+	return users, rows.Err()
+}
 
-	query.WriteString(" WHERE id = $" + strconv.Itoa(argIndex) + " AND deleted_at IS NULL")
-	args = append(args, user.ID)
+// ExportUsers reads every user matched by the filter of query, in its order,
+// through a single cursor, so that memory use does not grow with the number
+// of users.
+//
+// Parameters:
+// - query: the filter and the sort order. Its cursor, offset and limit are
+// ignored.
+// - fn: called with each user; the first error it returns stops the export.
+// Returns:
+// - error: the error of the query or of fn, otherwise nil.
+func (r *repoSqlx) ExportUsers(query domain.ListUsersQuery, fn func(user *entities.User) error) error {
+	where, args, err := userQueryWhere(query, false)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.writer.Exec(query.String(), args...)
+	rows, err := r.reader.Query(listUsersStatement+where+userQueryOrderBy(query), args...)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanListedUser(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	return rows.Err()
+}
+
+const listUsersStatement = `
+	SELECT id, first_name, last_name, email, role, region, created_at, updated_at, deleted_at, version
+	FROM users`
+
+// scanListedUser scans a row of listUsersStatement.
+func scanListedUser(rows *sql.Rows) (*entities.User, error) {
+	var user entities.User
+	var lastName, region sql.NullString
+	var deletedAt sql.NullTime
+	err := rows.Scan(
+		&user.ID,
+		&user.FirstName,
+		&lastName,
+		&user.Email,
+		&user.Role,
+		&region,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&deletedAt,
+		&user.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.LastName = lastName.String
+	user.Region = region.String
+
+	if deletedAt.Valid {
+		user.DeletedAt = deletedAt.Time
+	}
+
+	return &user, nil
+}
+
+// CountUsers returns the number of users matched by the filter of query,
+// regardless of the page it describes.
+func (r *repoSqlx) CountUsers(query domain.ListUsersQuery) (int, error) {
+	where, args, err := userQueryWhere(query, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+
+	err = r.reader.Get(&count, `SELECT COUNT(*) FROM users`+where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// UpdateUser overwrites the mutable fields of the user with the given values,
+// the role included. Empty values are written as well, an empty last name or
+// region as NULL.
+//
+// Parameters:
+// - user: a pointer to an entities.User struct holding the complete new state.
+//
+// Returns:
+// - error: an error if the update operation fails, otherwise nil.
+func (r *repoSqlx) UpdateUser(user *entities.User) error {
+	query := `
+	UPDATE users
+	SET first_name = $1, last_name = $2, email = $3, role = $4, region = $5, updated_at = $6, version = version + 1
+	WHERE id = $7 AND deleted_at IS NULL`
+	args := []interface{}{user.FirstName, nullString(user.LastName), user.Email, user.Role, nullString(user.Region), user.UpdatedAt, user.ID}
+
+	if user.Version != 0 {
+		query += " AND version = $8"
+		args = append(args, user.Version)
+	}
+
+	return r.writeVersioned(user, query+" RETURNING version", args...)
+}
+
+// writeVersioned runs an UPDATE that returns the new version of user and
+// stores it in user. No row means the user was deleted or, when a version
+// was expected, that it changed since it was read.
+//
+// The query relies on RETURNING, which both Postgres and SQLite support.
+func (r *repoSqlx) writeVersioned(user *entities.User, query string, args ...interface{}) error {
+	err := r.writer.QueryRow(query, args...).Scan(&user.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		if user.Version != 0 {
+			return domain.ErrVersionMismatch
+		}
+		return nil
+	}
+
+	return err
 }
 
 // DeleteUser apllies a date to a column teleted_at in the database.
 //
 // It takes in a single parameter, `id`, which is the ID of the user to be deleted.
 // The function returns an error if there was a problem executing the database query.
-func (r *repoSqlx) DeleteUser(id string) error {
+//
+// When version is not 0, the user is only deleted at that version.
+func (r *repoSqlx) DeleteUser(id string, version int64) error {
 	deletedTime := time.Now()
 	query := `
 	UPDATE users
-	SET deleted_at = $1
-	WHERE id = $2
-	`
+	SET deleted_at = $1, version = version + 1
+	WHERE id = $2`
+	args := []interface{}{deletedTime, id}
+
+	if version != 0 {
+		query += " AND version = $3"
+		args = append(args, version)
+	}
 
-	_, err := r.writer.Exec(query, deletedTime, id)
+	result, err := r.writer.Exec(query, args...)
 	if err != nil {
 		return err
 	}
 
+	if version != 0 {
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			return domain.ErrVersionMismatch
+		}
+	}
+
 	return nil
 }
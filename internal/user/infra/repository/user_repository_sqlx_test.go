@@ -1,10 +1,13 @@
 package repository_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
 	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
 	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
 	_ "github.com/mattn/go-sqlite3"
@@ -27,9 +30,11 @@ func setupTestDB(t *testing.T) *sqlx.DB {
 		email TEXT,
 		password TEXT,
 		role TEXT,
+		region TEXT,
 		created_at TIMESTAMP,
 		updated_at TIMESTAMP,
-		deleted_at TIMESTAMP
+		deleted_at TIMESTAMP,
+		version INTEGER NOT NULL DEFAULT 1
 	)
 	`)
 	if err != nil {
@@ -134,7 +139,225 @@ func TestFindUserByEmail(t *testing.T) {
 	assert.Equal(t, user.Email, foundUser.Email)
 }
 
-func TestPatchUser(t *testing.T) {
+func TestFindUsersByIds(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxRepository(db, db)
+
+	var ids []string
+	for _, name := range []string{"John", "Paul", "George"} {
+		user := &entities.User{
+			ID:        ulid.Make().String(),
+			FirstName: name,
+			LastName:  "Beatle",
+			Email:     name + "@example.com",
+			Password:  "password",
+			Role:      "user",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		err := repo.CreateUser(user)
+		assert.Nil(t, err)
+
+		ids = append(ids, user.ID)
+	}
+
+	err := repo.DeleteUser(ids[2], 0)
+	assert.Nil(t, err)
+
+	users, err := repo.FindUsersByIds([]string{ids[0], ids[1], ids[2], "unknown"})
+	assert.Nil(t, err)
+
+	var found []string
+	for _, user := range users {
+		found = append(found, user.ID)
+	}
+
+	assert.ElementsMatch(t, []string{ids[0], ids[1]}, found)
+}
+
+func TestListUsers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxRepository(db, db)
+
+	var ids []string
+	for _, name := range []string{"John", "Paul", "George", "Ringo"} {
+		user := &entities.User{
+			ID:        ulid.Make().String(),
+			FirstName: name,
+			LastName:  "Beatle",
+			Email:     name + "@example.com",
+			Password:  "password",
+			Role:      "user",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		err := repo.CreateUser(user)
+		assert.Nil(t, err)
+
+		ids = append(ids, user.ID)
+	}
+
+	err := repo.DeleteUser(ids[1], 0)
+	assert.Nil(t, err)
+
+	userIds := func(users []*entities.User) []string {
+		var found []string
+		for _, user := range users {
+			found = append(found, user.ID)
+		}
+		return found
+	}
+
+	users, err := repo.ListUsers(domain.ListUsersQuery{Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ids[0], ids[2]}, userIds(users))
+
+	users, err = repo.ListUsers(domain.ListUsersQuery{After: &domain.UserCursor{ID: ids[2]}, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ids[3]}, userIds(users))
+
+	users, err = repo.ListUsers(domain.ListUsersQuery{Offset: 1, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ids[2]}, userIds(users))
+
+	count, err := repo.CountUsers(domain.ListUsersQuery{Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestListUsers_FilterAndSort(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxRepository(db, db)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Create users a day apart; George and Ringo share a creation time.
+	var users []*entities.User
+	for i, name := range []string{"John", "Paul", "George", "Ringo"} {
+		domainName := "example.com"
+		if name == "Paul" {
+			domainName = "beatles.org"
+		}
+
+		created := start.AddDate(0, 0, i)
+		if name == "Ringo" {
+			created = users[2].CreatedAt
+		}
+
+		user := &entities.User{
+			ID:        ulid.Make().String(),
+			FirstName: name,
+			LastName:  "Beatle",
+			Email:     strings.ToLower(name) + "@" + domainName,
+			Password:  "password",
+			Role:      "user",
+			CreatedAt: created,
+			UpdatedAt: created,
+		}
+		if name == "John" {
+			user.Role = "admin"
+		}
+
+		err := repo.CreateUser(user)
+		assert.Nil(t, err)
+
+		users = append(users, user)
+	}
+
+	err := repo.DeleteUser(users[3].ID, 0)
+	assert.Nil(t, err)
+
+	userNames := func(users []*entities.User) []string {
+		var found []string
+		for _, user := range users {
+			found = append(found, user.FirstName)
+		}
+		return found
+	}
+
+	list := func(query domain.ListUsersQuery) []string {
+		found, err := repo.ListUsers(query)
+		assert.Nil(t, err)
+		return userNames(found)
+	}
+
+	assert.Equal(t, []string{"John"}, list(domain.ListUsersQuery{Filter: domain.UserFilter{Role: "admin"}, Limit: 10}))
+	assert.Equal(t, []string{"Paul"}, list(domain.ListUsersQuery{Filter: domain.UserFilter{EmailDomain: "Beatles.org"}, Limit: 10}))
+	assert.Equal(t, []string{"Ringo"}, list(domain.ListUsersQuery{Filter: domain.UserFilter{Deleted: domain.OnlyDeleted}, Limit: 10}))
+
+	// The range includes its start and excludes its end.
+	assert.Equal(t, []string{"Paul"}, list(domain.ListUsersQuery{
+		Filter: domain.UserFilter{CreatedAfter: start.AddDate(0, 0, 1), CreatedBefore: start.AddDate(0, 0, 2)},
+		Limit:  10,
+	}))
+
+	assert.Equal(t, []string{"George", "John", "Paul", "Ringo"}, list(domain.ListUsersQuery{
+		Filter: domain.UserFilter{Deleted: domain.IncludeDeleted},
+		SortBy: domain.SortByFirstName,
+		Limit:  10,
+	}))
+
+	// Walk the newest users first, one page at a time, across the tie.
+	query := domain.ListUsersQuery{
+		Filter:     domain.UserFilter{Deleted: domain.IncludeDeleted},
+		SortBy:     domain.SortByCreatedAt,
+		Descending: true,
+		Limit:      1,
+	}
+
+	var walked []*entities.User
+	for i := 0; i < 5; i++ {
+		page, err := repo.ListUsers(query)
+		assert.Nil(t, err)
+		if len(page) == 0 {
+			break
+		}
+
+		walked = append(walked, page...)
+		last := page[len(page)-1]
+		query.After = &domain.UserCursor{Value: query.SortBy.Value(last), ID: last.ID}
+	}
+
+	names := userNames(walked)
+	assert.Len(t, names, 4)
+	assert.ElementsMatch(t, []string{"George", "Ringo"}, names[:2])
+	assert.Equal(t, []string{"Paul", "John"}, names[2:])
+
+	count, err := repo.CountUsers(domain.ListUsersQuery{Filter: domain.UserFilter{EmailDomain: "example.com"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	// Exports ignore pagination and stop at the first error of the callback.
+	var exported []*entities.User
+	err = repo.ExportUsers(domain.ListUsersQuery{
+		Filter: domain.UserFilter{Deleted: domain.IncludeDeleted},
+		SortBy: domain.SortByFirstName,
+		After:  &domain.UserCursor{Value: "Paul", ID: users[1].ID},
+		Limit:  1,
+	}, func(user *entities.User) error {
+		exported = append(exported, user)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"George", "John", "Paul", "Ringo"}, userNames(exported))
+	assert.False(t, exported[3].DeletedAt.IsZero())
+
+	stop := errors.New("stop")
+	err = repo.ExportUsers(domain.ListUsersQuery{}, func(user *entities.User) error {
+		return stop
+	})
+	assert.Equal(t, stop, err)
+}
+
+func TestUpdateUser(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 
@@ -148,6 +371,7 @@ func TestPatchUser(t *testing.T) {
 		Email:     "john.lennon@example.com",
 		Password:  "password",
 		Role:      "user",
+		Region:    "south",
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -155,22 +379,43 @@ func TestPatchUser(t *testing.T) {
 	err := repo.CreateUser(initialUser)
 	assert.Nil(t, err)
 
-	updatedUser := &entities.User{
+	foundUser, err := repo.FindUserById(userId)
+	assert.Nil(t, err)
+	assert.Equal(t, "south", foundUser.Region)
+
+	err = repo.UpdateUser(&entities.User{
 		ID:        userId,
 		FirstName: "Paul",
-		LastName:  "McCartney",
+		LastName:  "",
 		Email:     "paul.mccartney@example.com",
-	}
+		Role:      "admin",
+		Region:    "north",
+		UpdatedAt: time.Now(),
+	})
+	assert.Nil(t, err)
 
-	err = repo.PatchUser(updatedUser)
+	foundUser, err = repo.FindUserById(userId)
 	assert.Nil(t, err)
 
-	foundUser, err := repo.FindUserById(userId)
+	assert.Equal(t, "Paul", foundUser.FirstName)
+	assert.Equal(t, "", foundUser.LastName)
+	assert.Equal(t, "north", foundUser.Region)
+	assert.Equal(t, "paul.mccartney@example.com", foundUser.Email)
+	assert.Equal(t, "admin", foundUser.Role)
+	assert.Equal(t, initialUser.Password, foundUser.Password)
+
+	// The cleared last name is stored as NULL, and read back by every query.
+	var lastNames int
+	assert.Nil(t, db.Get(&lastNames, `SELECT COUNT(*) FROM users WHERE id = $1 AND last_name IS NULL`, userId))
+	assert.Equal(t, 1, lastNames)
+
+	users, err := repo.FindUsersByIds([]string{userId})
 	assert.Nil(t, err)
+	assert.Len(t, users, 1)
 
-	assert.NotEqualValues(t, initialUser.FirstName, foundUser.FirstName)
-	assert.NotEqualValues(t, initialUser.LastName, foundUser.LastName)
-	assert.NotEqualValues(t, initialUser.Email, foundUser.Email)
+	listed, err := repo.ListUsers(domain.ListUsersQuery{SortBy: domain.SortByLastName, Limit: 10})
+	assert.Nil(t, err)
+	assert.Len(t, listed, 1)
 }
 
 func TestDeleteUser(t *testing.T) {
@@ -194,9 +439,75 @@ func TestDeleteUser(t *testing.T) {
 	err := repo.CreateUser(user)
 	assert.Nil(t, err)
 
-	err = repo.DeleteUser(userId)
+	deleted, err := repo.IsUserDeleted(userId)
+	assert.Nil(t, err)
+	assert.False(t, deleted)
+
+	err = repo.DeleteUser(userId, 0)
 	assert.Nil(t, err)
 
 	_, err = repo.FindUserById(userId)
 	assert.Error(t, err)
+
+	// The row keeps the ID.
+	deleted, err = repo.IsUserDeleted(userId)
+	assert.Nil(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = repo.IsUserDeleted(ulid.Make().String())
+	assert.Nil(t, err)
+	assert.False(t, deleted)
+}
+
+func TestUserVersion(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxRepository(db, db)
+
+	userId := ulid.Make().String()
+	user := &entities.User{
+		ID:        userId,
+		FirstName: "John",
+		LastName:  "Lennon",
+		Email:     "john.lennon@example.com",
+		Password:  "password",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err := repo.CreateUser(user)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), user.Version)
+
+	// Every write moves the user to the next version.
+	update := &entities.User{ID: userId, FirstName: "Paul", LastName: "McCartney", Email: "paul@example.com", UpdatedAt: time.Now(), Version: 1}
+	err = repo.UpdateUser(update)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), update.Version)
+
+	// A write at an older version is refused and changes nothing.
+	err = repo.UpdateUser(&entities.User{ID: userId, FirstName: "George", UpdatedAt: time.Now(), Version: 1})
+	assert.Equal(t, domain.ErrVersionMismatch, err)
+
+	foundUser, err := repo.FindUserById(userId)
+	assert.Nil(t, err)
+	assert.Equal(t, "Paul", foundUser.FirstName)
+	assert.Equal(t, int64(2), foundUser.Version)
+
+	// Without a version the write is unconditional.
+	update = &entities.User{ID: userId, FirstName: "Ringo", LastName: "Starr", Email: "ringo@example.com", UpdatedAt: time.Now()}
+	err = repo.UpdateUser(update)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), update.Version)
+
+	err = repo.DeleteUser(userId, 2)
+	assert.Equal(t, domain.ErrVersionMismatch, err)
+
+	err = repo.DeleteUser(userId, 3)
+	assert.Nil(t, err)
+
+	_, err = repo.FindUserById(userId)
+	assert.Equal(t, domain.ErrUserNotFound, err)
 }
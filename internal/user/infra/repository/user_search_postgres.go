@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+const postgresUserSearchStatement = `
+	SELECT id, first_name, last_name, email, role, region, created_at, updated_at, version,
+		ts_rank(search_vector, query) + word_similarity($2, search_text) AS rank
+	FROM users, to_tsquery('simple', $1) AS query
+	WHERE deleted_at IS NULL AND (search_vector @@ query OR $2 <% search_text)
+	ORDER BY rank DESC, id
+	LIMIT $3
+	`
+
+type postgresUserSearchIndex struct {
+	reader *sqlx.DB
+}
+
+// NewPostgresUserSearchIndex searches the search_vector and search_text
+// columns added by the 005 migration. Words match by prefix through the
+// tsvector, and misspellings through pg_trgm word similarity.
+func NewPostgresUserSearchIndex(reader *sqlx.DB) domain.UserSearchIndex {
+	return &postgresUserSearchIndex{reader: reader}
+}
+
+// SearchUsers ranks users by the sum of their full-text rank and their
+// trigram similarity to the query.
+func (r *postgresUserSearchIndex) SearchUsers(query string, limit int) ([]*domain.UserSearchHit, error) {
+	terms := domain.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	statement, args := postgresSearchStatement(terms, limit)
+
+	rows, err := r.reader.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []*domain.UserSearchHit
+
+	for rows.Next() {
+		var user entities.User
+		var lastName, region sql.NullString
+		var rank float64
+		err := rows.Scan(
+			&user.ID,
+			&user.FirstName,
+			&lastName,
+			&user.Email,
+			&user.Role,
+			&region,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Version,
+			&rank,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		user.LastName = lastName.String
+		user.Region = region.String
+
+		hits = append(hits, &domain.UserSearchHit{
+			User:       &user,
+			Rank:       rank,
+			Highlights: domain.UserHighlights(&user, terms),
+		})
+	}
+
+	return hits, rows.Err()
+}
+
+// postgresSearchStatement returns the search statement with its arguments:
+// the tsquery that matches every term by prefix, the terms for the trigram
+// similarity, and the limit.
+func postgresSearchStatement(terms []string, limit int) (string, []interface{}) {
+	// Terms hold letters and digits only, so they are safe in a tsquery.
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+
+	return postgresUserSearchStatement, []interface{}{strings.Join(prefixes, " & "), strings.Join(terms, " "), limit}
+}
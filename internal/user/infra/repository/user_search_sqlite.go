@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+// sqliteUserSearchSchema keeps an FTS5 index of the users table up to date
+// with triggers. The index only stores the tokens; rows are read from users.
+const sqliteUserSearchSchema = `
+CREATE VIRTUAL TABLE IF NOT EXISTS users_fts USING fts5(
+	first_name, last_name, email,
+	content='users', content_rowid='rowid', tokenize='unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS users_fts_insert AFTER INSERT ON users BEGIN
+	INSERT INTO users_fts (rowid, first_name, last_name, email)
+	VALUES (new.rowid, new.first_name, new.last_name, new.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS users_fts_delete AFTER DELETE ON users BEGIN
+	INSERT INTO users_fts (users_fts, rowid, first_name, last_name, email)
+	VALUES ('delete', old.rowid, old.first_name, old.last_name, old.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS users_fts_update AFTER UPDATE ON users BEGIN
+	INSERT INTO users_fts (users_fts, rowid, first_name, last_name, email)
+	VALUES ('delete', old.rowid, old.first_name, old.last_name, old.email);
+	INSERT INTO users_fts (rowid, first_name, last_name, email)
+	VALUES (new.rowid, new.first_name, new.last_name, new.email);
+END;
+
+INSERT INTO users_fts (users_fts) VALUES ('rebuild');
+`
+
+const sqliteUserSearchStatement = `
+	SELECT u.id, u.first_name, u.last_name, u.email, u.role, u.region, u.created_at, u.updated_at, u.version, -bm25(users_fts) AS rank
+	FROM users_fts
+	JOIN users u ON u.rowid = users_fts.rowid
+	WHERE users_fts MATCH $1 AND u.deleted_at IS NULL
+	ORDER BY rank DESC, u.id
+	LIMIT $2
+	`
+
+type sqliteUserSearchIndex struct {
+	reader *sqlx.DB
+}
+
+// NewSqliteUserSearchIndex creates, or rebuilds, the FTS5 index of the users
+// table in db. It fails with "no such module: fts5" when go-sqlite3 was built
+// without the sqlite_fts5 tag.
+//
+// Words match by prefix; unlike the Postgres index, misspellings are not
+// tolerated.
+func NewSqliteUserSearchIndex(db *sqlx.DB) (domain.UserSearchIndex, error) {
+	if _, err := db.Exec(sqliteUserSearchSchema); err != nil {
+		return nil, err
+	}
+
+	return &sqliteUserSearchIndex{reader: db}, nil
+}
+
+// SearchUsers ranks users by BM25, which FTS5 reports as lower-is-better, so
+// it is negated.
+func (r *sqliteUserSearchIndex) SearchUsers(query string, limit int) ([]*domain.UserSearchHit, error) {
+	terms := domain.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	statement, args := sqliteSearchStatement(terms, limit)
+
+	rows, err := r.reader.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []*domain.UserSearchHit
+
+	for rows.Next() {
+		var user entities.User
+		var lastName, region sql.NullString
+		var rank float64
+		err := rows.Scan(
+			&user.ID,
+			&user.FirstName,
+			&lastName,
+			&user.Email,
+			&user.Role,
+			&region,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Version,
+			&rank,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		user.LastName = lastName.String
+		user.Region = region.String
+
+		hits = append(hits, &domain.UserSearchHit{
+			User:       &user,
+			Rank:       rank,
+			Highlights: domain.UserHighlights(&user, terms),
+		})
+	}
+
+	return hits, rows.Err()
+}
+
+// sqliteSearchStatement returns the search statement with its arguments: the
+// FTS5 query that matches every term by prefix, and the limit.
+func sqliteSearchStatement(terms []string, limit int) (string, []interface{}) {
+	// Terms hold letters and digits only, so they are safe in quotes.
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = `"` + term + `"*`
+	}
+
+	return sqliteUserSearchStatement, []interface{}{strings.Join(prefixes, " "), limit}
+}
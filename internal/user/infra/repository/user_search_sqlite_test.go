@@ -0,0 +1,82 @@
+package repository_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The FTS5 module is only compiled into go-sqlite3 with the sqlite_fts5 build
+// tag, which make test sets; a plain "go test" skips this test.
+func TestSqliteUserSearchIndex(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewSqlxRepository(db, db)
+
+	create := func(firstName, lastName, email string) *entities.User {
+		user := &entities.User{
+			ID:        ulid.Make().String(),
+			FirstName: firstName,
+			LastName:  lastName,
+			Email:     email,
+			Password:  "password",
+			Role:      "user",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		require.NoError(t, repo.CreateUser(user))
+		return user
+	}
+
+	// Users created before the index is built are indexed by the rebuild.
+	john := create("John", "Lennon", "john.lennon@example.com")
+
+	index, err := repository.NewSqliteUserSearchIndex(db)
+	if err != nil && strings.Contains(err.Error(), "fts5") {
+		t.Skip("go-sqlite3 was built without FTS5")
+	}
+	require.NoError(t, err)
+
+	johnny := create("Johnny", "Cash", "cash@example.com")
+	ringo := create("Ringo", "Starr", "ringo<b>@example.com")
+
+	searchIds := func(query string) []string {
+		hits, err := index.SearchUsers(query, 10)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, hit := range hits {
+			ids = append(ids, hit.User.ID)
+		}
+		return ids
+	}
+
+	// Partial words match by prefix, in any field.
+	assert.ElementsMatch(t, []string{john.ID, johnny.ID}, searchIds("joh"))
+	assert.Equal(t, []string{john.ID}, searchIds("john len"))
+	assert.Equal(t, []string{johnny.ID}, searchIds("cash@exam"))
+
+	hits, err := index.SearchUsers("ringo", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "<mark>ringo</mark>&lt;b&gt;@example.com", hits[0].Highlights["email"])
+	assert.Equal(t, "<mark>Ringo</mark>", hits[0].Highlights["first_name"])
+
+	// Updates are reindexed and deleted users are left out.
+	ringo.FirstName = "Richard"
+	require.NoError(t, repo.UpdateUser(ringo))
+	assert.Equal(t, []string{ringo.ID}, searchIds("richard"))
+
+	require.NoError(t, repo.DeleteUser(john.ID, 0))
+	assert.Equal(t, []string{johnny.ID}, searchIds("john"))
+
+	assert.Empty(t, searchIds("paul"))
+}
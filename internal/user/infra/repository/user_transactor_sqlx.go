@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+)
+
+// sqlxConn is the part of *sqlx.DB used by the repositories. *sqlx.Tx has it
+// as well, so that the same repositories can write inside a transaction.
+type sqlxConn interface {
+	DriverName() string
+	Get(dest interface{}, query string, args ...interface{}) error
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type userTransactorSqlx struct {
+	writer *sqlx.DB
+}
+
+func NewSqlxUserTransactor(writer *sqlx.DB) domain.UserTransactor {
+	return &userTransactorSqlx{writer: writer}
+}
+
+func NewSqlxInvitationTransactor(writer *sqlx.DB) domain.InvitationTransactor {
+	return &userTransactorSqlx{writer: writer}
+}
+
+// WithinTransaction gives fn user and event repositories that both read and
+// write through one transaction on the writer, so that fn sees its own
+// writes.
+func (t *userTransactorSqlx) WithinTransaction(fn func(users domain.UserRepository, events domain.UserEventRepository) error) error {
+	return t.within(func(tx *sqlx.Tx) error {
+		return fn(&repoSqlx{writer: tx, reader: tx}, &userEventRepoSqlx{writer: tx, reader: tx})
+	})
+}
+
+// WithinInvitationTransaction is WithinTransaction with an invitation
+// repository on the same transaction.
+func (t *userTransactorSqlx) WithinInvitationTransaction(fn func(invitations domain.InvitationRepository, users domain.UserRepository, events domain.UserEventRepository) error) error {
+	return t.within(func(tx *sqlx.Tx) error {
+		return fn(&invitationRepoSqlx{writer: tx, reader: tx}, &repoSqlx{writer: tx, reader: tx}, &userEventRepoSqlx{writer: tx, reader: tx})
+	})
+}
+
+func (t *userTransactorSqlx) within(fn func(tx *sqlx.Tx) error) error {
+	tx, err := t.writer.Beginx()
+	if err != nil {
+		return err
+	}
+
+	// Once committed, the rollback does nothing.
+	defer tx.Rollback()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserTransactor(t *testing.T) {
+	db := setupUserEventTestDB(t)
+	defer db.Close()
+
+	// Every connection to :memory: is a new database.
+	db.SetMaxOpenConns(1)
+
+	transactor := repository.NewSqlxUserTransactor(db)
+	users := repository.NewSqlxRepository(db, db)
+	events := repository.NewSqlxUserEventRepository(db, db)
+
+	newUser := func(id string) *entities.User {
+		return &entities.User{ID: id, FirstName: "John", LastName: "Lennon", Email: id + "@example.com", Role: "user", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	}
+
+	// A failure rolls back every write, including the ones fn read back.
+	failure := errors.New("failure")
+	err := transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+		user := newUser("1")
+		require.NoError(t, users.CreateUser(user))
+		require.NoError(t, events.AppendUserEvent(entities.NewUserEvent(entities.UserCreated, user)))
+
+		found, err := users.FindUserById("1")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+
+		return failure
+	})
+	assert.Equal(t, failure, err)
+
+	_, err = users.FindUserById("1")
+	assert.Equal(t, domain.ErrUserNotFound, err)
+
+	logged, err := events.ListUserEvents(0, 10)
+	assert.Nil(t, err)
+	assert.Empty(t, logged)
+
+	// Success commits them.
+	err = transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+		user := newUser("2")
+		if err := users.CreateUser(user); err != nil {
+			return err
+		}
+		return events.AppendUserEvent(entities.NewUserEvent(entities.UserCreated, user))
+	})
+	assert.Nil(t, err)
+
+	found, err := users.FindUserById("2")
+	assert.Nil(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "John", found.FirstName)
+
+	logged, err = events.ListUserEvents(0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, logged, 1)
+}
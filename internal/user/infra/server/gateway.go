@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewGateway builds the JSON/REST gateway described by the google.api.http
+// rules in proto/user.proto. Every request is forwarded to the gRPC server
+// listening on endpoint, so both transports share the same implementation,
+// validation and error codes. Extra dial options, such as a custom dialer,
+// are added to the insecure default.
+//
+// The gateway is the gRPC contract over JSON, for clients built on the
+// proto: it serves the RPCs of UserService and nothing else. The canonical
+// HTTP API is the one under /api, whose operations that have an RPC call the
+// same gRPC server in-process.
+func NewGateway(ctx context.Context, endpoint string, dialOptions ...grpc.DialOption) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(identityHeaderMatcher),
+		runtime.WithErrorHandler(catalogueErrorHandler),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+	)
+
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, dialOptions...)
+	if err := pb.RegisterUserServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
+		return nil, err
+	}
+
+	return mux, nil
+}
+
+// identityHeaderMatcher forwards the identity headers used by the policy
+// engine as gRPC metadata, on top of the headers the gateway forwards by
+// default.
+func identityHeaderMatcher(key string) (string, bool) {
+	canonical := http.CanonicalHeaderKey(key)
+
+	switch {
+	case canonical == http.CanonicalHeaderKey(policy.HeaderUserID),
+		canonical == http.CanonicalHeaderKey(policy.HeaderUserRole),
+		canonical == http.CanonicalHeaderKey(policy.HeaderUserSignature),
+		strings.HasPrefix(canonical, http.CanonicalHeaderKey(policy.HeaderSubjectPrefix)):
+		return strings.ToLower(key), true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// catalogueErrorHandler writes errors as the default handler does, a
+// google.rpc.Status body, but with the HTTP status the catalogue gives the
+// error code, so that an error answers the same status under /v1 and under
+// /api. Errors without a code keep the status of their gRPC code.
+func catalogueErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if st, ok := status.FromError(err); ok {
+		for _, detail := range st.Details() {
+			info, ok := detail.(*errdetails.ErrorInfo)
+			if !ok || info.Domain != errcode.Domain {
+				continue
+			}
+
+			if code := errcode.Code(info.Reason); code.Known() {
+				w = &statusWriter{ResponseWriter: w, status: code.Definition().Status}
+			}
+		}
+	}
+
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+// statusWriter writes status instead of the status it is given.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(int) {
+	w.ResponseWriter.WriteHeader(w.status)
+}
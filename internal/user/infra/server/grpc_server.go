@@ -1,31 +1,90 @@
 package server
 
 import (
+	"context"
 	"log"
 	"net"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
-	grpcService "github.com/jonattasmoraes/titan/internal/user/infra/grpc"
 	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
-	"github.com/jonattasmoraes/titan/internal/user/usecase"
 )
 
-func StartGrpcServer(getUserById *usecase.GetUserByIdUsecase) {
-	lis, err := net.Listen("tcp", ":50051")
+const (
+	DefaultGrpcAddress  = ":50051"
+	DefaultDrainTimeout = 15 * time.Second
+)
+
+// StartGrpcServer listens on address and serves until ctx is cancelled. It
+// then marks the health service as not serving and stops gracefully, giving
+// in-flight RPCs up to drainTimeout to finish. Streams that only end with the
+// client, as WatchUsers, are expected to end with ctx as well: the user
+// server is given it as its shutdown context.
+func StartGrpcServer(ctx context.Context, address string, grpcServer *grpc.Server, healthServer *health.Server, drainTimeout time.Duration) error {
+	lis, err := net.Listen("tcp", address)
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		return err
 	}
 
-	grpcServer := grpc.NewServer()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- grpcServer.Serve(lis)
+	}()
+
+	log.Printf("Listening and serving GRPC on %s", lis.Addr())
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
 
-	pb.RegisterUserServiceServer(grpcServer, grpcService.NewUserGrpcServer(getUserById))
+	log.Printf("Stopping GRPC server, draining for up to %s", drainTimeout)
+
+	if healthServer != nil {
+		healthServer.Shutdown()
+	}
+
+	GracefulStop(grpcServer, drainTimeout)
+
+	return nil
+}
+
+// NewGrpcServer creates a gRPC server with the UserService, the standard
+// grpc.health.v1 service (when healthServer is not nil) and reflection
+// registered, ready to Serve on any listener.
+func NewGrpcServer(userServer pb.UserServiceServer, healthServer *health.Server, opts ...grpc.ServerOption) *grpc.Server {
+	grpcServer := grpc.NewServer(opts...)
+
+	pb.RegisterUserServiceServer(grpcServer, userServer)
+
+	if healthServer != nil {
+		healthpb.RegisterHealthServer(grpcServer, healthServer)
+	}
 
 	reflection.Register(grpcServer)
 
-	log.Printf("Listening and serving GRPC on :50051")
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+	return grpcServer
+}
+
+// GracefulStop stops accepting new RPCs and waits for the running ones. If
+// they are not done within timeout, the remaining ones are cancelled.
+func GracefulStop(grpcServer *grpc.Server, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf("GRPC drain timeout reached, closing remaining RPCs")
+		grpcServer.Stop()
+		<-done
 	}
 }
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakePinger struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (p *fakePinger) PingContext(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+func (p *fakePinger) set(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.err = err
+}
+
+// slowUserService blocks GetUserByID until release is closed or the call is
+// cancelled.
+type slowUserService struct {
+	pb.UnimplementedUserServiceServer
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *slowUserService) GetUserByID(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	close(s.started)
+
+	select {
+	case <-s.release:
+		return &pb.GetUserResponse{Id: req.Id}, nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func startTestGrpcServer(t *testing.T, service pb.UserServiceServer) (*grpc.Server, *grpc.ClientConn) {
+	listener := bufconn.Listen(1 << 20)
+
+	grpcServer := NewGrpcServer(service, health.NewServer())
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return grpcServer, conn
+}
+
+func servingStatus(t *testing.T, healthServer *health.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	response, err := healthServer.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+
+	return response.Status
+}
+
+func TestWatchDatabaseHealth(t *testing.T) {
+	healthServer := health.NewServer()
+	pinger := &fakePinger{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go WatchDatabaseHealth(ctx, healthServer, pinger, 5*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return servingStatus(t, healthServer, pb.UserService_ServiceDesc.ServiceName) == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	pinger.set(errors.New("connection refused"))
+
+	assert.Eventually(t, func() bool {
+		return servingStatus(t, healthServer, "") == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	pinger.set(nil)
+
+	assert.Eventually(t, func() bool {
+		return servingStatus(t, healthServer, "") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestNewGrpcServer_RegistersHealth(t *testing.T) {
+	_, conn := startTestGrpcServer(t, &pb.UnimplementedUserServiceServer{})
+
+	response, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, response.Status)
+}
+
+func TestGracefulStop_DrainsInFlightCalls(t *testing.T) {
+	service := &slowUserService{started: make(chan struct{}), release: make(chan struct{})}
+	grpcServer, conn := startTestGrpcServer(t, service)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := pb.NewUserServiceClient(conn).GetUserByID(context.Background(), &pb.GetUserRequest{Id: "1"})
+		result <- err
+	}()
+	<-service.started
+
+	stopped := make(chan struct{})
+	go func() {
+		GracefulStop(grpcServer, time.Second)
+		close(stopped)
+	}()
+
+	close(service.release)
+	<-stopped
+
+	assert.NoError(t, <-result)
+}
+
+func TestGracefulStop_ForcesStopAfterTimeout(t *testing.T) {
+	service := &slowUserService{started: make(chan struct{}), release: make(chan struct{})}
+	grpcServer, conn := startTestGrpcServer(t, service)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := pb.NewUserServiceClient(conn).GetUserByID(context.Background(), &pb.GetUserRequest{Id: "1"})
+		result <- err
+	}()
+	<-service.started
+
+	start := time.Now()
+	GracefulStop(grpcServer, 20*time.Millisecond)
+
+	assert.Less(t, time.Since(start), time.Second)
+
+	err := <-result
+	require.Error(t, err)
+	assert.NotEqual(t, codes.OK, status.Code(err))
+}
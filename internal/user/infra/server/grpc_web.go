@@ -0,0 +1,55 @@
+package server
+
+import (
+	nethttp "net/http"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"google.golang.org/grpc"
+)
+
+// GrpcWebConfig controls which browsers may call the UserService over
+// gRPC-Web.
+type GrpcWebConfig struct {
+	// AllowedOrigins lists the origins allowed to make cross-origin calls.
+	// "*" allows any origin; an empty list allows same-origin calls only.
+	AllowedOrigins []string
+	// AllowedHeaders are accepted on top of the gRPC-Web headers, the
+	// authorization header and the identity headers.
+	AllowedHeaders []string
+}
+
+// NewGrpcWebHandler serves gRPC-Web requests, unary and server streaming,
+// with the same grpc.Server as native clients, so interceptors, policy and
+// error codes behave the same. Any other request is answered with 415.
+func NewGrpcWebHandler(grpcServer *grpc.Server, config GrpcWebConfig) nethttp.Handler {
+	headers := append([]string{
+		"content-type", "x-grpc-web", "x-user-agent", "grpc-timeout",
+		"authorization", policy.HeaderUserID, policy.HeaderUserRole, policy.HeaderUserSignature,
+	}, config.AllowedHeaders...)
+
+	wrapped := grpcweb.WrapServer(grpcServer,
+		grpcweb.WithOriginFunc(config.allowOrigin),
+		grpcweb.WithAllowedRequestHeaders(headers),
+		grpcweb.WithCorsForRegisteredEndpointsOnly(true),
+	)
+
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if !wrapped.IsGrpcWebRequest(r) && !wrapped.IsAcceptableGrpcCorsRequest(r) {
+			nethttp.Error(w, "expected a gRPC-Web request", nethttp.StatusUnsupportedMediaType)
+			return
+		}
+
+		wrapped.ServeHTTP(w, r)
+	})
+}
+
+func (c GrpcWebConfig) allowOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+
+	return false
+}
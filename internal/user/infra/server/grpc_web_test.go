@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type grpcWebUserService struct {
+	pb.UnimplementedUserServiceServer
+}
+
+func (s *grpcWebUserService) GetUserByID(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	if req.Id != "1" {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	return &pb.GetUserResponse{Id: "1", FirstName: "John"}, nil
+}
+
+func (s *grpcWebUserService) WatchUsers(req *pb.WatchUsersRequest, stream pb.UserService_WatchUsersServer) error {
+	for _, cursor := range []string{"1", "2"} {
+		if err := stream.Send(&pb.UserEvent{Cursor: cursor}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func startGrpcWebServer(t *testing.T, config GrpcWebConfig) string {
+	grpcServer := NewGrpcServer(&grpcWebUserService{}, nil)
+	t.Cleanup(grpcServer.Stop)
+
+	httpServer := httptest.NewServer(NewGrpcWebHandler(grpcServer, config))
+	t.Cleanup(httpServer.Close)
+
+	return httpServer.URL
+}
+
+// grpcWebCall posts msg as a single gRPC-Web frame and returns the messages
+// and trailers of the response.
+func grpcWebCall(t *testing.T, url string, method string, msg proto.Message) ([][]byte, string) {
+	payload, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	body := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(payload)))
+	body = append(body, payload...)
+
+	req, err := http.NewRequest(http.MethodPost, url+"/user.UserService/"+method, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("X-Grpc-Web", "1")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	response, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	var messages [][]byte
+	var trailers string
+	for len(response) >= 5 {
+		flag, length := response[0], binary.BigEndian.Uint32(response[1:5])
+		frame := response[5 : 5+length]
+		response = response[5+length:]
+
+		if flag&0x80 != 0 {
+			trailers = strings.ReplaceAll(string(frame), " ", "")
+		} else {
+			messages = append(messages, frame)
+		}
+	}
+
+	// Errors without a body are reported in the response headers instead of
+	// a trailer frame.
+	if trailers == "" {
+		trailers = "grpc-status:" + res.Header.Get("Grpc-Status")
+	}
+
+	return messages, trailers
+}
+
+func TestGrpcWeb_Unary(t *testing.T) {
+	url := startGrpcWebServer(t, GrpcWebConfig{})
+
+	messages, trailers := grpcWebCall(t, url, "GetUserByID", &pb.GetUserRequest{Id: "1"})
+
+	require.Len(t, messages, 1)
+	var user pb.GetUserResponse
+	require.NoError(t, proto.Unmarshal(messages[0], &user))
+	assert.Equal(t, "John", user.FirstName)
+	assert.Contains(t, trailers, "grpc-status:0")
+
+	_, trailers = grpcWebCall(t, url, "GetUserByID", &pb.GetUserRequest{Id: "2"})
+	assert.Contains(t, trailers, "grpc-status:5")
+}
+
+func TestGrpcWeb_ServerStreaming(t *testing.T) {
+	url := startGrpcWebServer(t, GrpcWebConfig{})
+
+	messages, trailers := grpcWebCall(t, url, "WatchUsers", &pb.WatchUsersRequest{})
+
+	require.Len(t, messages, 2)
+	var event pb.UserEvent
+	require.NoError(t, proto.Unmarshal(messages[1], &event))
+	assert.Equal(t, "2", event.Cursor)
+	assert.Contains(t, trailers, "grpc-status:0")
+}
+
+func TestGrpcWeb_CORS(t *testing.T) {
+	url := startGrpcWebServer(t, GrpcWebConfig{AllowedOrigins: []string{"https://admin.example.com"}})
+
+	preflight := func(origin string) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, url+"/user.UserService/GetUserByID", nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web,x-user-id")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		return res
+	}
+
+	res := preflight("https://admin.example.com")
+	assert.Equal(t, "https://admin.example.com", res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, strings.ToLower(res.Header.Get("Access-Control-Allow-Headers")), "x-user-id")
+
+	res = preflight("https://evil.example.com")
+	assert.Empty(t, res.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestGrpcWeb_RejectsOtherRequests(t *testing.T) {
+	url := startGrpcWebServer(t, GrpcWebConfig{})
+
+	res, err := http.Post(url+"/user.UserService/GetUserByID", "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+)
+
+// Pinger checks that the database can be reached. *sqlx.DB implements it.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// WatchDatabaseHealth pings db every interval and reports the overall server
+// and the UserService as SERVING only while the ping succeeds. It returns
+// when ctx is cancelled.
+func WatchDatabaseHealth(ctx context.Context, healthServer *health.Server, db Pinger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := db.PingContext(pingCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		current := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			current = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+
+		if current != last {
+			if err != nil {
+				log.Printf("Database unreachable, reporting %s: %v", current, err)
+			}
+
+			healthServer.SetServingStatus("", current)
+			healthServer.SetServingStatus(pb.UserService_ServiceDesc.ServiceName, current)
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
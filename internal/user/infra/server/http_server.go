@@ -1,14 +1,78 @@
 package server
 
 import (
+	"context"
+	"log"
+	"net"
+	nethttp "net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/jonattasmoraes/titan/internal/policy"
 	"github.com/jonattasmoraes/titan/internal/user/infra/http"
 )
 
-func StartServer(userHandlers *http.UserHandler) {
+const DefaultHTTPAddress = ":8080"
+
+// RouterConfig controls the versions of the HTTP API.
+type RouterConfig struct {
+	// V1Deprecation is sent with the responses of the v1 routes, the bare
+	// /api routes included. The zero value leaves v1 undeprecated.
+	V1Deprecation http.Deprecation
+	// Identity decides which identity headers to believe. When nil, every
+	// caller is anonymous.
+	Identity *policy.IdentityVerifier
+}
+
+// StartServer listens on address and serves the router until ctx is
+// cancelled. It then stops accepting connections and gives in-flight requests
+// up to drainTimeout to finish.
+func StartServer(ctx context.Context, address string, userHandlers *http.UserHandler, invitationHandlers *http.InvitationHandler, gateway nethttp.Handler, grpcWeb nethttp.Handler, config RouterConfig, drainTimeout time.Duration) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &nethttp.Server{Handler: NewRouter(userHandlers, invitationHandlers, gateway, grpcWeb, config)}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(lis)
+	}()
+
+	log.Printf("Listening and serving HTTP on %s", lis.Addr())
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Stopping HTTP server, draining for up to %s", drainTimeout)
+
+	Shutdown(httpServer, drainTimeout)
+
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for the running requests.
+// If they are not done within timeout, the remaining connections are closed.
+func Shutdown(httpServer *nethttp.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("HTTP drain timeout reached, closing remaining requests")
+		httpServer.Close()
+	}
+}
+
+// NewRouter builds the HTTP router with every route registered, without
+// starting to listen.
+func NewRouter(userHandlers *http.UserHandler, invitationHandlers *http.InvitationHandler, gateway nethttp.Handler, grpcWeb nethttp.Handler, config RouterConfig) *gin.Engine {
 	router := gin.Default()
 
-	startRoutes(router, userHandlers)
+	startRoutes(router, userHandlers, invitationHandlers, gateway, grpcWeb, config)
 
-	router.Run(":8080")
+	return router
 }
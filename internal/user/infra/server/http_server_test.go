@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startSlowHTTPServer serves a handler that answers once release is closed.
+func startSlowHTTPServer(t *testing.T, started chan<- struct{}, release <-chan struct{}) (*http.Server, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	httpServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	})}
+	go httpServer.Serve(lis)
+	t.Cleanup(func() { httpServer.Close() })
+
+	return httpServer, "http://" + lis.Addr().String()
+}
+
+func TestShutdown_DrainsInFlightRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	httpServer, url := startSlowHTTPServer(t, started, release)
+
+	result := make(chan *http.Response, 1)
+	go func() {
+		res, _ := http.Get(url)
+		result <- res
+	}()
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		Shutdown(httpServer, time.Second)
+		close(stopped)
+	}()
+
+	close(release)
+	<-stopped
+
+	res := <-result
+	require.NotNil(t, res)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func TestShutdown_ClosesAfterTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	httpServer, url := startSlowHTTPServer(t, started, release)
+
+	result := make(chan error, 1)
+	go func() {
+		res, err := http.Get(url)
+		if err == nil {
+			res.Body.Close()
+		}
+		result <- err
+	}()
+	<-started
+
+	start := time.Now()
+	Shutdown(httpServer, 20*time.Millisecond)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Error(t, <-result)
+}
@@ -1,22 +1,118 @@
 package server
 
 import (
+	"expvar"
+	nethttp "net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	docs "github.com/jonattasmoraes/titan/docs"
 	"github.com/jonattasmoraes/titan/internal/user/infra/http"
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func startRoutes(router *gin.Engine, userHandlers *http.UserHandler) {
+func startRoutes(router *gin.Engine, userHandlers *http.UserHandler, invitationHandlers *http.InvitationHandler, gateway nethttp.Handler, grpcWeb nethttp.Handler, config RouterConfig) {
 	docs.SwaggerInfo.BasePath = "/api"
-	userRoutes := router.Group("/api")
+	router.Use(http.Authenticate(config.Identity))
+
+	apiRoutes := router.Group("/api")
 	{
-		userRoutes.POST("/user", userHandlers.CreateUser)
-		userRoutes.GET("/user/:id", userHandlers.GetUserById)
-		userRoutes.GET("/users", userHandlers.ListUsers)
-		userRoutes.PATCH("/user/:id", userHandlers.PatchUser)
-		userRoutes.DELETE("/user/:id", userHandlers.DeleteUser)
-		userRoutes.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.NewHandler()))
+		apiRoutes.GET("/errors", http.ListErrorCodes)
+		apiRoutes.GET("/errors/:code", http.GetErrorCode)
+		apiRoutes.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.NewHandler()))
+		apiRoutes.GET("/openapi/user.json", func(c *gin.Context) {
+			c.Data(nethttp.StatusOK, "application/json", docs.UserServiceOpenAPI)
+		})
+	}
+
+	// The bare /api routes are v1, kept for compatibility with existing
+	// clients.
+	v1Middleware := []gin.HandlerFunc{http.Version(http.APIVersion1), http.Deprecated(config.V1Deprecation)}
+	startV1Routes(router.Group("/api", v1Middleware...), userHandlers, invitationHandlers)
+	startV1Routes(router.Group("/api/v1", v1Middleware...), userHandlers, invitationHandlers)
+
+	startV2Routes(router.Group("/api/v2", http.Version(http.APIVersion2)), userHandlers, invitationHandlers)
+
+	router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+
+	// /api is the canonical HTTP API: HTTP features are added there. /v1 is
+	// the gRPC contract over JSON, with the RPCs of proto/user.proto only; it
+	// is not a version of /api. The /api operations that have an RPC are
+	// served by the same gRPC server, so both answer the same statuses and
+	// error codes (TestSurfaces_SameBehaviour).
+	if gateway != nil {
+		router.Any("/v1/*any", gin.WrapH(gateway))
+	}
+
+	if grpcWeb != nil {
+		router.Any("/"+pb.UserService_ServiceDesc.ServiceName+"/*method", gin.WrapH(grpcWeb))
+	}
+}
+
+// startV1Routes registers the v1 routes on group, the invitation routes only
+// when there are invitationHandlers.
+func startV1Routes(group *gin.RouterGroup, userHandlers *http.UserHandler, invitationHandlers *http.InvitationHandler) {
+	group.POST("/user", userHandlers.Idempotent, userHandlers.CreateUser)
+	group.GET("/user/:id", userHandlers.GetUserById)
+	group.GET("/users", userHandlers.ListUsers)
+	group.GET("/users/search", userHandlers.SearchUsers)
+	group.GET("/users/export", userHandlers.ExportUsers)
+	group.POST("/users/import", userHandlers.ImportUsers)
+	group.GET("/users/import/:id", userHandlers.GetImportJob)
+	group.POST("/users:verb", customMethod(map[string]gin.HandlerFunc{
+		"batch": userHandlers.BatchWriteUsers,
+	}))
+	group.PATCH("/user/:id", userHandlers.PatchUser)
+	group.PUT("/user/:id", userHandlers.ReplaceUser)
+	group.DELETE("/user/:id", userHandlers.DeleteUser)
+
+	if invitationHandlers != nil {
+		group.POST("/invitations", invitationHandlers.CreateInvitation)
+		group.POST("/invitations/accept", invitationHandlers.AcceptInvitation)
+		group.POST("/invitations/:id/resend", invitationHandlers.ResendInvitation)
+		group.POST("/invitations/:id/revoke", invitationHandlers.RevokeInvitation)
+	}
+}
+
+// startV2Routes registers the v2 routes on group, which cover every v1 route,
+// the invitation routes only when there are invitationHandlers.
+func startV2Routes(group *gin.RouterGroup, userHandlers *http.UserHandler, invitationHandlers *http.InvitationHandler) {
+	group.POST("/users", userHandlers.Idempotent, userHandlers.CreateUserV2)
+	group.GET("/users", userHandlers.ListUsersV2)
+	group.GET("/users/search", userHandlers.SearchUsersV2)
+	group.GET("/users/export", userHandlers.ExportUsersV2)
+	group.POST("/users/import", userHandlers.ImportUsersV2)
+	group.GET("/users/import/:id", userHandlers.GetImportJobV2)
+	group.POST("/users:verb", customMethod(map[string]gin.HandlerFunc{
+		"batch": userHandlers.BatchWriteUsersV2,
+	}))
+	group.GET("/users/:id", userHandlers.GetUserByIdV2)
+	group.PATCH("/users/:id", userHandlers.PatchUserV2)
+	group.PUT("/users/:id", userHandlers.ReplaceUserV2)
+	group.DELETE("/users/:id", userHandlers.DeleteUserV2)
+
+	if invitationHandlers != nil {
+		group.POST("/invitations", invitationHandlers.CreateInvitationV2)
+		group.POST("/invitations/accept", invitationHandlers.AcceptInvitationV2)
+		group.POST("/invitations/:id/resend", invitationHandlers.ResendInvitationV2)
+		group.POST("/invitations/:id/revoke", invitationHandlers.RevokeInvitationV2)
+	}
+}
+
+// customMethod routes "/collection:verb" custom methods. Gin cannot match a
+// colon inside a path segment literally, so the route is registered with a
+// parameter holding ":verb" and unknown verbs answer 404.
+func customMethod(verbs map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		verb, ok := strings.CutPrefix(ctx.Param("verb"), ":")
+		handler, known := verbs[verb]
+		if !ok || !known {
+			ctx.AbortWithStatus(nethttp.StatusNotFound)
+			return
+		}
+
+		handler(ctx)
 	}
 }
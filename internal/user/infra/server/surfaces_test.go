@@ -0,0 +1,320 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	grpcserver "github.com/jonattasmoraes/titan/internal/user/infra/grpc"
+	httpserver "github.com/jonattasmoraes/titan/internal/user/infra/http"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/infra/server"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/jonattasmoraes/titan/internal/utils"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startSurfaces serves the router with both surfaces, /api and the /v1
+// gateway, over the same usecases and in-memory SQLite database.
+func startSurfaces(t *testing.T) (*httptest.Server, domain.UserRepository) {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+	CREATE TABLE users (
+		id TEXT PRIMARY KEY,
+		first_name TEXT,
+		last_name TEXT,
+		email TEXT,
+		password TEXT,
+		role TEXT,
+		region TEXT,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP,
+		version INTEGER NOT NULL DEFAULT 1
+	);
+	CREATE TABLE user_events (
+		sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_type TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		occurred_at TIMESTAMP NOT NULL
+	);
+	`)
+	require.NoError(t, err)
+
+	users := repository.NewSqlxRepository(db, db)
+	transactor := repository.NewSqlxUserTransactor(db)
+
+	createUser := usecase.NewCreateUserUsecase(transactor)
+	getUserById := usecase.NewGetUserByIdUsecase(users)
+	listUsers := usecase.NewListUsersUsecase(users)
+	exportUsers := usecase.NewExportUsersUsecase(users)
+	updateUser := usecase.NewUpdateUserUsecase(transactor)
+	deleteUser := usecase.NewDeleteUserUsecase(transactor)
+
+	userGrpcServer := grpcserver.NewUserGrpcServer(
+		createUser,
+		getUserById,
+		usecase.NewGetUserByEmailUsecase(users),
+		usecase.NewBatchGetUsersUsecase(users),
+		listUsers,
+		exportUsers,
+		updateUser,
+		deleteUser,
+		usecase.NewListUserEventsUsecase(repository.NewSqlxUserEventRepository(db, db)),
+		nil,
+		context.Background(),
+	)
+
+	grpcServer := server.NewGrpcServer(userGrpcServer, nil)
+	listener := bufconn.Listen(1 << 20)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	gateway, err := server.NewGateway(context.Background(), "passthrough:///titan.test", grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	require.NoError(t, err)
+
+	userHandlers := httpserver.NewUserHandler(
+		userGrpcServer,
+		getUserById,
+		nil,
+		usecase.NewPatchUserDocumentUsecase(users, updateUser),
+		usecase.NewReplaceUserUsecase(transactor, false),
+		usecase.NewBatchWriteUsersUsecase(users, transactor),
+		usecase.NewImportUsersUsecase(users, createUser),
+		nil,
+		nil,
+	)
+
+	gin.SetMode(gin.TestMode)
+	api := httptest.NewServer(server.NewRouter(userHandlers, nil, gateway, nil, server.RouterConfig{Identity: policy.TrustIdentityHeaders}))
+	t.Cleanup(api.Close)
+
+	return api, users
+}
+
+// surfaceAnswer is what a call answered under /api or under /v1: the status,
+// the user it returned and the catalogue code of its error.
+type surfaceAnswer struct {
+	Status    int
+	ID        string
+	FirstName string
+	Email     string
+	Code      string
+}
+
+// callAPI calls the canonical HTTP API, which wraps users in data and
+// reports errors as problems.
+func callAPI(t *testing.T, api *httptest.Server, method string, path string, body string, header http.Header) surfaceAnswer {
+	var answer struct {
+		Data struct {
+			ID        string `json:"id"`
+			FirstName string `json:"first_name"`
+			Email     string `json:"email"`
+		} `json:"data"`
+		Code string `json:"code"`
+	}
+
+	status := call(t, api, method, "/api"+path, body, header, &answer)
+
+	return surfaceAnswer{Status: status, ID: answer.Data.ID, FirstName: answer.Data.FirstName, Email: answer.Data.Email, Code: answer.Code}
+}
+
+// callGateway calls the gateway, which answers with the proto messages and
+// reports errors as google.rpc.Status.
+func callGateway(t *testing.T, api *httptest.Server, method string, path string, body string) surfaceAnswer {
+	var answer struct {
+		ID        string `json:"id"`
+		FirstName string `json:"first_name"`
+		Email     string `json:"email"`
+		Details   []struct {
+			Reason string `json:"reason"`
+		} `json:"details"`
+	}
+
+	status := call(t, api, method, "/v1"+path, body, nil, &answer)
+
+	code := ""
+	for _, detail := range answer.Details {
+		if detail.Reason != "" {
+			code = detail.Reason
+		}
+	}
+
+	return surfaceAnswer{Status: status, ID: answer.ID, FirstName: answer.FirstName, Email: answer.Email, Code: code}
+}
+
+func call(t *testing.T, api *httptest.Server, method string, path string, body string, header http.Header, answer interface{}) int {
+	req, err := http.NewRequest(method, api.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := api.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(answer))
+
+	return res.StatusCode
+}
+
+// TestSurfaces_SameBehaviour enforces that the operations the gateway shares
+// with the canonical API answer the same statuses, users and error codes.
+func TestSurfaces_SameBehaviour(t *testing.T) {
+	api, users := startSurfaces(t)
+	user := &entities.User{
+		ID:        "01J00000000000000000000001",
+		FirstName: "John",
+		LastName:  "Lennon",
+		Email:     "john@example.com",
+		Password:  "password123",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Version:   1,
+	}
+	require.NoError(t, users.CreateUser(user))
+	require.NoError(t, users.CreateUser(&entities.User{
+		ID:        "01J00000000000000000000002",
+		FirstName: "Ringo",
+		Email:     "ringo@example.com",
+		Password:  "password123",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		api    string
+		// gatewayMethod is the method of the gateway call when it differs,
+		// as for the custom :patch method.
+		gatewayMethod string
+		gateway       string
+		body          string
+		header        http.Header
+		want          surfaceAnswer
+	}{
+		{
+			name:    "get user",
+			method:  http.MethodGet,
+			api:     "/user/" + user.ID,
+			gateway: "/users/" + user.ID,
+			want:    surfaceAnswer{Status: http.StatusOK, ID: user.ID, FirstName: "John", Email: "john@example.com"},
+		},
+		{
+			name:    "missing user",
+			method:  http.MethodGet,
+			api:     "/user/01J0000000000000000000000X",
+			gateway: "/users/01J0000000000000000000000X",
+			want:    surfaceAnswer{Status: http.StatusNotFound, Code: string(errcode.UserNotFound)},
+		},
+		{
+			name:    "invalid user",
+			method:  http.MethodPost,
+			api:     "/user",
+			gateway: "/users",
+			body:    `{"first_name":"Paul","last_name":"McCartney","email":"paul","password":"password"}`,
+			want:    surfaceAnswer{Status: errcode.EmailInvalid.Definition().Status, Code: string(errcode.EmailInvalid)},
+		},
+		{
+			name:    "taken email",
+			method:  http.MethodPost,
+			api:     "/user",
+			gateway: "/users",
+			body:    `{"first_name":"Paul","last_name":"McCartney","email":"john@example.com","password":"password"}`,
+			want:    surfaceAnswer{Status: http.StatusConflict, Code: string(errcode.EmailTaken)},
+		},
+		{
+			name:          "invalid patch",
+			method:        http.MethodPatch,
+			api:           "/user/" + user.ID,
+			gatewayMethod: http.MethodPost,
+			gateway:       "/users/" + user.ID + ":patch",
+			body:          `{"email":"john"}`,
+			want:          surfaceAnswer{Status: errcode.EmailInvalid.Definition().Status, Code: string(errcode.EmailInvalid)},
+		},
+		{
+			name:          "patch to a taken email",
+			method:        http.MethodPatch,
+			api:           "/user/" + user.ID,
+			gatewayMethod: http.MethodPost,
+			gateway:       "/users/" + user.ID + ":patch",
+			body:          `{"email":"ringo@example.com"}`,
+			want:          surfaceAnswer{Status: http.StatusConflict, Code: string(errcode.EmailTaken)},
+		},
+		{
+			name:    "stale etag",
+			method:  http.MethodDelete,
+			api:     "/user/" + user.ID,
+			gateway: "/users/" + user.ID + "?etag=" + url.QueryEscape(utils.ETag(99)),
+			header:  http.Header{"If-Match": {utils.ETag(99)}},
+			want:    surfaceAnswer{Status: http.StatusPreconditionFailed, Code: string(errcode.VersionMismatch)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, callAPI(t, api, tt.method, tt.api, tt.body, tt.header), "/api")
+			gatewayMethod := tt.method
+			if tt.gatewayMethod != "" {
+				gatewayMethod = tt.gatewayMethod
+			}
+			assert.Equal(t, tt.want, callGateway(t, api, gatewayMethod, tt.gateway, tt.body), "/v1")
+		})
+	}
+}
+
+// TestGateway_UpdateUserWithETag enforces that PATCH /v1/users accepts the
+// etag in the body, although the gateway lists it in the update mask it
+// derives from the body.
+func TestGateway_UpdateUserWithETag(t *testing.T) {
+	api, users := startSurfaces(t)
+	user := &entities.User{
+		ID:        "01J00000000000000000000001",
+		FirstName: "John",
+		LastName:  "Lennon",
+		Email:     "john@example.com",
+		Password:  "password123",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Version:   1,
+	}
+	require.NoError(t, users.CreateUser(user))
+
+	answer := callGateway(t, api, http.MethodPatch, "/users/"+user.ID, `{"first_name":"Johnny","etag":`+strconv.Quote(utils.ETag(1))+`}`)
+	assert.Equal(t, surfaceAnswer{Status: http.StatusOK, ID: user.ID, FirstName: "Johnny", Email: "john@example.com"}, answer)
+
+	answer = callGateway(t, api, http.MethodPatch, "/users/"+user.ID, `{"first_name":"John","etag":`+strconv.Quote(utils.ETag(1))+`}`)
+	assert.Equal(t, surfaceAnswer{Status: http.StatusPreconditionFailed, Code: string(errcode.VersionMismatch)}, answer)
+}
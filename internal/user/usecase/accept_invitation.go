@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+var (
+	ErrInvitationExpired       = errcode.New(errcode.InvitationExpired, "invitation has expired, please ask for a new one")
+	ErrInvitationEmailMismatch = errcode.New(errcode.InvitationEmailMismatch, "this invitation was sent to a different email address")
+	ErrInvalidCredentials      = errcode.New(errcode.InvitationCredentialsInvalid, "invalid credentials for the existing account, please try again")
+)
+
+type AcceptInvitationUsecase struct {
+	invitations domain.InvitationRepository
+	transactor  domain.InvitationTransactor
+}
+
+func NewAcceptInvitationUsecase(
+	invitations domain.InvitationRepository,
+	transactor domain.InvitationTransactor,
+) *AcceptInvitationUsecase {
+	return &AcceptInvitationUsecase{invitations: invitations, transactor: transactor}
+}
+
+// Execute redeems an invitation token. When the invited email already has an
+// account it is linked to the organization after its password is checked,
+// otherwise a new account is created through CreateUserUsecase.
+//
+// The account, the membership and the accepted invitation are written in one
+// transaction, and the invitation is only consumed while it is still pending,
+// so that a token redeemed twice concurrently creates a single account.
+func (u *AcceptInvitationUsecase) Execute(request *dto.AcceptInvitationRequestDTO) (*dto.MembershipResponseDTO, error) {
+	invitation, err := u.invitations.FindInvitationByTokenHash(entities.HashInvitationToken(request.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	if !invitation.MatchesEmail(request.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	if invitation.Status != entities.InvitationPending {
+		return nil, ErrInvitationNotPending
+	}
+
+	if invitation.IsExpired(time.Now()) {
+		invitation.Status = entities.InvitationExpired
+		invitation.UpdatedAt = time.Now()
+
+		err = u.invitations.UpdateInvitation(invitation)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ErrInvitationExpired
+	}
+
+	var account *dto.UserResponseDTO
+
+	err = u.transactor.WithinInvitationTransaction(func(invitations domain.InvitationRepository, users domain.UserRepository, events domain.UserEventRepository) error {
+		userExists, err := users.FindUserByEmail(invitation.Email)
+		if err != nil {
+			return err
+		}
+
+		if userExists != nil && userExists.ID != "" {
+			if subtle.ConstantTimeCompare([]byte(userExists.Password), []byte(request.Password)) != 1 {
+				return ErrInvalidCredentials
+			}
+
+			account = &dto.UserResponseDTO{
+				ID:          userExists.ID,
+				FirstName:   userExists.FirstName,
+				LastName:    userExists.LastName,
+				Email:       userExists.Email,
+				Role:        userExists.Role,
+				Region:      userExists.Region,
+				CreateAt:    userExists.CreatedAt.Format("2006-01-02 15:04:05"),
+				UpdateAt:    userExists.UpdatedAt.Format("2006-01-02 15:04:05"),
+				CreatedTime: userExists.CreatedAt,
+				UpdatedTime: userExists.UpdatedAt,
+				Version:     userExists.Version,
+			}
+		} else {
+			account, err = NewCreateUserUsecase(inTransaction{users, events}).Execute(&dto.UserRequestDTO{
+				FirstName: request.FirstName,
+				LastName:  request.LastName,
+				Email:     invitation.Email,
+				Password:  request.Password,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = invitations.AddMember(&entities.Membership{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         account.ID,
+			Role:           invitation.Role,
+			CreatedAt:      time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		invitation.UserID = account.ID
+		invitation.AcceptedAt = time.Now()
+		invitation.UpdatedAt = time.Now()
+
+		return invitations.AcceptInvitation(invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.MembershipResponseDTO{
+		OrganizationID: invitation.OrganizationID,
+		Role:           invitation.Role,
+		User:           account,
+	}
+
+	return response, nil
+}
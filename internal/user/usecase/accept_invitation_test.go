@@ -0,0 +1,200 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestAcceptInvitation_NewAccount tests the AcceptInvitation usecase.
+// It verifies if a new account is created when the invited email has none.
+func TestAcceptInvitation_NewAccount(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+
+	mockTransactor := &repository.MockInvitationTransactor{Invitations: mockInvitations, Users: mockUsers, Events: mockEvents}
+
+	acceptInvitationUsecase := usecase.NewAcceptInvitationUsecase(mockInvitations, mockTransactor)
+
+	// Create a pending invitation.
+	invitation, token, err := entities.NewInvitation("org-1", "peter.parker@example.com", "admin", "admin-1")
+	assert.NoError(t, err)
+
+	mockInvitations.On("FindInvitationByTokenHash", entities.HashInvitationToken(token)).Return(invitation, nil)
+	mockInvitations.On("AddMember", mock.AnythingOfType("*entities.Membership")).Return(nil)
+	mockInvitations.On("AcceptInvitation", invitation).Return(nil)
+	mockTransactor.On("WithinInvitationTransaction").Return(nil)
+
+	// The invited email has no account yet.
+	mockUsers.On("FindUserByEmail", "peter.parker@example.com").Return(&entities.User{}, nil)
+	mockUsers.On("CreateUser", mock.AnythingOfType("*entities.User")).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	// Execute the usecase.
+	response, err := acceptInvitationUsecase.Execute(&dto.AcceptInvitationRequestDTO{
+		Token:     token,
+		Email:     "peter.parker@example.com",
+		FirstName: "Peter",
+		LastName:  "Parker",
+		Password:  "12345678",
+	})
+
+	// Assert that the account was created and linked with the invited role.
+	assert.NoError(t, err)
+	assert.Equal(t, "org-1", response.OrganizationID)
+	assert.Equal(t, "admin", response.Role)
+	assert.Equal(t, "peter.parker@example.com", response.User.Email)
+	assert.Equal(t, response.User.ID, invitation.UserID)
+
+	mockInvitations.AssertExpectations(t)
+	mockUsers.AssertExpectations(t)
+	mockTransactor.AssertExpectations(t)
+}
+
+// TestAcceptInvitation_ExistingAccount verifies that an existing account is linked.
+func TestAcceptInvitation_ExistingAccount(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+
+	mockTransactor := &repository.MockInvitationTransactor{Invitations: mockInvitations, Users: mockUsers, Events: mockEvents}
+
+	acceptInvitationUsecase := usecase.NewAcceptInvitationUsecase(mockInvitations, mockTransactor)
+
+	invitation, token, err := entities.NewInvitation("org-1", "john.lennon@example.com", "user", "admin-1")
+	assert.NoError(t, err)
+
+	mockInvitations.On("FindInvitationByTokenHash", entities.HashInvitationToken(token)).Return(invitation, nil)
+	mockInvitations.On("AddMember", mock.AnythingOfType("*entities.Membership")).Return(nil)
+	mockInvitations.On("AcceptInvitation", invitation).Return(nil)
+	mockTransactor.On("WithinInvitationTransaction").Return(nil)
+
+	// The invited email already has an account.
+	mockUsers.On("FindUserByEmail", "john.lennon@example.com").Return(&entities.User{
+		ID:        "1",
+		FirstName: "John",
+		LastName:  "Lennon",
+		Email:     "john.lennon@example.com",
+		Password:  "password",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil)
+
+	// Execute the usecase.
+	response, err := acceptInvitationUsecase.Execute(&dto.AcceptInvitationRequestDTO{
+		Token:    token,
+		Email:    "john.lennon@example.com",
+		Password: "password",
+	})
+
+	// Assert that the existing account was linked and no user was created.
+	assert.NoError(t, err)
+	assert.Equal(t, "1", response.User.ID)
+	mockUsers.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+// TestAcceptInvitation_EmailMismatch verifies that only the invited email can redeem the token.
+func TestAcceptInvitation_EmailMismatch(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+
+	mockTransactor := &repository.MockInvitationTransactor{Invitations: mockInvitations, Users: mockUsers, Events: mockEvents}
+
+	acceptInvitationUsecase := usecase.NewAcceptInvitationUsecase(mockInvitations, mockTransactor)
+
+	invitation, token, err := entities.NewInvitation("org-1", "john.lennon@example.com", "user", "admin-1")
+	assert.NoError(t, err)
+
+	mockInvitations.On("FindInvitationByTokenHash", entities.HashInvitationToken(token)).Return(invitation, nil)
+
+	// Execute the usecase with another email.
+	_, err = acceptInvitationUsecase.Execute(&dto.AcceptInvitationRequestDTO{
+		Token:    token,
+		Email:    "paul.mccartney@example.com",
+		Password: "password",
+	})
+
+	// Assert that the invitation was not redeemed.
+	assert.Equal(t, usecase.ErrInvitationEmailMismatch, err)
+	assert.Equal(t, entities.InvitationPending, invitation.Status)
+}
+
+// TestAcceptInvitation_Expired verifies that expired invitations are marked as such.
+func TestAcceptInvitation_Expired(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+
+	mockTransactor := &repository.MockInvitationTransactor{Invitations: mockInvitations, Users: mockUsers, Events: mockEvents}
+
+	acceptInvitationUsecase := usecase.NewAcceptInvitationUsecase(mockInvitations, mockTransactor)
+
+	invitation, token, err := entities.NewInvitation("org-1", "john.lennon@example.com", "user", "admin-1")
+	assert.NoError(t, err)
+	invitation.ExpiresAt = time.Now().Add(-time.Hour)
+
+	mockInvitations.On("FindInvitationByTokenHash", entities.HashInvitationToken(token)).Return(invitation, nil)
+	mockInvitations.On("UpdateInvitation", mock.AnythingOfType("*entities.Invitation")).Return(nil)
+
+	// Execute the usecase.
+	_, err = acceptInvitationUsecase.Execute(&dto.AcceptInvitationRequestDTO{
+		Token: token,
+		Email: "john.lennon@example.com",
+	})
+
+	// Assert that the invitation is now expired.
+	assert.Equal(t, usecase.ErrInvitationExpired, err)
+	assert.Equal(t, entities.InvitationExpired, invitation.Status)
+}
+
+// TestAcceptInvitation_AcceptedConcurrently verifies that a token redeemed by
+// another request in the meantime fails the whole transaction.
+func TestAcceptInvitation_AcceptedConcurrently(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	mockTransactor := &repository.MockInvitationTransactor{Invitations: mockInvitations, Users: mockUsers, Events: mockEvents}
+
+	acceptInvitationUsecase := usecase.NewAcceptInvitationUsecase(mockInvitations, mockTransactor)
+
+	invitation, token, err := entities.NewInvitation("org-1", "peter.parker@example.com", "user", "admin-1")
+	assert.NoError(t, err)
+
+	mockInvitations.On("FindInvitationByTokenHash", entities.HashInvitationToken(token)).Return(invitation, nil)
+	mockInvitations.On("AddMember", mock.AnythingOfType("*entities.Membership")).Return(nil)
+	mockUsers.On("FindUserByEmail", "peter.parker@example.com").Return(&entities.User{}, nil)
+	mockUsers.On("CreateUser", mock.AnythingOfType("*entities.User")).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+	mockTransactor.On("WithinInvitationTransaction").Return(nil)
+
+	// The invitation stopped being pending after it was read.
+	mockInvitations.On("AcceptInvitation", invitation).Return(domain.ErrInvitationNotPending)
+
+	// Execute the usecase.
+	response, err := acceptInvitationUsecase.Execute(&dto.AcceptInvitationRequestDTO{
+		Token:     token,
+		Email:     "peter.parker@example.com",
+		FirstName: "Peter",
+		LastName:  "Parker",
+		Password:  "12345678",
+	})
+
+	// Assert that the error rolls the account back instead of returning it.
+	assert.Equal(t, usecase.ErrInvitationNotPending, err)
+	assert.Nil(t, response)
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+// MaxBatchGetUsers is the largest number of IDs a single batch lookup accepts.
+const MaxBatchGetUsers = 500
+
+var (
+	ErrIdsRequired = errcode.New(errcode.IdsRequired, "param: 'ids' is required, please try again")
+	ErrTooManyIds  = errcode.New(errcode.TooManyIds, fmt.Sprintf("too many ids, a batch accepts at most %d", MaxBatchGetUsers))
+)
+
+type BatchGetUsersUsecase struct {
+	repo domain.UserRepository
+}
+
+func NewBatchGetUsersUsecase(repo domain.UserRepository) *BatchGetUsersUsecase {
+	return &BatchGetUsersUsecase{repo: repo}
+}
+
+// Execute looks up all ids with one repository call. Users come back in the
+// order they were requested, duplicates removed, and every ID without an
+// active user is listed in missing.
+func (u *BatchGetUsersUsecase) Execute(ids []string) ([]*dto.UserResponseDTO, []string, error) {
+	if len(ids) == 0 {
+		return nil, nil, ErrIdsRequired
+	}
+
+	seen := make(map[string]bool, len(ids))
+	var requested []string
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			requested = append(requested, id)
+		}
+	}
+
+	if len(requested) == 0 {
+		return nil, nil, ErrIdsRequired
+	}
+
+	if len(requested) > MaxBatchGetUsers {
+		return nil, nil, ErrTooManyIds
+	}
+
+	users, err := u.repo.FindUsersByIds(requested)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byId := make(map[string]*dto.UserResponseDTO, len(users))
+	for _, user := range users {
+		byId[user.ID] = &dto.UserResponseDTO{
+			ID:          user.ID,
+			FirstName:   user.FirstName,
+			LastName:    user.LastName,
+			Email:       user.Email,
+			Role:        user.Role,
+			Region:      user.Region,
+			CreateAt:    user.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdateAt:    user.UpdatedAt.Format("2006-01-02 15:04:05"),
+			CreatedTime: user.CreatedAt,
+			UpdatedTime: user.UpdatedAt,
+			Version:     user.Version,
+		}
+	}
+
+	var (
+		found   []*dto.UserResponseDTO
+		missing []string
+	)
+	for _, id := range requested {
+		if user, ok := byId[id]; ok {
+			found = append(found, user)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	return found, missing, nil
+}
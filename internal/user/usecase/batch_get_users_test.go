@@ -0,0 +1,59 @@
+package usecase_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBatchGetUsers verifies that users come back in request order and that
+// unknown IDs are reported as missing.
+func TestBatchGetUsers(t *testing.T) {
+	// Create a new mock repository for the user repository.
+	mockRepo := new(repository.MockUserRepository)
+
+	batchGetUsersUsecase := usecase.NewBatchGetUsersUsecase(mockRepo)
+
+	// The repository returns the users in its own order; duplicates are
+	// removed before the query.
+	mockRepo.On("FindUsersByIds", []string{"2", "1", "3"}).Return([]*entities.User{
+		{ID: "1", FirstName: "John", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: "2", FirstName: "Paul", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}, nil)
+
+	// Execute the usecase.
+	users, missing, err := batchGetUsersUsecase.Execute([]string{"2", "1", "2", "3"})
+
+	// Assert the order of the users and the missing ID.
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "2", users[0].ID)
+	assert.Equal(t, "1", users[1].ID)
+	assert.Equal(t, []string{"3"}, missing)
+}
+
+// TestBatchGetUsers_Limits verifies the empty and oversized batches.
+func TestBatchGetUsers_Limits(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+
+	batchGetUsersUsecase := usecase.NewBatchGetUsersUsecase(mockRepo)
+
+	// An empty batch is rejected.
+	_, _, err := batchGetUsersUsecase.Execute(nil)
+	assert.Equal(t, usecase.ErrIdsRequired, err)
+
+	// A batch above the limit is rejected without touching the repository.
+	ids := make([]string, usecase.MaxBatchGetUsers+1)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+
+	_, _, err = batchGetUsersUsecase.Execute(ids)
+	assert.Equal(t, usecase.ErrTooManyIds, err)
+	mockRepo.AssertNotCalled(t, "FindUsersByIds")
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+// MaxBatchWriteOperations is the largest number of operations a single batch
+// write accepts.
+const MaxBatchWriteOperations = 500
+
+const (
+	BatchOpCreate = "create"
+	BatchOpPatch  = "patch"
+	BatchOpDelete = "delete"
+
+	// BatchModeAtomic applies every operation or none of them.
+	BatchModeAtomic = "atomic"
+	// BatchModeBestEffort applies every operation that succeeds on its own.
+	BatchModeBestEffort = "best_effort"
+)
+
+var (
+	ErrOperationsRequired = errcode.New(errcode.OperationsRequired, "param: 'operations' is required, please try again")
+	ErrTooManyOperations  = errcode.New(errcode.TooManyOperations, fmt.Sprintf("too many operations, a batch accepts at most %d", MaxBatchWriteOperations))
+	ErrInvalidBatchMode   = errcode.New(errcode.BatchModeInvalid, "invalid mode, use 'atomic' or 'best_effort'")
+	ErrInvalidBatchOp     = errcode.New(errcode.BatchOpInvalid, "invalid op, use 'create', 'patch' or 'delete'")
+	ErrBatchOpIdRequired  = errcode.New(errcode.BatchOpIDRequired, "param: 'id' is required, please try again")
+	ErrBatchOpForbidden   = errcode.New(errcode.BatchOpForbidden, "you are not allowed to perform this operation")
+	ErrBatchOpNotApplied  = errcode.New(errcode.BatchOpNotApplied, "not applied, another operation of the batch failed")
+)
+
+// BatchAuthorizer reports whether an operation may be applied to its target
+// user. For creates, the target only has its email and role set.
+type BatchAuthorizer func(op string, target *dto.UserResponseDTO) bool
+
+// BatchWriteResult is the outcome of one operation of a batch. User is the
+// user after the operation, or before it for deletes.
+type BatchWriteResult struct {
+	User *dto.UserResponseDTO
+	Err  error
+}
+
+type BatchWriteUsersUsecase struct {
+	repo       domain.UserRepository
+	transactor domain.UserTransactor
+}
+
+func NewBatchWriteUsersUsecase(repo domain.UserRepository, transactor domain.UserTransactor) *BatchWriteUsersUsecase {
+	return &BatchWriteUsersUsecase{repo: repo, transactor: transactor}
+}
+
+// Execute applies the operations in order, with the same rules as the
+// single-user usecases, and returns one result per operation.
+//
+// In atomic mode, the default, the operations share one transaction. The
+// first failure rolls it back, and every other operation fails with
+// ErrBatchOpNotApplied. In best-effort mode, each operation is applied in a
+// transaction of its own. authorize may be nil to allow every operation.
+func (u *BatchWriteUsersUsecase) Execute(request *dto.BatchWriteRequestDTO, authorize BatchAuthorizer) ([]BatchWriteResult, error) {
+	if len(request.Operations) == 0 {
+		return nil, ErrOperationsRequired
+	}
+
+	if len(request.Operations) > MaxBatchWriteOperations {
+		return nil, ErrTooManyOperations
+	}
+
+	results := make([]BatchWriteResult, len(request.Operations))
+
+	switch request.Mode {
+	case BatchModeBestEffort:
+		for i, operation := range request.Operations {
+			results[i] = applyBatchOperation(u.repo, u.transactor, operation, authorize)
+		}
+
+		return results, nil
+
+	case BatchModeAtomic, "":
+		failed := -1
+
+		err := u.transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+			for i, operation := range request.Operations {
+				results[i] = applyBatchOperation(users, inTransaction{users, events}, operation, authorize)
+				if results[i].Err != nil {
+					failed = i
+					return results[i].Err
+				}
+			}
+
+			return nil
+		})
+		if err == nil {
+			return results, nil
+		}
+
+		// The commit itself failed.
+		if failed < 0 {
+			return nil, err
+		}
+
+		for i := range results {
+			if i != failed {
+				results[i] = BatchWriteResult{Err: ErrBatchOpNotApplied}
+			}
+		}
+
+		return results, nil
+	}
+
+	return nil, ErrInvalidBatchMode
+}
+
+// IsBatchWriteRequestError reports whether err is about the batch itself
+// rather than about one of its operations.
+func IsBatchWriteRequestError(err error) bool {
+	return err == ErrOperationsRequired || err == ErrTooManyOperations || err == ErrInvalidBatchMode
+}
+
+func applyBatchOperation(users domain.UserRepository, transactor domain.UserTransactor, operation dto.BatchOperationDTO, authorize BatchAuthorizer) BatchWriteResult {
+	request := operation.User
+	if request == nil {
+		request = &dto.UserRequestDTO{}
+	}
+
+	var target *dto.UserResponseDTO
+
+	switch operation.Op {
+	case BatchOpCreate:
+		target = &dto.UserResponseDTO{Email: request.Email, Role: "user", Region: request.Region}
+
+	case BatchOpPatch, BatchOpDelete:
+		if operation.ID == "" {
+			return BatchWriteResult{Err: ErrBatchOpIdRequired}
+		}
+
+		if operation.Op == BatchOpPatch && request.FirstName == "" && request.LastName == "" && request.Email == "" && request.Region == "" {
+			return BatchWriteResult{Err: entities.ErrAtLeastOneParam}
+		}
+
+		if authorize != nil {
+			user, err := users.FindUserById(operation.ID)
+			if err != nil {
+				return BatchWriteResult{Err: err}
+			}
+			if user == nil {
+				return BatchWriteResult{Err: ErrUserNotFound}
+			}
+			target = replacedUserResponse(user)
+		}
+
+	default:
+		return BatchWriteResult{Err: ErrInvalidBatchOp}
+	}
+
+	if authorize != nil && !authorize(operation.Op, target) {
+		return BatchWriteResult{Err: ErrBatchOpForbidden}
+	}
+
+	var (
+		user *dto.UserResponseDTO
+		err  error
+	)
+
+	switch operation.Op {
+	case BatchOpCreate:
+		user, err = NewCreateUserUsecase(transactor).Execute(request)
+	case BatchOpPatch:
+		// A patch is an update of its non-empty fields, validated the same
+		// way, an email owned by another user included.
+		user, err = NewUpdateUserUsecase(transactor).Execute(&entities.User{
+			ID:        operation.ID,
+			FirstName: request.FirstName,
+			LastName:  request.LastName,
+			Email:     request.Email,
+			Region:    request.Region,
+			Version:   operation.Version,
+		}, nil)
+	case BatchOpDelete:
+		user, err = NewDeleteUserUsecase(transactor).Execute(operation.ID, operation.Version)
+	}
+
+	return BatchWriteResult{User: user, Err: err}
+}
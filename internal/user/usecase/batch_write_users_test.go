@@ -0,0 +1,192 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newBatchWriteUsecase() (*usecase.BatchWriteUsersUsecase, *repository.MockUserRepository, *repository.MockUserEventRepository, *repository.MockUserTransactor) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	mockTransactor := &repository.MockUserTransactor{Users: mockRepo, Events: mockEvents}
+
+	return usecase.NewBatchWriteUsersUsecase(mockRepo, mockTransactor), mockRepo, mockEvents, mockTransactor
+}
+
+var batchNewUser = &dto.UserRequestDTO{
+	FirstName: "George",
+	LastName:  "Harrison",
+	Email:     "george.harrison@example.com",
+	Password:  "password123",
+}
+
+// TestBatchWriteUsers_Atomic verifies that every operation of an atomic batch
+// runs in one transaction.
+func TestBatchWriteUsers_Atomic(t *testing.T) {
+	batchWrite, mockRepo, mockEvents, mockTransactor := newBatchWriteUsecase()
+
+	mockTransactor.On("WithinTransaction").Return(nil)
+	mockRepo.On("FindUserByEmail", batchNewUser.Email).Return(&entities.User{}, nil)
+	mockRepo.On("CreateUser", mock.AnythingOfType("*entities.User")).Return(nil)
+	mockRepo.On("FindUserById", "1").Return(&entities.User{ID: "1", FirstName: "John", Version: 1}, nil)
+	mockRepo.On("DeleteUser", "1", int64(1)).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	results, err := batchWrite.Execute(&dto.BatchWriteRequestDTO{
+		Mode: usecase.BatchModeAtomic,
+		Operations: []dto.BatchOperationDTO{
+			{Op: usecase.BatchOpCreate, User: batchNewUser},
+			{Op: usecase.BatchOpDelete, ID: "1"},
+		},
+	}, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "George", results[0].User.FirstName)
+	assert.Nil(t, results[1].Err)
+	assert.Equal(t, "1", results[1].User.ID)
+	mockTransactor.AssertExpectations(t)
+}
+
+// TestBatchWriteUsers_AtomicFailure verifies that one failure rolls back the
+// whole batch.
+func TestBatchWriteUsers_AtomicFailure(t *testing.T) {
+	batchWrite, mockRepo, mockEvents, mockTransactor := newBatchWriteUsecase()
+
+	mockTransactor.On("WithinTransaction").Return(nil)
+	mockRepo.On("FindUserByEmail", mock.AnythingOfType("string")).Return(&entities.User{}, nil)
+	mockRepo.On("CreateUser", mock.AnythingOfType("*entities.User")).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	results, err := batchWrite.Execute(&dto.BatchWriteRequestDTO{
+		Operations: []dto.BatchOperationDTO{
+			{Op: usecase.BatchOpCreate, User: batchNewUser},
+			{Op: usecase.BatchOpCreate, User: &dto.UserRequestDTO{FirstName: "Ringo", Email: "ringo@example.com"}},
+			{Op: usecase.BatchOpDelete, ID: "1"},
+		},
+	}, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, usecase.ErrBatchOpNotApplied, results[0].Err)
+	assert.True(t, entities.IsValidationError(results[1].Err))
+	assert.Equal(t, usecase.ErrBatchOpNotApplied, results[2].Err)
+	mockRepo.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+}
+
+// TestBatchWriteUsers_CommitFailure verifies that a failed commit fails the
+// whole request.
+func TestBatchWriteUsers_CommitFailure(t *testing.T) {
+	batchWrite, mockRepo, mockEvents, mockTransactor := newBatchWriteUsecase()
+
+	commitErr := errors.New("commit failed")
+	mockTransactor.On("WithinTransaction").Return(commitErr)
+	mockRepo.On("FindUserById", "1").Return(&entities.User{ID: "1", Version: 1}, nil)
+	mockRepo.On("DeleteUser", "1", int64(1)).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	_, err := batchWrite.Execute(&dto.BatchWriteRequestDTO{
+		Operations: []dto.BatchOperationDTO{{Op: usecase.BatchOpDelete, ID: "1"}},
+	}, nil)
+
+	assert.Equal(t, commitErr, err)
+}
+
+// TestBatchWriteUsers_BestEffort verifies that failed operations do not stop
+// the others, and that they are denied when the authorizer says so.
+func TestBatchWriteUsers_BestEffort(t *testing.T) {
+	batchWrite, mockRepo, mockEvents, mockTransactor := newBatchWriteUsecase()
+
+	mockRepo.On("FindUserById", "1").Return(&entities.User{ID: "1", Email: "john@example.com", Role: "user", Version: 2}, nil)
+	mockRepo.On("FindUserById", "2").Return(&entities.User{ID: "2", Role: "admin", Version: 1}, nil)
+	mockRepo.On("FindUserById", "3").Return((*entities.User)(nil), nil)
+	mockRepo.On("UpdateUser", mock.AnythingOfType("*entities.User")).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+	mockTransactor.On("WithinTransaction").Return(nil)
+
+	// Admins can only be changed by other admins.
+	authorize := func(op string, target *dto.UserResponseDTO) bool {
+		return target.Role != "admin"
+	}
+
+	results, err := batchWrite.Execute(&dto.BatchWriteRequestDTO{
+		Mode: usecase.BatchModeBestEffort,
+		Operations: []dto.BatchOperationDTO{
+			{Op: usecase.BatchOpPatch, ID: "1", Version: 2, User: &dto.UserRequestDTO{FirstName: "Paul"}},
+			{Op: usecase.BatchOpDelete, ID: "2"},
+			{Op: usecase.BatchOpDelete, ID: "3"},
+			{Op: usecase.BatchOpPatch, ID: "1"},
+			{Op: "upsert"},
+		},
+	}, authorize)
+
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "Paul", results[0].User.FirstName)
+	assert.Equal(t, usecase.ErrBatchOpForbidden, results[1].Err)
+	assert.Equal(t, usecase.ErrUserNotFound, results[2].Err)
+	assert.Equal(t, entities.ErrAtLeastOneParam, results[3].Err)
+	assert.Equal(t, usecase.ErrInvalidBatchOp, results[4].Err)
+	mockRepo.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+
+	// Each applied operation has a transaction of its own.
+	mockTransactor.AssertNumberOfCalls(t, "WithinTransaction", 1)
+}
+
+// TestBatchWriteUsers_PatchValidation verifies that patches are validated
+// like single-user updates, each failure reported as the error of its
+// operation.
+func TestBatchWriteUsers_PatchValidation(t *testing.T) {
+	batchWrite, mockRepo, mockEvents, mockTransactor := newBatchWriteUsecase()
+
+	mockRepo.On("FindUserById", "1").Return(&entities.User{ID: "1", FirstName: "John", Email: "john@example.com", Role: "user", Version: 1}, nil)
+	mockRepo.On("FindUserByEmail", "ringo@example.com").Return(&entities.User{ID: "2", Email: "ringo@example.com"}, nil)
+	mockRepo.On("FindUserByEmail", "paul@example.com").Return(&entities.User{}, nil)
+	mockRepo.On("UpdateUser", mock.AnythingOfType("*entities.User")).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+	mockTransactor.On("WithinTransaction").Return(nil)
+
+	results, err := batchWrite.Execute(&dto.BatchWriteRequestDTO{
+		Mode: usecase.BatchModeBestEffort,
+		Operations: []dto.BatchOperationDTO{
+			{Op: usecase.BatchOpPatch, ID: "1", User: &dto.UserRequestDTO{Email: "not-an-email"}},
+			{Op: usecase.BatchOpPatch, ID: "1", User: &dto.UserRequestDTO{FirstName: "Jo"}},
+			{Op: usecase.BatchOpPatch, ID: "1", User: &dto.UserRequestDTO{Email: "ringo@example.com"}},
+			{Op: usecase.BatchOpPatch, ID: "1", User: &dto.UserRequestDTO{Email: "paul@example.com"}},
+		},
+	}, nil)
+
+	assert.Nil(t, err)
+	assert.True(t, entities.IsValidationError(results[0].Err))
+	assert.Equal(t, entities.ErrFirstNameTooShort, results[1].Err)
+	assert.Equal(t, usecase.ErrEmailAlreadyExists, results[2].Err)
+	assert.Nil(t, results[3].Err)
+	assert.Equal(t, "paul@example.com", results[3].User.Email)
+	mockRepo.AssertNumberOfCalls(t, "UpdateUser", 1)
+}
+
+// TestBatchWriteUsers_InvalidRequest verifies the limits on the batch itself.
+func TestBatchWriteUsers_InvalidRequest(t *testing.T) {
+	batchWrite, _, _, _ := newBatchWriteUsecase()
+
+	_, err := batchWrite.Execute(&dto.BatchWriteRequestDTO{}, nil)
+	assert.Equal(t, usecase.ErrOperationsRequired, err)
+
+	_, err = batchWrite.Execute(&dto.BatchWriteRequestDTO{
+		Operations: make([]dto.BatchOperationDTO, usecase.MaxBatchWriteOperations+1),
+	}, nil)
+	assert.Equal(t, usecase.ErrTooManyOperations, err)
+
+	_, err = batchWrite.Execute(&dto.BatchWriteRequestDTO{
+		Mode:       "eventually",
+		Operations: []dto.BatchOperationDTO{{Op: usecase.BatchOpDelete, ID: "1"}},
+	}, nil)
+	assert.Equal(t, usecase.ErrInvalidBatchMode, err)
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+var (
+	ErrInvitationForbidden      = errcode.New(errcode.InvitationForbidden, "only admins can manage invitations")
+	ErrInvitationAlreadyPending = errcode.New(errcode.InvitationAlreadyPending, "there is already a pending invitation for this email in this organization")
+)
+
+type CreateInvitationUsecase struct {
+	invitations domain.InvitationRepository
+	users       domain.UserRepository
+}
+
+func NewCreateInvitationUsecase(invitations domain.InvitationRepository, users domain.UserRepository) *CreateInvitationUsecase {
+	return &CreateInvitationUsecase{invitations: invitations, users: users}
+}
+
+func (u *CreateInvitationUsecase) Execute(request *dto.InvitationRequestDTO) (*dto.InvitationResponseDTO, error) {
+	if err := requireAdmin(u.users, request.InvitedBy); err != nil {
+		return nil, err
+	}
+
+	invitation, token, err := entities.NewInvitation(
+		request.OrganizationID,
+		request.Email,
+		request.Role,
+		request.InvitedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := u.invitations.FindPendingInvitation(invitation.OrganizationID, invitation.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending != nil && !pending.IsExpired(time.Now()) {
+		return nil, ErrInvitationAlreadyPending
+	}
+
+	err = u.invitations.CreateInvitation(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInvitationResponse(invitation, token), nil
+}
+
+// requireAdmin makes sure the user acting on an invitation is an admin.
+func requireAdmin(users domain.UserRepository, id string) error {
+	if id == "" {
+		return ErrInvitationForbidden
+	}
+
+	user, err := users.FindUserById(id)
+	if err == ErrUserNotFound {
+		return ErrInvitationForbidden
+	}
+	if err != nil {
+		return err
+	}
+
+	if user == nil || !user.IsAdmin() {
+		return ErrInvitationForbidden
+	}
+
+	return nil
+}
+
+func newInvitationResponse(invitation *entities.Invitation, token string) *dto.InvitationResponseDTO {
+	return &dto.InvitationResponseDTO{
+		ID:             invitation.ID,
+		OrganizationID: invitation.OrganizationID,
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		Status:         invitation.Status,
+		Token:          token,
+		ExpiresAt:      invitation.ExpiresAt.Format("2006-01-02 15:04:05"),
+		CreateAt:       invitation.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdateAt:       invitation.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,74 @@
+package usecase_test
+
+import (
+	"testing"
+
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestCreateInvitation tests the CreateInvitation usecase.
+// It verifies if an admin can invite an email and receives the raw token once.
+func TestCreateInvitation(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+
+	// Create a new CreateInvitationUsecase with the mock repositories.
+	createInvitationUsecase := usecase.NewCreateInvitationUsecase(mockInvitations, mockUsers)
+
+	// The inviter is an admin.
+	mockUsers.On("FindUserById", "admin-1").Return(&entities.User{ID: "admin-1", Role: "admin"}, nil)
+
+	// There is no pending invitation for this email yet.
+	mockInvitations.On("FindPendingInvitation", "org-1", "peter.parker@example.com").Return((*entities.Invitation)(nil), nil)
+	mockInvitations.On("CreateInvitation", mock.AnythingOfType("*entities.Invitation")).Return(nil)
+
+	// Execute the usecase.
+	response, err := createInvitationUsecase.Execute(&dto.InvitationRequestDTO{
+		OrganizationID: "org-1",
+		Email:          "peter.parker@example.com",
+		Role:           "user",
+		InvitedBy:      "admin-1",
+	})
+
+	// Assert that the invitation was created with a token that is not the stored hash.
+	assert.NoError(t, err)
+	assert.Equal(t, entities.InvitationPending, response.Status)
+	assert.NotEmpty(t, response.Token)
+
+	stored := mockInvitations.Calls[1].Arguments.Get(0).(*entities.Invitation)
+	assert.Equal(t, entities.HashInvitationToken(response.Token), stored.TokenHash)
+
+	// Assert that all expected methods of the mock repositories were called.
+	mockInvitations.AssertExpectations(t)
+	mockUsers.AssertExpectations(t)
+}
+
+// TestCreateInvitation_NotAdmin verifies that regular users cannot invite.
+func TestCreateInvitation_NotAdmin(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+
+	createInvitationUsecase := usecase.NewCreateInvitationUsecase(mockInvitations, mockUsers)
+
+	// The inviter is a regular user.
+	mockUsers.On("FindUserById", "user-1").Return(&entities.User{ID: "user-1", Role: "user"}, nil)
+
+	// Execute the usecase.
+	_, err := createInvitationUsecase.Execute(&dto.InvitationRequestDTO{
+		OrganizationID: "org-1",
+		Email:          "peter.parker@example.com",
+		Role:           "admin",
+		InvitedBy:      "user-1",
+	})
+
+	// Assert that the request was refused before touching the invitations.
+	assert.Equal(t, usecase.ErrInvitationForbidden, err)
+	mockInvitations.AssertNotCalled(t, "CreateInvitation", mock.Anything)
+}
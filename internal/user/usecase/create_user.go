@@ -1,58 +1,70 @@
 package usecase
 
 import (
-	"errors"
-
 	"github.com/jonattasmoraes/titan/internal/user/domain"
 	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
 	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
 )
 
-var ErrEmailAlreadyExists = errors.New("user with this email already exists, please try a different email")
+var ErrEmailAlreadyExists = errcode.New(errcode.EmailTaken, "user with this email already exists, please try a different email")
 
 type CreateUserUsecase struct {
-	repo domain.UserRepository
+	transactor domain.UserTransactor
 }
 
-func NewCreateUserUsecase(repo domain.UserRepository) *CreateUserUsecase {
-	return &CreateUserUsecase{repo: repo}
+func NewCreateUserUsecase(transactor domain.UserTransactor) *CreateUserUsecase {
+	return &CreateUserUsecase{transactor: transactor}
 }
 
+// Execute creates the user and records the event of its creation in the same
+// transaction.
 func (u *CreateUserUsecase) Execute(user *dto.UserRequestDTO) (*dto.UserResponseDTO, error) {
-	userExists, err := u.repo.FindUserByEmail(user.Email)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if userExists != nil && userExists.Email == user.Email {
-		return nil, ErrEmailAlreadyExists
-	}
-
 	createdUser, err := entities.NewUser(
 		user.FirstName,
 		user.LastName,
 		user.Email,
 		user.Password,
+		user.Region,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	newUser := &dto.UserResponseDTO{
-		ID:        createdUser.ID,
-		FirstName: createdUser.FirstName,
-		LastName:  createdUser.LastName,
-		Email:     createdUser.Email,
-		Role:      createdUser.Role,
-		CreateAt:  createdUser.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdateAt:  createdUser.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:          createdUser.ID,
+		FirstName:   createdUser.FirstName,
+		LastName:    createdUser.LastName,
+		Email:       createdUser.Email,
+		Role:        createdUser.Role,
+		Region:      createdUser.Region,
+		CreateAt:    createdUser.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdateAt:    createdUser.UpdatedAt.Format("2006-01-02 15:04:05"),
+		CreatedTime: createdUser.CreatedAt,
+		UpdatedTime: createdUser.UpdatedAt,
+		Version:     createdUser.Version,
 	}
 
-	err = u.repo.CreateUser(createdUser)
+	err = u.transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+		userExists, err := users.FindUserByEmail(user.Email)
+		if err != nil {
+			return err
+		}
+
+		if userExists != nil && userExists.Email == user.Email {
+			return ErrEmailAlreadyExists
+		}
+
+		err = users.CreateUser(createdUser)
+		if err != nil {
+			return err
+		}
+
+		return events.AppendUserEvent(entities.NewUserEvent(entities.UserCreated, createdUser))
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return newUser, err
+	return newUser, nil
 }
@@ -1,6 +1,7 @@
 package usecase_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -18,8 +19,11 @@ func TestCreateUser(t *testing.T) {
 	// Create a new mock repository for the user repository.
 	mockRepo := new(repository.MockUserRepository)
 
-	// Create a new CreateUserUsecase with the mock repository.
-	createUserUsecase := usecase.NewCreateUserUsecase(mockRepo)
+	// Create a new mock repository for the change log.
+	mockEvents := new(repository.MockUserEventRepository)
+
+	// Create a new CreateUserUsecase with the mock repositories.
+	createUserUsecase := usecase.NewCreateUserUsecase(transactorFor(mockRepo, mockEvents))
 
 	// Mock the FindUserByEmail method of the mock repository to return a predefined user.
 	mockRepo.On("FindUserByEmail", mock.AnythingOfType("string")).Return(
@@ -37,6 +41,9 @@ func TestCreateUser(t *testing.T) {
 	// Mock the CreateUser method of the mock repository to return no error.
 	mockRepo.On("CreateUser", mock.AnythingOfType("*entities.User")).Return(nil)
 
+	// Mock the AppendUserEvent method of the change log to return no error.
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
 	// Define the user DTO to be passed to the usecase.
 	response := &dto.UserRequestDTO{
 		FirstName: "Peter",
@@ -56,6 +63,50 @@ func TestCreateUser(t *testing.T) {
 	assert.Equal(t, userDTO.LastName, response.LastName)
 	assert.Equal(t, userDTO.Email, response.Email)
 
-	// Assert that all expected methods of the mock repository were called.
+	// Assert that a created event without the password was recorded.
+	event := mockEvents.Calls[0].Arguments.Get(0).(*entities.UserEvent)
+	assert.Equal(t, entities.UserCreated, event.Type)
+	assert.Empty(t, event.User.Password)
+
+	// Assert that all expected methods of the mock repositories were called.
 	mockRepo.AssertExpectations(t)
+	mockEvents.AssertExpectations(t)
+}
+
+// TestCreateUser_EventFails verifies that the user is not created when its
+// event cannot be recorded, as both are written in one transaction.
+func TestCreateUser_EventFails(t *testing.T) {
+	// Create the mock repositories and a transactor over them.
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	mockTransactor := transactorFor(mockRepo, mockEvents)
+
+	createUserUsecase := usecase.NewCreateUserUsecase(mockTransactor)
+
+	appendErr := errors.New("change log unavailable")
+	mockRepo.On("FindUserByEmail", "peter.parker@example.com").Return(&entities.User{}, nil)
+	mockRepo.On("CreateUser", mock.AnythingOfType("*entities.User")).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(appendErr)
+
+	// Execute the usecase.
+	userDTO, err := createUserUsecase.Execute(&dto.UserRequestDTO{
+		FirstName: "Peter",
+		LastName:  "Parker",
+		Email:     "peter.parker@example.com",
+		Password:  "12345678",
+	})
+
+	// Assert that the failure reached the transaction, which rolls back the user.
+	assert.Equal(t, appendErr, err)
+	assert.Nil(t, userDTO)
+	mockTransactor.AssertNumberOfCalls(t, "WithinTransaction", 1)
+}
+
+// transactorFor is a MockUserTransactor over users and events whose
+// transactions commit.
+func transactorFor(users *repository.MockUserRepository, events *repository.MockUserEventRepository) *repository.MockUserTransactor {
+	transactor := &repository.MockUserTransactor{Users: users, Events: events}
+	transactor.On("WithinTransaction").Return(nil)
+
+	return transactor
 }
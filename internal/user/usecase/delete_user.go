@@ -3,40 +3,60 @@ package usecase
 import (
 	"github.com/jonattasmoraes/titan/internal/user/domain"
 	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
 )
 
 type DeleteUserUsecase struct {
-	repo domain.UserRepository
+	transactor domain.UserTransactor
 }
 
-func NewDeleteUserUsecase(repo domain.UserRepository) *DeleteUserUsecase {
-	return &DeleteUserUsecase{repo: repo}
+func NewDeleteUserUsecase(transactor domain.UserTransactor) *DeleteUserUsecase {
+	return &DeleteUserUsecase{transactor: transactor}
 }
 
-func (u *DeleteUserUsecase) Execute(id string) (*dto.UserResponseDTO, error) {
-	user, err := u.repo.FindUserById(id)
+// Execute soft deletes the user and records the event of its deletion in the
+// same transaction. When version is set, the user is only deleted at that
+// version.
+func (u *DeleteUserUsecase) Execute(id string, version int64) (*dto.UserResponseDTO, error) {
+	var response *dto.UserResponseDTO
+
+	err := u.transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+		user, err := users.FindUserById(id)
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return ErrUserNotFound
+		}
+
+		if version != 0 && version != user.Version {
+			return ErrVersionMismatch
+		}
+
+		response = &dto.UserResponseDTO{
+			ID:          user.ID,
+			FirstName:   user.FirstName,
+			LastName:    user.LastName,
+			Email:       user.Email,
+			Role:        user.Role,
+			Region:      user.Region,
+			CreateAt:    user.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdateAt:    user.UpdatedAt.Format("2006-01-02 15:04:05"),
+			CreatedTime: user.CreatedAt,
+			UpdatedTime: user.UpdatedAt,
+		}
+
+		err = users.DeleteUser(user.ID, user.Version)
+		if err != nil {
+			return err
+		}
+
+		return events.AppendUserEvent(entities.NewUserEvent(entities.UserDeleted, user))
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if user == nil {
-		return nil, ErrUserNotFound
-	}
-
-	response := &dto.UserResponseDTO{
-		ID:        user.ID,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreateAt:  user.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdateAt:  user.UpdatedAt.Format("2006-01-02 15:04:05"),
-	}
-
-	err = u.repo.DeleteUser(user.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	return response, err
+	return response, nil
 }
@@ -16,8 +16,11 @@ func TestDeleteUser(t *testing.T) {
 	// Create a mock UserRepository.
 	mockRepo := new(repository.MockUserRepository)
 
-	// Create a new DeleteUserUsecase with the mock repository.
-	deleteUserUsecase := usecase.NewDeleteUserUsecase(mockRepo)
+	// Create a mock change log.
+	mockEvents := new(repository.MockUserEventRepository)
+
+	// Create a new DeleteUserUsecase with the mock repositories.
+	deleteUserUsecase := usecase.NewDeleteUserUsecase(transactorFor(mockRepo, mockEvents))
 
 	// Set up the mock repository to return a User entity when FindUserById is called.
 	mockRepo.On("FindUserById", mock.AnythingOfType("string")).Return(&entities.User{
@@ -25,14 +28,51 @@ func TestDeleteUser(t *testing.T) {
 	}, nil)
 
 	// Set up the mock repository to return nil when DeleteUser is called.
-	mockRepo.On("DeleteUser", mock.AnythingOfType("string")).Return(nil)
+	mockRepo.On("DeleteUser", mock.AnythingOfType("string"), int64(0)).Return(nil)
+
+	// Set up the change log to accept the deleted event.
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
 
 	// Execute the DeleteUser use case with the ID "1".
-	_, err := deleteUserUsecase.Execute("1")
+	_, err := deleteUserUsecase.Execute("1", 0)
 
 	// Assert that there is no error.
 	assert.Nil(t, err)
 
-	// Assert that all expectations set on the mock repository have been met.
+	// Assert that all expectations set on the mock repositories have been met.
 	mockRepo.AssertExpectations(t)
+	mockEvents.AssertExpectations(t)
+}
+
+// TestDeleteUser_VersionMismatch verifies that a user is not deleted when it
+// is no longer at the expected version.
+func TestDeleteUser_VersionMismatch(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	deleteUserUsecase := usecase.NewDeleteUserUsecase(transactorFor(mockRepo, mockEvents))
+
+	// The user was changed since version 2 was read.
+	mockRepo.On("FindUserById", "1").Return(&entities.User{ID: "1", Version: 3}, nil)
+
+	_, err := deleteUserUsecase.Execute("1", 2)
+
+	assert.Equal(t, usecase.ErrVersionMismatch, err)
+	mockRepo.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+}
+
+// TestDeleteUser_WritesAtReadVersion verifies that the delete is made at the
+// version that was read, so a concurrent change is not lost.
+func TestDeleteUser_WritesAtReadVersion(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	deleteUserUsecase := usecase.NewDeleteUserUsecase(transactorFor(mockRepo, mockEvents))
+
+	// The user changes between the read and the delete.
+	mockRepo.On("FindUserById", "1").Return(&entities.User{ID: "1", Version: 3}, nil)
+	mockRepo.On("DeleteUser", "1", int64(3)).Return(usecase.ErrVersionMismatch)
+
+	_, err := deleteUserUsecase.Execute("1", 0)
+
+	assert.Equal(t, usecase.ErrVersionMismatch, err)
+	mockEvents.AssertNotCalled(t, "AppendUserEvent", mock.Anything)
 }
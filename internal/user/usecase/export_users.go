@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+// ExportUsersRequest selects the users to export with the same filter and
+// sort as ListUsersRequest, without pagination.
+type ExportUsersRequest struct {
+	Filter domain.UserFilter
+	Sort   string
+	Order  string
+}
+
+type ExportUsersUsecase struct {
+	repo domain.UserRepository
+}
+
+func NewExportUsersUsecase(repo domain.UserRepository) *ExportUsersUsecase {
+	return &ExportUsersUsecase{repo: repo}
+}
+
+// Execute calls fn with every matching user, one at a time, as they are read
+// from the repository. The request is checked before fn is first called, so
+// an error returned without any call to fn is a request or repository error.
+// The first error returned by fn stops the export and is returned.
+func (u *ExportUsersUsecase) Execute(request ExportUsersRequest, fn func(user *dto.UserResponseDTO) error) error {
+	if err := validateUserFilter(request.Filter); err != nil {
+		return err
+	}
+
+	sortBy, descending, err := parseUserSort(request.Sort, request.Order)
+	if err != nil {
+		return err
+	}
+
+	query := domain.ListUsersQuery{Filter: request.Filter, SortBy: sortBy, Descending: descending}
+
+	return u.repo.ExportUsers(query, func(user *entities.User) error {
+		return fn(listedUserResponse(user))
+	})
+}
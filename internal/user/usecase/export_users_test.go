@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestExportUsers verifies that every matching user is passed on with the
+// requested filter and sort.
+func TestExportUsers(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	exportUsers := usecase.NewExportUsersUsecase(mockRepo)
+
+	deletedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	users := []*entities.User{
+		{ID: "1", FirstName: "John", Email: "john@example.com", Password: "secret", Role: "user"},
+		{ID: "2", FirstName: "Paul", Email: "paul@example.com", Role: "admin", DeletedAt: deletedAt},
+	}
+
+	mockRepo.On("ExportUsers", domain.ListUsersQuery{
+		Filter:     domain.UserFilter{EmailDomain: "example.com", Deleted: domain.IncludeDeleted},
+		SortBy:     domain.SortByEmail,
+		Descending: true,
+	}).Return(users, nil)
+
+	var exported []*dto.UserResponseDTO
+	err := exportUsers.Execute(usecase.ExportUsersRequest{
+		Filter: domain.UserFilter{EmailDomain: "example.com", Deleted: domain.IncludeDeleted},
+		Sort:   "email",
+		Order:  "desc",
+	}, func(user *dto.UserResponseDTO) error {
+		exported = append(exported, user)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, exported, 2)
+	assert.Equal(t, "John", exported[0].FirstName)
+	assert.Empty(t, exported[0].DeleteAt)
+	assert.Equal(t, "2024-06-01 12:00:00", exported[1].DeleteAt)
+}
+
+// TestExportUsers_Errors verifies that invalid requests are rejected before
+// the repository is read, and that errors of the callback stop the export.
+func TestExportUsers_Errors(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	exportUsers := usecase.NewExportUsersUsecase(mockRepo)
+
+	ignore := func(*dto.UserResponseDTO) error { return nil }
+
+	err := exportUsers.Execute(usecase.ExportUsersRequest{Filter: domain.UserFilter{Role: "owner"}}, ignore)
+	assert.Equal(t, usecase.ErrInvalidRoleFilter, err)
+
+	err = exportUsers.Execute(usecase.ExportUsersRequest{Sort: "password"}, ignore)
+	assert.ErrorIs(t, err, usecase.ErrInvalidSortField)
+
+	err = exportUsers.Execute(usecase.ExportUsersRequest{Order: "up"}, ignore)
+	assert.Equal(t, usecase.ErrInvalidSortOrder, err)
+
+	mockRepo.AssertNotCalled(t, "ExportUsers", mock.Anything)
+
+	mockRepo.On("ExportUsers", mock.AnythingOfType("domain.ListUsersQuery")).
+		Return([]*entities.User{{ID: "1"}, {ID: "2"}}, nil)
+
+	writeErr := errors.New("client went away")
+	calls := 0
+	err = exportUsers.Execute(usecase.ExportUsersRequest{}, func(*dto.UserResponseDTO) error {
+		calls++
+		return writeErr
+	})
+	assert.Equal(t, writeErr, err)
+	assert.Equal(t, 1, calls)
+}
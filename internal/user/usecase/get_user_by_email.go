@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+)
+
+type GetUserByEmailUsecase struct {
+	repo domain.UserRepository
+}
+
+func NewGetUserByEmailUsecase(repo domain.UserRepository) *GetUserByEmailUsecase {
+	return &GetUserByEmailUsecase{repo: repo}
+}
+
+func (u *GetUserByEmailUsecase) Execute(email string) (*dto.UserResponseDTO, error) {
+	user, err := u.repo.FindUserByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.ID == "" {
+		return nil, ErrUserNotFound
+	}
+
+	userDTO := &dto.UserResponseDTO{
+		ID:          user.ID,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Email:       user.Email,
+		Role:        user.Role,
+		Region:      user.Region,
+		CreateAt:    user.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdateAt:    user.UpdatedAt.Format("2006-01-02 15:04:05"),
+		CreatedTime: user.CreatedAt,
+		UpdatedTime: user.UpdatedAt,
+		Version:     user.Version,
+	}
+
+	return userDTO, nil
+}
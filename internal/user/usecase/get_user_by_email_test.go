@@ -0,0 +1,61 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetUserByEmail tests the GetUserByEmail usecase.
+// It verifies if the usecase returns the user registered with the provided email.
+func TestGetUserByEmail(t *testing.T) {
+	// Create a new mock repository for the user repository.
+	mockRepo := new(repository.MockUserRepository)
+
+	// Create a new GetUserByEmailUsecase with the mock repository.
+	getUserByEmailUsecase := usecase.NewGetUserByEmailUsecase(mockRepo)
+
+	user := &entities.User{
+		ID:        ulid.Make().String(),
+		FirstName: "John",
+		LastName:  "Lennon",
+		Email:     "john.lennon@example.com",
+		Password:  "password",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	// Mock the FindUserByEmail method of the mock repository to return the user.
+	mockRepo.On("FindUserByEmail", user.Email).Return(user, nil)
+
+	// Execute the GetUserByEmailUsecase with the user email.
+	output, err := getUserByEmailUsecase.Execute(user.Email)
+
+	// Verify if the returned user is equal to the expected user.
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, output.ID)
+	assert.Equal(t, user.Email, output.Email)
+}
+
+// TestGetUserByEmail_NotFound verifies that an unknown email returns ErrUserNotFound.
+func TestGetUserByEmail_NotFound(t *testing.T) {
+	// Create a new mock repository for the user repository.
+	mockRepo := new(repository.MockUserRepository)
+
+	getUserByEmailUsecase := usecase.NewGetUserByEmailUsecase(mockRepo)
+
+	// The repository returns an empty user when nothing matches.
+	mockRepo.On("FindUserByEmail", "nobody@example.com").Return(&entities.User{}, nil)
+
+	// Execute the usecase.
+	_, err := getUserByEmailUsecase.Execute("nobody@example.com")
+
+	// Assert that the user was not found.
+	assert.Equal(t, usecase.ErrUserNotFound, err)
+}
@@ -1,13 +1,18 @@
 package usecase
 
 import (
-	"errors"
-
 	"github.com/jonattasmoraes/titan/internal/user/domain"
 	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
 )
 
-var ErrUserNotFound = errors.New("user not found")
+var (
+	ErrUserNotFound = domain.ErrUserNotFound
+	// ErrVersionMismatch is returned by writes given another version than the
+	// current one of the user.
+	ErrVersionMismatch = domain.ErrVersionMismatch
+	// ErrUserDeleted is returned when a replace would recreate a deleted user.
+	ErrUserDeleted = domain.ErrUserDeleted
+)
 
 type GetUserByIdUsecase struct {
 	repo domain.UserRepository
@@ -28,13 +33,17 @@ func (u *GetUserByIdUsecase) Execute(id string) (*dto.UserResponseDTO, error) {
 	}
 
 	userDTO := &dto.UserResponseDTO{
-		ID:        user.ID,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreateAt:  user.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdateAt:  user.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:          user.ID,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Email:       user.Email,
+		Role:        user.Role,
+		Region:      user.Region,
+		CreateAt:    user.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdateAt:    user.UpdatedAt.Format("2006-01-02 15:04:05"),
+		CreatedTime: user.CreatedAt,
+		UpdatedTime: user.UpdatedAt,
+		Version:     user.Version,
 	}
 
 	return userDTO, nil
@@ -44,4 +44,6 @@ func TestGetUserById(t *testing.T) {
 	assert.Equal(t, user.LastName, outPut.LastName, "LastName should be equal")
 	assert.Equal(t, user.Email, outPut.Email, "Email should be equal")
 	assert.Equal(t, user.Role, outPut.Role, "Role should be equal")
+	assert.Equal(t, user.CreatedAt, outPut.CreatedTime, "CreatedTime should be equal")
+	assert.Equal(t, user.UpdatedAt, outPut.UpdatedTime, "UpdatedTime should be equal")
 }
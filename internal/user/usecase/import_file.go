@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+// MaxImportRows is the largest number of rows a single import accepts.
+const MaxImportRows = 10000
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+var (
+	ErrInvalidImportFormat  = errcode.New(errcode.ImportFormatInvalid, "invalid format, use 'csv' or 'ndjson'")
+	ErrInvalidImportMapping = errcode.New(errcode.ImportMappingInvalid, "invalid mapping, use comma-separated field=column pairs for first_name, last_name, email, password and region")
+	ErrInvalidImportFile    = errcode.New(errcode.ImportFileInvalid, "invalid import file")
+	ErrEmptyImport          = errcode.New(errcode.ImportEmpty, "the file has no rows to import")
+	ErrTooManyImportRows    = errcode.New(errcode.ImportTooManyRows, fmt.Sprintf("too many rows, an import accepts at most %d", MaxImportRows))
+)
+
+// importFields are the user fields an import can fill.
+var importFields = []string{"first_name", "last_name", "email", "password", "region"}
+
+// ImportMapping maps user fields to the CSV column, or the NDJSON key, that
+// holds them. Unmapped fields are read from the column named after them.
+type ImportMapping map[string]string
+
+// ParseImportMapping reads a mapping such as
+// "first_name=Given name,email=Work email". An empty string maps nothing.
+func ParseImportMapping(value string) (ImportMapping, error) {
+	mapping := ImportMapping{}
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(field)
+		column = strings.TrimSpace(column)
+
+		if !ok || column == "" || !isImportField(field) {
+			return nil, ErrInvalidImportMapping
+		}
+
+		mapping[field] = column
+	}
+
+	return mapping, nil
+}
+
+func isImportField(field string) bool {
+	for _, known := range importFields {
+		if field == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m ImportMapping) column(field string) string {
+	if column, ok := m[field]; ok {
+		return column
+	}
+
+	return field
+}
+
+// ImportRecord is one row of an import file. Line is where it starts in the
+// file, counting from 1.
+type ImportRecord struct {
+	Line int
+	User dto.UserRequestDTO
+}
+
+// ParseImportFile reads every row of a CSV file with a header line, or of an
+// NDJSON file with one object per line. CSV headers are matched without
+// regard to case. Missing columns or keys leave the field empty, for the
+// validation to report. Values are trimmed, except passwords.
+func ParseImportFile(r io.Reader, format string, mapping ImportMapping) ([]ImportRecord, error) {
+	var (
+		records []ImportRecord
+		err     error
+	)
+
+	switch format {
+	case ImportFormatCSV:
+		records, err = parseImportCSV(r, mapping)
+	case ImportFormatNDJSON:
+		records, err = parseImportNDJSON(r, mapping)
+	default:
+		return nil, ErrInvalidImportFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, ErrEmptyImport
+	}
+
+	return records, nil
+}
+
+func parseImportCSV(r io.Reader, mapping ImportMapping) ([]ImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyImport
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheets often save a byte order mark before the first column.
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for field, column := range mapping {
+		if _, ok := columns[strings.ToLower(column)]; !ok {
+			return nil, fmt.Errorf("%w: column '%s' mapped to %s is not in the header", ErrInvalidImportFile, column, field)
+		}
+	}
+
+	var records []ImportRecord
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+		}
+
+		if len(records) == MaxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+
+		value := func(field string) string {
+			i, ok := columns[strings.ToLower(mapping.column(field))]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			if field == "password" {
+				return row[i]
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		line, _ := reader.FieldPos(0)
+		records = append(records, ImportRecord{
+			Line: line,
+			User: dto.UserRequestDTO{
+				FirstName: value("first_name"),
+				LastName:  value("last_name"),
+				Email:     value("email"),
+				Password:  value("password"),
+				Region:    value("region"),
+			},
+		})
+	}
+}
+
+func parseImportNDJSON(r io.Reader, mapping ImportMapping) ([]ImportRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var records []ImportRecord
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if len(records) == MaxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidImportFile, line, err)
+		}
+
+		value := func(field string) string {
+			switch v := object[mapping.column(field)].(type) {
+			case string:
+				if field == "password" {
+					return v
+				}
+				return strings.TrimSpace(v)
+			case nil:
+				return ""
+			default:
+				return fmt.Sprint(v)
+			}
+		}
+
+		records = append(records, ImportRecord{
+			Line: line,
+			User: dto.UserRequestDTO{
+				FirstName: value("first_name"),
+				LastName:  value("last_name"),
+				Email:     value("email"),
+				Password:  value("password"),
+				Region:    value("region"),
+			},
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,79 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseImportFile_CSV verifies that mapped columns are read whatever
+// their case and position, and that lines are reported as in the file.
+func TestParseImportFile_CSV(t *testing.T) {
+	mapping, err := usecase.ParseImportMapping("first_name=Given Name, email=Work Email")
+	require.NoError(t, err)
+
+	file := "\ufeffWork email,given name,last_name,password\n" +
+		"john@example.com, John ,Lennon,password123\n" +
+		"\n" +
+		"\"paul@example.com\",Paul,McCartney\n"
+
+	records, err := usecase.ParseImportFile(strings.NewReader(file), usecase.ImportFormatCSV, mapping)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, 2, records[0].Line)
+	assert.Equal(t, "John", records[0].User.FirstName)
+	assert.Equal(t, "Lennon", records[0].User.LastName)
+	assert.Equal(t, "john@example.com", records[0].User.Email)
+	assert.Equal(t, "password123", records[0].User.Password)
+
+	assert.Equal(t, 4, records[1].Line)
+	assert.Equal(t, "paul@example.com", records[1].User.Email)
+	assert.Empty(t, records[1].User.Password)
+}
+
+// TestParseImportFile_NDJSON verifies that NDJSON keys are mapped like CSV
+// columns.
+func TestParseImportFile_NDJSON(t *testing.T) {
+	mapping, err := usecase.ParseImportMapping("email=mail")
+	require.NoError(t, err)
+
+	file := `{"first_name":"John","last_name":"Lennon","mail":"john@example.com","password":"password123"}` + "\n\n" +
+		`{"first_name":"Paul","mail":"paul@example.com"}` + "\n"
+
+	records, err := usecase.ParseImportFile(strings.NewReader(file), usecase.ImportFormatNDJSON, mapping)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, 1, records[0].Line)
+	assert.Equal(t, "john@example.com", records[0].User.Email)
+	assert.Equal(t, 3, records[1].Line)
+	assert.Equal(t, "Paul", records[1].User.FirstName)
+}
+
+// TestParseImportFile_Invalid verifies the errors of files that cannot be
+// imported at all.
+func TestParseImportFile_Invalid(t *testing.T) {
+	_, err := usecase.ParseImportMapping("role=Role")
+	assert.Equal(t, usecase.ErrInvalidImportMapping, err)
+
+	_, err = usecase.ParseImportFile(strings.NewReader("email\n"), "xlsx", nil)
+	assert.Equal(t, usecase.ErrInvalidImportFormat, err)
+
+	_, err = usecase.ParseImportFile(strings.NewReader("email\n"), usecase.ImportFormatCSV, nil)
+	assert.Equal(t, usecase.ErrEmptyImport, err)
+
+	_, err = usecase.ParseImportFile(strings.NewReader("email\n"), usecase.ImportFormatCSV, usecase.ImportMapping{"email": "mail"})
+	assert.True(t, errors.Is(err, usecase.ErrInvalidImportFile))
+
+	_, err = usecase.ParseImportFile(strings.NewReader("{\"email\":\n"), usecase.ImportFormatNDJSON, nil)
+	assert.True(t, errors.Is(err, usecase.ErrInvalidImportFile))
+
+	rows := strings.Repeat("user@example.com\n", usecase.MaxImportRows+1)
+	_, err = usecase.ParseImportFile(strings.NewReader("email\n"+rows), usecase.ImportFormatCSV, nil)
+	assert.Equal(t, usecase.ErrTooManyImportRows, err)
+}
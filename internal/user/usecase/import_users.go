@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"github.com/oklog/ulid/v2"
+)
+
+// ImportSyncRows is the largest import completed within the request. Larger
+// imports run in the background.
+const ImportSyncRows = 100
+
+// importJobRetention is how long a finished import can still be polled.
+const importJobRetention = time.Hour
+
+// DefaultImportDrainTimeout is how long a shutdown waits for the background
+// imports when no timeout is configured.
+const DefaultImportDrainTimeout = time.Minute
+
+const (
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+
+	ImportRowCreated   = "created"
+	ImportRowValid     = "valid"
+	ImportRowInvalid   = "invalid"
+	ImportRowDuplicate = "duplicate"
+	ImportRowFailed    = "failed"
+)
+
+var (
+	ErrImportJobNotFound = errcode.New(errcode.ImportJobNotFound, "import job not found")
+	ErrImportsDraining   = errcode.New(errcode.Unavailable, "the service is shutting down, retry the import later")
+)
+
+type importJob struct {
+	dto.ImportJobDTO
+	finishedAt time.Time
+}
+
+// ImportUsersUsecase creates users from the rows of a file. Jobs are kept in
+// memory, so they are lost on restart and can only be polled on the instance
+// that runs them. Drain waits for the background imports on shutdown.
+type ImportUsersUsecase struct {
+	repo       domain.UserRepository
+	createUser *CreateUserUsecase
+
+	mu       sync.Mutex
+	jobs     map[string]*importJob
+	running  sync.WaitGroup
+	draining bool
+}
+
+func NewImportUsersUsecase(repo domain.UserRepository, createUser *CreateUserUsecase) *ImportUsersUsecase {
+	return &ImportUsersUsecase{repo: repo, createUser: createUser, jobs: map[string]*importJob{}}
+}
+
+// Execute validates every record like a sign-up and rejects emails that are
+// taken or repeated in the file. Unless dryRun is set, each valid record is
+// then created on its own, so a failed row does not stop the others.
+//
+// Imports of up to ImportSyncRows records are completed when Execute returns.
+// Larger ones are returned running; poll them with Job. Once Drain was
+// called, they fail with ErrImportsDraining.
+func (u *ImportUsersUsecase) Execute(records []ImportRecord, dryRun bool) (*dto.ImportJobDTO, error) {
+	if len(records) == 0 {
+		return nil, ErrEmptyImport
+	}
+
+	if len(records) > MaxImportRows {
+		return nil, ErrTooManyImportRows
+	}
+
+	job := &importJob{
+		ImportJobDTO: dto.ImportJobDTO{
+			ID:        ulid.Make().String(),
+			Status:    ImportJobRunning,
+			DryRun:    dryRun,
+			TotalRows: len(records),
+			Rows:      make([]dto.ImportRowDTO, 0, len(records)),
+			CreateAt:  time.Now().Format("2006-01-02 15:04:05"),
+		},
+	}
+
+	background := len(records) > ImportSyncRows
+
+	u.mu.Lock()
+	if background && u.draining {
+		u.mu.Unlock()
+		return nil, ErrImportsDraining
+	}
+	u.pruneJobs()
+	u.jobs[job.ID] = job
+	if background {
+		u.running.Add(1)
+	}
+	u.mu.Unlock()
+
+	if !background {
+		u.run(job, records)
+		return u.snapshot(job), nil
+	}
+
+	go func() {
+		defer u.running.Done()
+		u.run(job, records)
+	}()
+
+	return u.snapshot(job), nil
+}
+
+// Drain refuses new background imports and waits for the running ones to
+// finish, or for ctx to be done. Jobs are kept in memory only, so an import
+// still running when the process exits is left half done.
+func (u *ImportUsersUsecase) Drain(ctx context.Context) error {
+	u.mu.Lock()
+	u.draining = true
+	u.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		u.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Job returns the progress of an import. Finished imports can be polled for
+// an hour.
+func (u *ImportUsersUsecase) Job(id string) (*dto.ImportJobDTO, error) {
+	u.mu.Lock()
+	u.pruneJobs()
+	job, ok := u.jobs[id]
+	u.mu.Unlock()
+
+	if !ok {
+		return nil, ErrImportJobNotFound
+	}
+
+	return u.snapshot(job), nil
+}
+
+// pruneJobs forgets the jobs finished for longer than importJobRetention. It
+// must be called with u.mu held.
+func (u *ImportUsersUsecase) pruneJobs() {
+	for id, job := range u.jobs {
+		if !job.finishedAt.IsZero() && time.Since(job.finishedAt) > importJobRetention {
+			delete(u.jobs, id)
+		}
+	}
+}
+
+func (u *ImportUsersUsecase) snapshot(job *importJob) *dto.ImportJobDTO {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	snapshot := job.ImportJobDTO
+	snapshot.Rows = append([]dto.ImportRowDTO(nil), job.Rows...)
+
+	return &snapshot
+}
+
+func (u *ImportUsersUsecase) run(job *importJob, records []ImportRecord) {
+	// seen holds the line of the first accepted row of each email.
+	seen := map[string]int{}
+
+	for _, record := range records {
+		row := u.importRow(record, job.DryRun, seen)
+
+		u.mu.Lock()
+		job.Rows = append(job.Rows, row)
+		job.ProcessedRows++
+		if row.Status == ImportRowCreated || row.Status == ImportRowValid {
+			job.Succeeded++
+		} else {
+			job.Failed++
+		}
+		u.mu.Unlock()
+	}
+
+	u.mu.Lock()
+	job.Status = ImportJobCompleted
+	job.finishedAt = time.Now()
+	job.FinishedAt = job.finishedAt.Format("2006-01-02 15:04:05")
+	u.mu.Unlock()
+}
+
+func (u *ImportUsersUsecase) importRow(record ImportRecord, dryRun bool, seen map[string]int) dto.ImportRowDTO {
+	row := dto.ImportRowDTO{Line: record.Line, Email: record.User.Email}
+	request := record.User
+
+	// Validate first, so that rows without an email are reported as such.
+	if _, err := entities.NewUser(request.FirstName, request.LastName, request.Email, request.Password, request.Region); err != nil {
+		return rejectImportRow(row, ImportRowInvalid, err)
+	}
+
+	if line, ok := seen[request.Email]; ok {
+		return rejectImportRow(row, ImportRowDuplicate, &errcode.Error{
+			Code:    errcode.ImportDuplicate,
+			Message: fmt.Sprintf("email is already used on line %d of the file", line),
+		})
+	}
+
+	if dryRun {
+		existing, err := u.repo.FindUserByEmail(request.Email)
+		if err != nil {
+			return rejectImportRow(row, ImportRowFailed, err)
+		}
+
+		if existing != nil && existing.Email == request.Email {
+			return rejectImportRow(row, ImportRowDuplicate, ErrEmailAlreadyExists)
+		}
+
+		seen[request.Email] = record.Line
+		row.Status = ImportRowValid
+
+		return row
+	}
+
+	user, err := u.createUser.Execute(&request)
+	if err != nil {
+		if err == ErrEmailAlreadyExists {
+			return rejectImportRow(row, ImportRowDuplicate, err)
+		}
+
+		return rejectImportRow(row, ImportRowFailed, err)
+	}
+
+	seen[request.Email] = record.Line
+	row.Status = ImportRowCreated
+	row.UserID = user.ID
+
+	return row
+}
+
+func rejectImportRow(row dto.ImportRowDTO, status string, err error) dto.ImportRowDTO {
+	row.Status = status
+	row.Error = err.Error()
+	row.Code = string(errcode.Of(err))
+	row.Field = errcode.FieldOf(err)
+
+	return row
+}
@@ -0,0 +1,127 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func importRecord(line int, firstName, email string) usecase.ImportRecord {
+	return usecase.ImportRecord{
+		Line: line,
+		User: dto.UserRequestDTO{FirstName: firstName, LastName: "Lennon", Email: email, Password: "password123"},
+	}
+}
+
+// TestImportUsers_DryRun verifies that a dry run reports every row without
+// writing anything.
+func TestImportUsers_DryRun(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	importUsers := usecase.NewImportUsersUsecase(mockRepo, usecase.NewCreateUserUsecase(transactorFor(mockRepo, mockEvents)))
+
+	mockRepo.On("FindUserByEmail", "taken@example.com").Return(&entities.User{Email: "taken@example.com"}, nil)
+	mockRepo.On("FindUserByEmail", mock.AnythingOfType("string")).Return(&entities.User{}, nil)
+
+	job, err := importUsers.Execute([]usecase.ImportRecord{
+		importRecord(2, "John", "john@example.com"),
+		importRecord(3, "Jo", "jo@example.com"),
+		importRecord(4, "Johnny", "john@example.com"),
+		importRecord(5, "Taken", "taken@example.com"),
+	}, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, usecase.ImportJobCompleted, job.Status)
+	assert.True(t, job.DryRun)
+	assert.Equal(t, 4, job.ProcessedRows)
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, 3, job.Failed)
+
+	assert.Equal(t, usecase.ImportRowValid, job.Rows[0].Status)
+	assert.Equal(t, usecase.ImportRowInvalid, job.Rows[1].Status)
+	assert.Equal(t, "first_name", job.Rows[1].Field)
+	assert.Equal(t, usecase.ImportRowDuplicate, job.Rows[2].Status)
+	assert.Contains(t, job.Rows[2].Error, "line 2")
+	assert.Equal(t, usecase.ImportRowDuplicate, job.Rows[3].Status)
+
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+// TestImportUsers_Background verifies that large imports run in the
+// background and can be polled until they complete.
+func TestImportUsers_Background(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	importUsers := usecase.NewImportUsersUsecase(mockRepo, usecase.NewCreateUserUsecase(transactorFor(mockRepo, mockEvents)))
+
+	mockRepo.On("FindUserByEmail", mock.AnythingOfType("string")).Return(&entities.User{}, nil)
+	mockRepo.On("CreateUser", mock.AnythingOfType("*entities.User")).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	var records []usecase.ImportRecord
+	for i := 0; i <= usecase.ImportSyncRows; i++ {
+		records = append(records, importRecord(i+2, "John", fmt.Sprintf("john%d@example.com", i)))
+	}
+
+	job, err := importUsers.Execute(records, false)
+	require.NoError(t, err)
+	assert.Equal(t, len(records), job.TotalRows)
+
+	assert.Eventually(t, func() bool {
+		job, err = importUsers.Job(job.ID)
+		return err == nil && job.Status == usecase.ImportJobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, len(records), job.Succeeded)
+	assert.Equal(t, usecase.ImportRowCreated, job.Rows[0].Status)
+	assert.NotEmpty(t, job.Rows[0].UserID)
+	mockRepo.AssertNumberOfCalls(t, "CreateUser", len(records))
+
+	_, err = importUsers.Job("unknown")
+	assert.Equal(t, usecase.ErrImportJobNotFound, err)
+}
+
+// TestImportUsers_Drain verifies that a shutdown waits for the background
+// imports and refuses new ones.
+func TestImportUsers_Drain(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	importUsers := usecase.NewImportUsersUsecase(mockRepo, usecase.NewCreateUserUsecase(transactorFor(mockRepo, mockEvents)))
+
+	release := make(chan struct{})
+	mockRepo.On("FindUserByEmail", mock.AnythingOfType("string")).Return(&entities.User{}, nil)
+	mockRepo.On("CreateUser", mock.AnythingOfType("*entities.User")).Run(func(mock.Arguments) { <-release }).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	var records []usecase.ImportRecord
+	for i := 0; i <= usecase.ImportSyncRows; i++ {
+		records = append(records, importRecord(i+2, "John", fmt.Sprintf("john%d@example.com", i)))
+	}
+
+	job, err := importUsers.Execute(records, false)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, importUsers.Drain(ctx))
+
+	_, err = importUsers.Execute(records, false)
+	assert.Equal(t, usecase.ErrImportsDraining, err)
+
+	close(release)
+	require.NoError(t, importUsers.Drain(context.Background()))
+
+	job, err = importUsers.Job(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, usecase.ImportJobCompleted, job.Status)
+	assert.Equal(t, len(records), job.Succeeded)
+}
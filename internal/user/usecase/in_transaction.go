@@ -0,0 +1,15 @@
+package usecase
+
+import "github.com/jonattasmoraes/titan/internal/user/domain"
+
+// inTransaction is the UserTransactor of repositories that already write
+// inside a transaction. Usecases run with it join that transaction, as the
+// operations of an atomic batch do.
+type inTransaction struct {
+	users  domain.UserRepository
+	events domain.UserEventRepository
+}
+
+func (t inTransaction) WithinTransaction(fn func(users domain.UserRepository, events domain.UserEventRepository) error) error {
+	return fn(t.users, t.events)
+}
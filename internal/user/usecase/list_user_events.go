@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+var ErrInvalidCursor = errcode.New(errcode.EventCursorInvalid, "invalid cursor, use a cursor returned by a previous event")
+
+type ListUserEventsUsecase struct {
+	events domain.UserEventRepository
+}
+
+func NewListUserEventsUsecase(events domain.UserEventRepository) *ListUserEventsUsecase {
+	return &ListUserEventsUsecase{events: events}
+}
+
+// Execute returns up to limit events recorded after cursor, together with the
+// cursor to resume from. An empty cursor starts at the beginning of the log.
+func (u *ListUserEventsUsecase) Execute(cursor string, limit int) ([]*dto.UserEventDTO, string, error) {
+	after, err := decodeEventCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	events, err := u.events.ListUserEvents(after, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := cursor
+	var eventsDTO []*dto.UserEventDTO
+
+	for _, event := range events {
+		next = encodeEventCursor(event.Sequence)
+
+		eventsDTO = append(eventsDTO, &dto.UserEventDTO{
+			Cursor: next,
+			Type:   event.Type,
+			User: &dto.UserResponseDTO{
+				ID:          event.User.ID,
+				FirstName:   event.User.FirstName,
+				LastName:    event.User.LastName,
+				Email:       event.User.Email,
+				Role:        event.User.Role,
+				Region:      event.User.Region,
+				CreateAt:    event.User.CreatedAt.Format("2006-01-02 15:04:05"),
+				UpdateAt:    event.User.UpdatedAt.Format("2006-01-02 15:04:05"),
+				CreatedTime: event.User.CreatedAt,
+				UpdatedTime: event.User.UpdatedAt,
+			},
+			OccurredAt:   event.OccurredAt.Format("2006-01-02 15:04:05"),
+			OccurredTime: event.OccurredAt,
+		})
+	}
+
+	return eventsDTO, next, nil
+}
+
+func encodeEventCursor(sequence int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("ev:" + strconv.FormatInt(sequence, 10)))
+}
+
+func decodeEventCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) < 4 || string(raw[:3]) != "ev:" {
+		return 0, ErrInvalidCursor
+	}
+
+	sequence, err := strconv.ParseInt(string(raw[3:]), 10, 64)
+	if err != nil || sequence < 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return sequence, nil
+}
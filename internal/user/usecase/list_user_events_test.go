@@ -0,0 +1,55 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestListUserEvents tests the ListUserEvents usecase.
+// It verifies if the returned cursor resumes right after the last event.
+func TestListUserEvents(t *testing.T) {
+	// Create a new mock repository for the change log.
+	mockEvents := new(repository.MockUserEventRepository)
+
+	listUserEventsUsecase := usecase.NewListUserEventsUsecase(mockEvents)
+
+	user := &entities.User{ID: "1", FirstName: "John", LastName: "Lennon", Email: "john.lennon@example.com", Role: "user"}
+	events := []*entities.UserEvent{
+		{Sequence: 7, Type: entities.UserCreated, User: *user, OccurredAt: time.Now()},
+		{Sequence: 9, Type: entities.UserUpdated, User: *user, OccurredAt: time.Now()},
+	}
+
+	// The first page starts at the beginning of the log.
+	mockEvents.On("ListUserEvents", int64(0), 10).Return(events, nil)
+
+	output, cursor, err := listUserEventsUsecase.Execute("", 10)
+	assert.NoError(t, err)
+	assert.Len(t, output, 2)
+	assert.Equal(t, entities.UserCreated, output[0].Type)
+	assert.Equal(t, cursor, output[1].Cursor)
+
+	// Resuming from the returned cursor asks for events after sequence 9.
+	mockEvents.On("ListUserEvents", int64(9), 10).Return([]*entities.UserEvent{}, nil)
+
+	output, next, err := listUserEventsUsecase.Execute(cursor, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, output)
+	assert.Equal(t, cursor, next)
+
+	mockEvents.AssertExpectations(t)
+}
+
+// TestListUserEvents_InvalidCursor verifies that unknown cursors are rejected.
+func TestListUserEvents_InvalidCursor(t *testing.T) {
+	mockEvents := new(repository.MockUserEventRepository)
+
+	listUserEventsUsecase := usecase.NewListUserEventsUsecase(mockEvents)
+
+	_, _, err := listUserEventsUsecase.Execute("not-a-cursor", 10)
+	assert.Equal(t, usecase.ErrInvalidCursor, err)
+}
@@ -1,17 +1,63 @@
 package usecase
 
 import (
-	"errors"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/jonattasmoraes/titan/internal/user/domain"
 	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
 )
 
 var (
-	ErrInvalidPageNumber = errors.New("invalid page number, enter a number greater than 0")
-	ErrUsersNotFound     = errors.New("users not found")
+	ErrInvalidPageNumber    = errcode.New(errcode.PageInvalid, "invalid page number, enter a number greater than 0")
+	ErrInvalidPageSize      = errcode.New(errcode.PageSizeInvalid, "invalid page size, enter a number between 1 and 100")
+	ErrInvalidPageCursor    = errcode.New(errcode.CursorInvalid, "invalid cursor, use the next_cursor of a previous response with the same sort")
+	ErrInvalidSortField     = errcode.New(errcode.SortInvalid, "invalid sort field")
+	ErrInvalidSortOrder     = errcode.New(errcode.OrderInvalid, "invalid sort order, use 'asc' or 'desc'")
+	ErrInvalidRoleFilter    = errcode.New(errcode.RoleFilterInvalid, "invalid role filter, use 'admin', 'super' or 'user'")
+	ErrInvalidEmailDomain   = errcode.New(errcode.EmailDomainInvalid, "invalid email domain filter")
+	ErrInvalidCreatedRange  = errcode.New(errcode.CreatedRangeInvalid, "invalid created range, the start must be before the end")
+	ErrInvalidUpdatedRange  = errcode.New(errcode.UpdatedRangeInvalid, "invalid updated range, the start must be before the end")
+	ErrInvalidDeletedFilter = errcode.New(errcode.DeletedInvalid, "invalid deleted filter, use 'exclude', 'include' or 'only'")
+	ErrUsersNotFound        = errcode.New(errcode.UsersNotFound, "users not found")
 )
 
+var emailDomainPattern = regexp.MustCompile(`^[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// ListUsersRequest selects a page either by Cursor, taken from the previous
+// response, or by Page number. Page is kept for older clients: it reads with
+// an offset and is ignored when Cursor is set.
+type ListUsersRequest struct {
+	Page         int
+	PageSize     int
+	Cursor       string
+	IncludeTotal bool
+	Filter       domain.UserFilter
+	// Sort is one of domain.UserSortFields, "id" by default. Order is "asc",
+	// the default, or "desc".
+	Sort  string
+	Order string
+}
+
+type ListUsersResponse struct {
+	Users []*dto.UserResponseDTO
+	// NextCursor is empty on the last page.
+	NextCursor string
+	// TotalCount is set only when the request asked for it.
+	TotalCount *int
+}
+
 type ListUsersUsecase struct {
 	repo domain.UserRepository
 }
@@ -20,32 +66,200 @@ func NewListUsersUsecase(repo domain.UserRepository) *ListUsersUsecase {
 	return &ListUsersUsecase{repo: repo}
 }
 
-func (u *ListUsersUsecase) Execute(page int) ([]*dto.UserResponseDTO, error) {
-	if page < 1 {
-		return nil, ErrInvalidPageNumber
+func (u *ListUsersUsecase) Execute(request ListUsersRequest) (*ListUsersResponse, error) {
+	if request.PageSize == 0 {
+		request.PageSize = DefaultPageSize
+	}
+
+	if request.PageSize < 1 || request.PageSize > MaxPageSize {
+		return nil, ErrInvalidPageSize
+	}
+
+	if err := validateUserFilter(request.Filter); err != nil {
+		return nil, err
+	}
+
+	// One extra user tells whether there is a next page.
+	query := domain.ListUsersQuery{Filter: request.Filter, Limit: request.PageSize + 1}
+
+	sortBy, descending, err := parseUserSort(request.Sort, request.Order)
+	if err != nil {
+		return nil, err
+	}
+	query.SortBy = sortBy
+	query.Descending = descending
+
+	if request.Cursor != "" {
+		after, err := decodeUserCursor(request.Cursor, query)
+		if err != nil {
+			return nil, err
+		}
+		query.After = after
+	} else {
+		if request.Page == 0 {
+			request.Page = 1
+		}
+
+		if request.Page < 1 {
+			return nil, ErrInvalidPageNumber
+		}
+		query.Offset = (request.Page - 1) * request.PageSize
 	}
 
-	users, err := u.repo.ListUsers(page)
+	users, err := u.repo.ListUsers(query)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(users) == 0 {
+	if len(users) == 0 && request.Cursor == "" {
 		return nil, ErrUsersNotFound
 	}
 
-	var usersDTO []*dto.UserResponseDTO
+	response := &ListUsersResponse{}
+
+	if len(users) > request.PageSize {
+		users = users[:request.PageSize]
+		response.NextCursor = encodeUserCursor(users[len(users)-1], query)
+	}
+
 	for _, user := range users {
-		usersDTO = append(usersDTO, &dto.UserResponseDTO{
-			ID:        user.ID,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-			Role:      user.Role,
-			CreateAt:  user.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdateAt:  user.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
-	}
-
-	return usersDTO, nil
+		response.Users = append(response.Users, listedUserResponse(user))
+	}
+
+	if request.IncludeTotal {
+		total, err := u.repo.CountUsers(query)
+		if err != nil {
+			return nil, err
+		}
+		response.TotalCount = &total
+	}
+
+	return response, nil
+}
+
+// parseUserSort returns the sort field and direction of a request, by ID in
+// ascending order by default.
+func parseUserSort(sort, order string) (domain.UserSortField, bool, error) {
+	sortBy := domain.SortByID
+	if sort != "" {
+		sortBy = domain.UserSortField(sort)
+		if !sortBy.IsValid() {
+			return "", false, fmt.Errorf("%w: %q, use one of %v", ErrInvalidSortField, sort, domain.UserSortFields)
+		}
+	}
+
+	switch strings.ToLower(order) {
+	case "", "asc":
+		return sortBy, false, nil
+	case "desc":
+		return sortBy, true, nil
+	}
+
+	return "", false, ErrInvalidSortOrder
+}
+
+// listedUserResponse converts a listed user, which may be deleted.
+func listedUserResponse(user *entities.User) *dto.UserResponseDTO {
+	response := &dto.UserResponseDTO{
+		ID:          user.ID,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Email:       user.Email,
+		Role:        user.Role,
+		Region:      user.Region,
+		CreateAt:    user.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdateAt:    user.UpdatedAt.Format("2006-01-02 15:04:05"),
+		CreatedTime: user.CreatedAt,
+		UpdatedTime: user.UpdatedAt,
+		Version:     user.Version,
+	}
+
+	if !user.DeletedAt.IsZero() {
+		response.DeleteAt = user.DeletedAt.Format("2006-01-02 15:04:05")
+		response.DeletedTime = user.DeletedAt
+	}
+
+	return response
+}
+
+func validateUserFilter(filter domain.UserFilter) error {
+	if filter.Role != "" && !entities.IsValidRole(filter.Role) {
+		return ErrInvalidRoleFilter
+	}
+
+	if filter.EmailDomain != "" && !emailDomainPattern.MatchString(filter.EmailDomain) {
+		return ErrInvalidEmailDomain
+	}
+
+	if isEmptyRange(filter.CreatedAfter, filter.CreatedBefore) {
+		return ErrInvalidCreatedRange
+	}
+
+	if isEmptyRange(filter.UpdatedAfter, filter.UpdatedBefore) {
+		return ErrInvalidUpdatedRange
+	}
+
+	switch filter.Deleted {
+	case domain.ExcludeDeleted, domain.IncludeDeleted, domain.OnlyDeleted:
+	default:
+		return ErrInvalidDeletedFilter
+	}
+
+	return nil
+}
+
+func isEmptyRange(start, end time.Time) bool {
+	return !start.IsZero() && !end.IsZero() && !start.Before(end)
+}
+
+// userCursor is the position after which the next page starts, with the sort
+// it was made for. It is sent to clients base64 encoded so they treat it as
+// opaque.
+type userCursor struct {
+	AfterID    string `json:"after"`
+	Value      string `json:"value,omitempty"`
+	Sort       string `json:"sort,omitempty"`
+	Descending bool   `json:"desc,omitempty"`
+}
+
+func encodeUserCursor(last *entities.User, query domain.ListUsersQuery) string {
+	c := userCursor{AfterID: last.ID, Descending: query.Descending}
+	if query.SortBy != domain.SortByID {
+		c.Sort = string(query.SortBy)
+		c.Value = query.SortBy.Value(last)
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeUserCursor rejects cursors made for another sort, whose position
+// would mean nothing in this one.
+func decodeUserCursor(encoded string, query domain.ListUsersQuery) (*domain.UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidPageCursor
+	}
+
+	var c userCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.AfterID == "" {
+		return nil, ErrInvalidPageCursor
+	}
+
+	sort := domain.UserSortField(c.Sort)
+	if c.Sort == "" {
+		sort = domain.SortByID
+	}
+
+	if sort != query.SortBy || c.Descending != query.Descending {
+		return nil, ErrInvalidPageCursor
+	}
+
+	if sort.IsTime() {
+		if _, err := time.Parse(time.RFC3339Nano, c.Value); err != nil {
+			return nil, ErrInvalidPageCursor
+		}
+	}
+
+	return &domain.UserCursor{Value: c.Value, ID: c.AfterID}, nil
 }
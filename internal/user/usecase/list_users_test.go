@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jonattasmoraes/titan/internal/user/domain"
 	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
 	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
 	"github.com/jonattasmoraes/titan/internal/user/usecase"
@@ -37,15 +38,16 @@ func TestListUsers(t *testing.T) {
 	}
 
 	// Mock the ListUsers method of the mock repository to return the user.
-	mockRepo.On("ListUsers", mock.AnythingOfType("int")).Return(userMock, nil)
+	mockRepo.On("ListUsers", mock.AnythingOfType("domain.ListUsersQuery")).Return(userMock, nil)
 
 	// Execute the ListUsersUsecase with the page number 1.
-	output, err := listUsersUsecase.Execute(1)
+	response, err := listUsersUsecase.Execute(usecase.ListUsersRequest{Page: 1})
 
 	// Assert that there is no error.
 	assert.NoError(t, err)
 
 	// Assert that the output is not nil and has a length of 1.
+	output := response.Users
 	assert.NotNil(t, output)
 	assert.Len(t, output, 1)
 
@@ -59,3 +61,157 @@ func TestListUsers(t *testing.T) {
 	// Assert that all expectations set on the mock repository have been met.
 	mockRepo.AssertExpectations(t)
 }
+
+func pageOfUsers(n int) []*entities.User {
+	var users []*entities.User
+	for i := 0; i < n; i++ {
+		users = append(users, &entities.User{ID: ulid.Make().String(), FirstName: "John"})
+	}
+
+	return users
+}
+
+// TestListUsers_Cursor verifies that a full page returns a cursor that
+// continues after its last user.
+func TestListUsers_Cursor(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	listUsersUsecase := usecase.NewListUsersUsecase(mockRepo)
+
+	// The repository is asked for one more user than the page size, to learn
+	// whether there is a next page.
+	users := pageOfUsers(3)
+	mockRepo.On("ListUsers", domain.ListUsersQuery{SortBy: domain.SortByID, Limit: 3}).Return(users, nil)
+
+	first, err := listUsersUsecase.Execute(usecase.ListUsersRequest{PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, first.Users, 2)
+	assert.NotEmpty(t, first.NextCursor)
+	assert.Nil(t, first.TotalCount)
+
+	// The cursor resumes after the second user.
+	mockRepo.On("ListUsers", domain.ListUsersQuery{SortBy: domain.SortByID, After: &domain.UserCursor{ID: users[1].ID}, Limit: 3}).Return(users[2:], nil)
+
+	second, err := listUsersUsecase.Execute(usecase.ListUsersRequest{PageSize: 2, Cursor: first.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, second.Users, 1)
+	assert.Empty(t, second.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestListUsers_PageCompatibility verifies that page numbers still read
+// with an offset.
+func TestListUsers_PageCompatibility(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	listUsersUsecase := usecase.NewListUsersUsecase(mockRepo)
+
+	mockRepo.On("ListUsers", domain.ListUsersQuery{SortBy: domain.SortByID, Offset: 20, Limit: 11}).Return(pageOfUsers(1), nil)
+
+	response, err := listUsersUsecase.Execute(usecase.ListUsersRequest{Page: 3})
+
+	assert.NoError(t, err)
+	assert.Len(t, response.Users, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestListUsers_TotalCount verifies that the total is only counted when
+// asked for.
+func TestListUsers_TotalCount(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	listUsersUsecase := usecase.NewListUsersUsecase(mockRepo)
+
+	mockRepo.On("ListUsers", mock.AnythingOfType("domain.ListUsersQuery")).Return(pageOfUsers(1), nil)
+	mockRepo.On("CountUsers", mock.AnythingOfType("domain.ListUsersQuery")).Return(42, nil)
+
+	response, err := listUsersUsecase.Execute(usecase.ListUsersRequest{IncludeTotal: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, *response.TotalCount)
+}
+
+// TestListUsers_InvalidRequest verifies that the page size and cursor are
+// checked before the repository is touched.
+func TestListUsers_InvalidRequest(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	listUsersUsecase := usecase.NewListUsersUsecase(mockRepo)
+
+	_, err := listUsersUsecase.Execute(usecase.ListUsersRequest{PageSize: usecase.MaxPageSize + 1})
+	assert.Equal(t, usecase.ErrInvalidPageSize, err)
+
+	_, err = listUsersUsecase.Execute(usecase.ListUsersRequest{Cursor: "not a cursor"})
+	assert.Equal(t, usecase.ErrInvalidPageCursor, err)
+
+	_, err = listUsersUsecase.Execute(usecase.ListUsersRequest{Page: -1})
+	assert.Equal(t, usecase.ErrInvalidPageNumber, err)
+
+	mockRepo.AssertNotCalled(t, "ListUsers", mock.Anything)
+}
+
+// TestListUsers_SortCursor verifies that a sorted page continues after the
+// sort value and ID of its last user, and that its cursor is refused by
+// another sort.
+func TestListUsers_SortCursor(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	listUsersUsecase := usecase.NewListUsersUsecase(mockRepo)
+
+	users := pageOfUsers(3)
+	filter := domain.UserFilter{Role: "user", EmailDomain: "example.com"}
+
+	// Mock the first page, newest users first.
+	mockRepo.On("ListUsers", domain.ListUsersQuery{
+		Filter:     filter,
+		SortBy:     domain.SortByCreatedAt,
+		Descending: true,
+		Limit:      3,
+	}).Return(users, nil)
+
+	first, err := listUsersUsecase.Execute(usecase.ListUsersRequest{PageSize: 2, Filter: filter, Sort: "created_at", Order: "DESC"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.NextCursor)
+
+	// The next page starts after the creation time and ID of the second user.
+	mockRepo.On("ListUsers", domain.ListUsersQuery{
+		Filter:     filter,
+		SortBy:     domain.SortByCreatedAt,
+		Descending: true,
+		After:      &domain.UserCursor{Value: users[1].CreatedAt.Format(time.RFC3339Nano), ID: users[1].ID},
+		Limit:      3,
+	}).Return(users[2:], nil)
+
+	_, err = listUsersUsecase.Execute(usecase.ListUsersRequest{PageSize: 2, Filter: filter, Sort: "created_at", Order: "desc", Cursor: first.NextCursor})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	// The same cursor means nothing in ascending order.
+	_, err = listUsersUsecase.Execute(usecase.ListUsersRequest{PageSize: 2, Filter: filter, Sort: "created_at", Cursor: first.NextCursor})
+	assert.Equal(t, usecase.ErrInvalidPageCursor, err)
+}
+
+// TestListUsers_InvalidFilterAndSort verifies that filters and sort are
+// checked against their whitelists before the repository is touched.
+func TestListUsers_InvalidFilterAndSort(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	listUsersUsecase := usecase.NewListUsersUsecase(mockRepo)
+
+	now := time.Now()
+
+	tests := []struct {
+		request usecase.ListUsersRequest
+		err     error
+	}{
+		{usecase.ListUsersRequest{Sort: "password"}, usecase.ErrInvalidSortField},
+		{usecase.ListUsersRequest{Sort: "email; DROP TABLE users"}, usecase.ErrInvalidSortField},
+		{usecase.ListUsersRequest{Order: "sideways"}, usecase.ErrInvalidSortOrder},
+		{usecase.ListUsersRequest{Filter: domain.UserFilter{Role: "root"}}, usecase.ErrInvalidRoleFilter},
+		{usecase.ListUsersRequest{Filter: domain.UserFilter{EmailDomain: "%"}}, usecase.ErrInvalidEmailDomain},
+		{usecase.ListUsersRequest{Filter: domain.UserFilter{CreatedAfter: now, CreatedBefore: now}}, usecase.ErrInvalidCreatedRange},
+		{usecase.ListUsersRequest{Filter: domain.UserFilter{UpdatedAfter: now, UpdatedBefore: now.Add(-time.Hour)}}, usecase.ErrInvalidUpdatedRange},
+		{usecase.ListUsersRequest{Filter: domain.UserFilter{Deleted: "sometimes"}}, usecase.ErrInvalidDeletedFilter},
+	}
+
+	for _, tt := range tests {
+		_, err := listUsersUsecase.Execute(tt.request)
+		assert.ErrorIs(t, err, tt.err)
+	}
+
+	mockRepo.AssertNotCalled(t, "ListUsers", mock.Anything)
+}
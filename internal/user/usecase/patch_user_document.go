@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+const (
+	// PatchFormatMerge is a JSON Merge Patch (RFC 7396).
+	PatchFormatMerge = "merge"
+	// PatchFormatJSON is a JSON Patch (RFC 6902).
+	PatchFormatJSON = "json"
+)
+
+var (
+	ErrInvalidPatch    = errcode.New(errcode.PatchInvalid, "invalid patch document")
+	ErrPatchTestFailed = errcode.New(errcode.PatchTestFailed, "a test operation of the patch failed")
+)
+
+// PatchUserDocumentUsecase applies a JSON Merge Patch or a JSON Patch to the
+// JSON document of a user, the one GET /user/{id} returns. Unlike a plain
+// patch, it can set a field to an empty value; the result is validated as a
+// whole like any update.
+type PatchUserDocumentUsecase struct {
+	repo       domain.UserRepository
+	updateUser *UpdateUserUsecase
+}
+
+func NewPatchUserDocumentUsecase(repo domain.UserRepository, updateUser *UpdateUserUsecase) *PatchUserDocumentUsecase {
+	return &PatchUserDocumentUsecase{repo: repo, updateUser: updateUser}
+}
+
+// Execute patches the user with the patch document, in format. Only fields,
+// some of the PatchableUserFields, can change, and the UpdatableUserFields
+// when it is empty; test operations may read any field. A null, or a removed
+// field, clears the last name or the region. The user is saved at the version
+// the patch was applied to, so a concurrent write fails with
+// ErrVersionMismatch. When version is set, the user must be at that version.
+func (u *PatchUserDocumentUsecase) Execute(id string, version int64, format string, patch []byte, fields []string) (*dto.UserResponseDTO, error) {
+	if len(fields) == 0 {
+		fields = UpdatableUserFields
+	}
+
+	user, err := u.repo.FindUserById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if version != 0 && version != user.Version {
+		return nil, ErrVersionMismatch
+	}
+
+	document, err := json.Marshal(listedUserResponse(user))
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := applyPatch(document, format, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := patchedUser(document, patched, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.updateUser.update(&entities.User{
+		ID:        user.ID,
+		FirstName: changed.FirstName,
+		LastName:  changed.LastName,
+		Email:     changed.Email,
+		Region:    changed.Region,
+		Role:      changed.Role,
+		Version:   user.Version,
+	}, fields)
+}
+
+func applyPatch(document []byte, format string, patch []byte) ([]byte, error) {
+	switch format {
+	case PatchFormatMerge:
+		if !json.Valid(patch) {
+			return nil, ErrInvalidPatch
+		}
+
+		patched, err := jsonpatch.MergePatch(document, patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+
+		return patched, nil
+
+	case PatchFormatJSON:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+
+		patched, err := operations.Apply(document)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, fmt.Errorf("%w: %v", ErrPatchTestFailed, err)
+		}
+		if err != nil {
+			return nil, &errcode.Error{Code: errcode.PatchNotApplicable, Message: err.Error(), Err: err}
+		}
+
+		return patched, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidPatch, format)
+}
+
+// patchedUser reads the patched document of a user, refusing changes to the
+// fields that are not in fields, and fields that are not part of a user.
+func patchedUser(document []byte, patched []byte, fields []string) (*dto.UserResponseDTO, error) {
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(document, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return nil, &errcode.Error{Code: errcode.PatchNotApplicable, Message: "the patched user is not a JSON object", Err: err}
+	}
+
+	for _, field := range mergedKeys(before, after) {
+		if containsField(fields, field) || reflect.DeepEqual(before[field], after[field]) {
+			continue
+		}
+
+		return nil, &errcode.Error{
+			Code:    errcode.PatchReadOnlyField,
+			Field:   field,
+			Message: fmt.Sprintf("%q cannot be patched, only %v can", field, fields),
+		}
+	}
+
+	var user dto.UserResponseDTO
+	if err := json.Unmarshal(patched, &user); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, &errcode.Error{
+				Code:    errcode.PatchNotApplicable,
+				Field:   typeErr.Field,
+				Message: fmt.Sprintf("%q must be a %s", typeErr.Field, typeErr.Type),
+				Err:     err,
+			}
+		}
+
+		return nil, &errcode.Error{Code: errcode.PatchNotApplicable, Message: err.Error(), Err: err}
+	}
+
+	return &user, nil
+}
+
+// mergedKeys returns the keys of a and b, sorted.
+func mergedKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,141 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newPatchUserDocumentUsecase() (*usecase.PatchUserDocumentUsecase, *repository.MockUserRepository, *repository.MockUserEventRepository) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	patchUserDocument := usecase.NewPatchUserDocumentUsecase(mockRepo, usecase.NewUpdateUserUsecase(transactorFor(mockRepo, mockEvents)))
+
+	existing := existingUpdateUser()
+	existing.Version = 3
+	mockRepo.On("FindUserById", "1").Return(existing, nil)
+
+	return patchUserDocument, mockRepo, mockEvents
+}
+
+// TestPatchUserDocument_MergePatch verifies that a merge patch is saved at the
+// version it was applied to.
+func TestPatchUserDocument_MergePatch(t *testing.T) {
+	patchUserDocument, mockRepo, mockEvents := newPatchUserDocumentUsecase()
+
+	mockRepo.On("FindUserByEmail", "paul@example.com").Return(&entities.User{}, nil)
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(user *entities.User) bool {
+		return user.FirstName == "Paul" && user.LastName == "Lennon" && user.Email == "paul@example.com" && user.Version == 3
+	})).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	response, err := patchUserDocument.Execute("1", 0, usecase.PatchFormatMerge, []byte(`{"first_name":"Paul","email":"paul@example.com"}`), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Paul", response.FirstName)
+	assert.Equal(t, "Lennon", response.LastName)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestPatchUserDocument_ClearingField verifies that null clears the last
+// name, which is optional, while the required fields cannot be cleared.
+func TestPatchUserDocument_ClearingField(t *testing.T) {
+	patchUserDocument, mockRepo, mockEvents := newPatchUserDocumentUsecase()
+
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(user *entities.User) bool {
+		return user.FirstName == "John" && user.LastName == "" && user.Version == 3
+	})).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	response, err := patchUserDocument.Execute("1", 0, usecase.PatchFormatMerge, []byte(`{"last_name":null}`), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, response.LastName)
+
+	_, err = patchUserDocument.Execute("1", 0, usecase.PatchFormatJSON, []byte(`[{"op": "remove", "path": "/first_name"}]`), nil)
+	assert.Equal(t, entities.ErrFirstNameIsRequired, err)
+	mockRepo.AssertNumberOfCalls(t, "UpdateUser", 1)
+}
+
+// TestPatchUserDocument_Role verifies that the role can only be patched when
+// it is one of the fields the caller may change, and to a known role.
+func TestPatchUserDocument_Role(t *testing.T) {
+	patchUserDocument, mockRepo, mockEvents := newPatchUserDocumentUsecase()
+
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(user *entities.User) bool {
+		return user.Role == "admin" && user.LastName == "Lennon"
+	})).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	response, err := patchUserDocument.Execute("1", 0, usecase.PatchFormatMerge, []byte(`{"role":"admin"}`), usecase.PatchableUserFields)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", response.Role)
+
+	_, err = patchUserDocument.Execute("1", 0, usecase.PatchFormatMerge, []byte(`{"role":"owner"}`), usecase.PatchableUserFields)
+	assert.Equal(t, entities.ErrIncorrectRole, err)
+
+	_, err = patchUserDocument.Execute("1", 0, usecase.PatchFormatMerge, []byte(`{"role":"admin"}`), usecase.UpdatableUserFields)
+	assert.Equal(t, errcode.PatchReadOnlyField, errcode.Of(err))
+	mockRepo.AssertNumberOfCalls(t, "UpdateUser", 1)
+}
+
+// TestPatchUserDocument_JSONPatch verifies that test operations guard the
+// other operations of a JSON patch, and may read fields that cannot change.
+func TestPatchUserDocument_JSONPatch(t *testing.T) {
+	patchUserDocument, mockRepo, mockEvents := newPatchUserDocumentUsecase()
+
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(user *entities.User) bool {
+		return user.LastName == "Starr"
+	})).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	_, err := patchUserDocument.Execute("1", 3, usecase.PatchFormatJSON, []byte(`[
+		{"op": "test", "path": "/role", "value": "user"},
+		{"op": "replace", "path": "/last_name", "value": "Starr"}
+	]`), nil)
+	assert.NoError(t, err)
+
+	_, err = patchUserDocument.Execute("1", 0, usecase.PatchFormatJSON, []byte(`[
+		{"op": "test", "path": "/first_name", "value": "Ringo"},
+		{"op": "replace", "path": "/first_name", "value": "Paul"}
+	]`), nil)
+	assert.True(t, errors.Is(err, usecase.ErrPatchTestFailed))
+	mockRepo.AssertNumberOfCalls(t, "UpdateUser", 1)
+}
+
+// TestPatchUserDocument_Rejected verifies the patches that cannot be applied.
+func TestPatchUserDocument_Rejected(t *testing.T) {
+	patchUserDocument, mockRepo, _ := newPatchUserDocumentUsecase()
+
+	cases := []struct {
+		name   string
+		format string
+		patch  string
+		code   errcode.Code
+		field  string
+	}{
+		{"malformed", usecase.PatchFormatMerge, `{"first_name":`, errcode.PatchInvalid, ""},
+		{"not a patch", usecase.PatchFormatJSON, `{"op": "add"}`, errcode.PatchInvalid, ""},
+		{"read-only field", usecase.PatchFormatMerge, `{"role":"admin"}`, errcode.PatchReadOnlyField, "role"},
+		{"unknown field", usecase.PatchFormatJSON, `[{"op": "add", "path": "/password", "value": "secret"}]`, errcode.PatchReadOnlyField, "password"},
+		{"missing path", usecase.PatchFormatJSON, `[{"op": "remove", "path": "/nickname"}]`, errcode.PatchNotApplicable, ""},
+		{"wrong type", usecase.PatchFormatMerge, `{"first_name":5}`, errcode.PatchNotApplicable, "first_name"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := patchUserDocument.Execute("1", 0, c.format, []byte(c.patch), nil)
+			assert.Equal(t, c.code, errcode.Of(err))
+			assert.Equal(t, c.field, errcode.FieldOf(err))
+		})
+	}
+
+	_, err := patchUserDocument.Execute("1", 2, usecase.PatchFormatMerge, []byte(`{"first_name":"Paul"}`), nil)
+	assert.Equal(t, usecase.ErrVersionMismatch, err)
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/oklog/ulid/v2"
+)
+
+// ReplaceUserUsecase replaces the whole profile of a user, as a PUT does.
+// When createMissing is set, replacing an unknown ID creates the user with
+// that ID instead of failing with ErrUserNotFound.
+type ReplaceUserUsecase struct {
+	transactor    domain.UserTransactor
+	createMissing bool
+}
+
+func NewReplaceUserUsecase(transactor domain.UserTransactor, createMissing bool) *ReplaceUserUsecase {
+	return &ReplaceUserUsecase{transactor: transactor, createMissing: createMissing}
+}
+
+// CreatesMissing reports whether Execute creates users that do not exist.
+func (u *ReplaceUserUsecase) CreatesMissing() bool {
+	return u.createMissing
+}
+
+// Execute sets every mutable field of the user to the values in request,
+// including empty ones, and validates the result like a new user, minus the
+// password. The password is only used, and required, when the user is
+// created. created reports whether the user did not exist before.
+//
+// When version is set, the user is only replaced at that version, and never
+// created. The event of the change is recorded in the same transaction.
+func (u *ReplaceUserUsecase) Execute(id string, version int64, request *dto.UserRequestDTO) (response *dto.UserResponseDTO, created bool, err error) {
+	replacement := entities.User{
+		ID:        id,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		Email:     request.Email,
+		Region:    request.Region,
+	}
+
+	if err := replacement.ValidateProfile(); err != nil {
+		return nil, false, err
+	}
+
+	err = u.transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+		response, created, err = replaceUser(users, events, id, version, request, replacement, u.createMissing)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return response, created, nil
+}
+
+func replaceUser(users domain.UserRepository, events domain.UserEventRepository, id string, version int64, request *dto.UserRequestDTO, replacement entities.User, createMissing bool) (*dto.UserResponseDTO, bool, error) {
+	userExists, err := users.FindUserById(id)
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return nil, false, err
+	}
+
+	if userExists == nil && !createMissing {
+		return nil, false, ErrUserNotFound
+	}
+
+	if version != 0 && (userExists == nil || userExists.Version != version) {
+		return nil, false, ErrVersionMismatch
+	}
+
+	owner, err := users.FindUserByEmail(replacement.Email)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if owner != nil && owner.ID != "" && owner.ID != id {
+		return nil, false, ErrEmailAlreadyExists
+	}
+
+	now := time.Now()
+
+	if userExists == nil {
+		if _, err := ulid.ParseStrict(id); err != nil {
+			return nil, false, entities.ErrInvalidID
+		}
+
+		// The row of a deleted user keeps its ID.
+		deleted, err := users.IsUserDeleted(id)
+		if err != nil {
+			return nil, false, err
+		}
+		if deleted {
+			return nil, false, ErrUserDeleted
+		}
+
+		replacement.Password = request.Password
+		replacement.Role = "user"
+		replacement.CreatedAt = now
+		replacement.UpdatedAt = now
+
+		if err := replacement.Validate(); err != nil {
+			return nil, false, err
+		}
+
+		if err := users.CreateUser(&replacement); err != nil {
+			return nil, false, err
+		}
+
+		if err := events.AppendUserEvent(entities.NewUserEvent(entities.UserCreated, &replacement)); err != nil {
+			return nil, false, err
+		}
+
+		return replacedUserResponse(&replacement), true, nil
+	}
+
+	updatedUser := *userExists
+	updatedUser.FirstName = replacement.FirstName
+	updatedUser.LastName = replacement.LastName
+	updatedUser.Email = replacement.Email
+	updatedUser.Region = replacement.Region
+	updatedUser.UpdatedAt = now
+
+	if err := users.UpdateUser(&updatedUser); err != nil {
+		return nil, false, err
+	}
+
+	if err := events.AppendUserEvent(entities.NewUserEvent(entities.UserUpdated, &updatedUser)); err != nil {
+		return nil, false, err
+	}
+
+	return replacedUserResponse(&updatedUser), false, nil
+}
+
+func replacedUserResponse(user *entities.User) *dto.UserResponseDTO {
+	return &dto.UserResponseDTO{
+		ID:          user.ID,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Email:       user.Email,
+		Role:        user.Role,
+		Region:      user.Region,
+		CreateAt:    user.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdateAt:    user.UpdatedAt.Format("2006-01-02 15:04:05"),
+		CreatedTime: user.CreatedAt,
+		UpdatedTime: user.UpdatedAt,
+		Version:     user.Version,
+	}
+}
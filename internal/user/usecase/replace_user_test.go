@@ -0,0 +1,248 @@
+package usecase_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func existingReplaceUser() *entities.User {
+	return &entities.User{
+		ID:        "1",
+		FirstName: "John",
+		LastName:  "Lennon",
+		Email:     "john.lennon@example.com",
+		Password:  "password",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// TestReplaceUser_ReplacesEveryField verifies that every mutable field is
+// written and the password and role are kept.
+func TestReplaceUser_ReplacesEveryField(t *testing.T) {
+	// Create the mock repositories and the usecase.
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	replaceUserUsecase := usecase.NewReplaceUserUsecase(transactorFor(mockRepo, mockEvents), false)
+
+	// Mock the existing user, a free email, the full write and the change log.
+	mockRepo.On("FindUserById", "1").Return(existingReplaceUser(), nil)
+	mockRepo.On("FindUserByEmail", "paul@example.com").Return(&entities.User{}, nil)
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(user *entities.User) bool {
+		return user.FirstName == "Paul" && user.LastName == "McCartney" && user.Email == "paul@example.com" &&
+			user.Password == "password" && user.Role == "user"
+	})).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	response, created, err := replaceUserUsecase.Execute("1", 0, &dto.UserRequestDTO{
+		FirstName: "Paul",
+		LastName:  "McCartney",
+		Email:     "paul@example.com",
+	})
+
+	// Verify the new representation is returned.
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "Paul", response.FirstName)
+	assert.Equal(t, "McCartney", response.LastName)
+	assert.Equal(t, "paul@example.com", response.Email)
+	mockRepo.AssertExpectations(t)
+	mockEvents.AssertExpectations(t)
+}
+
+// TestReplaceUser_IncompleteResource verifies that a missing field is
+// rejected instead of being left unchanged, as it would be with PATCH.
+func TestReplaceUser_IncompleteResource(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	replaceUserUsecase := usecase.NewReplaceUserUsecase(transactorFor(mockRepo, mockEvents), false)
+
+	// Leave the first name out.
+	_, _, err := replaceUserUsecase.Execute("1", 0, &dto.UserRequestDTO{
+		LastName: "McCartney",
+		Email:    "paul@example.com",
+	})
+
+	// The request is rejected before the repository is touched.
+	assert.Equal(t, entities.ErrFirstNameIsRequired, err)
+	mockRepo.AssertNotCalled(t, "FindUserById", mock.Anything)
+}
+
+// TestReplaceUser_EmailCollision verifies that taking the email of another
+// user fails.
+func TestReplaceUser_EmailCollision(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	replaceUserUsecase := usecase.NewReplaceUserUsecase(transactorFor(mockRepo, mockEvents), false)
+
+	// The email belongs to user 2.
+	mockRepo.On("FindUserById", "1").Return(existingReplaceUser(), nil)
+	mockRepo.On("FindUserByEmail", "paul@example.com").Return(&entities.User{ID: "2", Email: "paul@example.com"}, nil)
+
+	_, _, err := replaceUserUsecase.Execute("1", 0, &dto.UserRequestDTO{
+		FirstName: "Paul",
+		LastName:  "McCartney",
+		Email:     "paul@example.com",
+	})
+
+	assert.Equal(t, usecase.ErrEmailAlreadyExists, err)
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+}
+
+// TestReplaceUser_UnknownIdNotFound verifies that an unknown ID fails when
+// creating missing users is disabled.
+func TestReplaceUser_UnknownIdNotFound(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	replaceUserUsecase := usecase.NewReplaceUserUsecase(transactorFor(mockRepo, mockEvents), false)
+
+	mockRepo.On("FindUserById", "1").Return((*entities.User)(nil), usecase.ErrUserNotFound)
+
+	_, _, err := replaceUserUsecase.Execute("1", 0, &dto.UserRequestDTO{
+		FirstName: "Paul",
+		LastName:  "McCartney",
+		Email:     "paul@example.com",
+	})
+
+	assert.Equal(t, usecase.ErrUserNotFound, err)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+// TestReplaceUser_CreatesMissingUser verifies that an unknown ID creates the
+// user with that ID when enabled.
+func TestReplaceUser_CreatesMissingUser(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	replaceUserUsecase := usecase.NewReplaceUserUsecase(transactorFor(mockRepo, mockEvents), true)
+
+	id := ulid.Make().String()
+
+	// Mock the missing user, a free email, the insert and the change log.
+	mockRepo.On("FindUserById", id).Return((*entities.User)(nil), usecase.ErrUserNotFound)
+	mockRepo.On("FindUserByEmail", "paul@example.com").Return(&entities.User{}, nil)
+	mockRepo.On("IsUserDeleted", id).Return(false, nil)
+	mockRepo.On("CreateUser", mock.MatchedBy(func(user *entities.User) bool {
+		return user.ID == id && user.Password == "password123" && user.Role == "user"
+	})).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	response, created, err := replaceUserUsecase.Execute(id, 0, &dto.UserRequestDTO{
+		FirstName: "Paul",
+		LastName:  "McCartney",
+		Email:     "paul@example.com",
+		Password:  "password123",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, id, response.ID)
+	mockRepo.AssertExpectations(t)
+	mockEvents.AssertExpectations(t)
+}
+
+// TestReplaceUser_CreateRequiresPassword verifies that creating through PUT
+// applies the full validation, password included.
+func TestReplaceUser_CreateRequiresPassword(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	replaceUserUsecase := usecase.NewReplaceUserUsecase(transactorFor(mockRepo, mockEvents), true)
+
+	id := ulid.Make().String()
+
+	mockRepo.On("FindUserById", id).Return((*entities.User)(nil), usecase.ErrUserNotFound)
+	mockRepo.On("FindUserByEmail", "paul@example.com").Return(&entities.User{}, nil)
+	mockRepo.On("IsUserDeleted", id).Return(false, nil)
+
+	_, _, err := replaceUserUsecase.Execute(id, 0, &dto.UserRequestDTO{
+		FirstName: "Paul",
+		LastName:  "McCartney",
+		Email:     "paul@example.com",
+	})
+
+	assert.Equal(t, entities.ErrPasswordIsRequired, err)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+// TestReplaceUser_DeletedUserGone verifies that the ID of a deleted user is
+// not created again, instead of failing on the row it still holds.
+func TestReplaceUser_DeletedUserGone(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	replaceUserUsecase := usecase.NewReplaceUserUsecase(transactorFor(mockRepo, mockEvents), true)
+
+	id := ulid.Make().String()
+
+	mockRepo.On("FindUserById", id).Return((*entities.User)(nil), usecase.ErrUserNotFound)
+	mockRepo.On("FindUserByEmail", "paul@example.com").Return(&entities.User{}, nil)
+	mockRepo.On("IsUserDeleted", id).Return(true, nil)
+
+	_, _, err := replaceUserUsecase.Execute(id, 0, &dto.UserRequestDTO{
+		FirstName: "Paul",
+		LastName:  "McCartney",
+		Email:     "paul@example.com",
+		Password:  "password123",
+	})
+
+	assert.Equal(t, usecase.ErrUserDeleted, err)
+	assert.Equal(t, http.StatusGone, errcode.Of(err).Definition().Status)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+	mockEvents.AssertNotCalled(t, "AppendUserEvent", mock.Anything)
+}
+
+// TestReplaceUser_CreateRequiresULID verifies that only well-formed IDs can
+// be created.
+func TestReplaceUser_CreateRequiresULID(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	replaceUserUsecase := usecase.NewReplaceUserUsecase(transactorFor(mockRepo, mockEvents), true)
+
+	mockRepo.On("FindUserById", "not-a-ulid").Return((*entities.User)(nil), usecase.ErrUserNotFound)
+	mockRepo.On("FindUserByEmail", "paul@example.com").Return(&entities.User{}, nil)
+
+	_, _, err := replaceUserUsecase.Execute("not-a-ulid", 0, &dto.UserRequestDTO{
+		FirstName: "Paul",
+		LastName:  "McCartney",
+		Email:     "paul@example.com",
+		Password:  "password123",
+	})
+
+	assert.Equal(t, entities.ErrInvalidID, err)
+	assert.True(t, entities.IsValidationError(err))
+}
+
+// TestReplaceUser_VersionMismatch verifies that an outdated version is
+// refused, and that an expected version never creates a missing user.
+func TestReplaceUser_VersionMismatch(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	replaceUserUsecase := usecase.NewReplaceUserUsecase(transactorFor(mockRepo, mockEvents), true)
+
+	existing := existingReplaceUser()
+	existing.Version = 4
+	mockRepo.On("FindUserById", "1").Return(existing, nil)
+
+	id := ulid.Make().String()
+	mockRepo.On("FindUserById", id).Return((*entities.User)(nil), usecase.ErrUserNotFound)
+
+	request := &dto.UserRequestDTO{FirstName: "Paul", LastName: "McCartney", Email: "paul@example.com", Password: "password123"}
+
+	_, _, err := replaceUserUsecase.Execute("1", 3, request)
+	assert.Equal(t, usecase.ErrVersionMismatch, err)
+
+	_, _, err = replaceUserUsecase.Execute(id, 1, request)
+	assert.Equal(t, usecase.ErrVersionMismatch, err)
+
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+var (
+	ErrInvitationNotFound   = errcode.New(errcode.InvitationNotFound, "invitation not found")
+	ErrInvitationNotPending = domain.ErrInvitationNotPending
+)
+
+type ResendInvitationUsecase struct {
+	invitations domain.InvitationRepository
+	users       domain.UserRepository
+}
+
+func NewResendInvitationUsecase(invitations domain.InvitationRepository, users domain.UserRepository) *ResendInvitationUsecase {
+	return &ResendInvitationUsecase{invitations: invitations, users: users}
+}
+
+// Execute issues a new token for a pending or expired invitation. The previous
+// token stops working and the expiration is pushed forward. An invitation is
+// not revived while another one for the same email is pending.
+func (u *ResendInvitationUsecase) Execute(id string, requestedBy string) (*dto.InvitationResponseDTO, error) {
+	if err := requireAdmin(u.users, requestedBy); err != nil {
+		return nil, err
+	}
+
+	invitation, err := u.invitations.FindInvitationById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	if invitation.Status != entities.InvitationPending && invitation.Status != entities.InvitationExpired {
+		return nil, ErrInvitationNotPending
+	}
+
+	pending, err := u.invitations.FindPendingInvitation(invitation.OrganizationID, invitation.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending != nil && pending.ID != invitation.ID && !pending.IsExpired(time.Now()) {
+		return nil, ErrInvitationAlreadyPending
+	}
+
+	token, err := invitation.RenewToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.invitations.UpdateInvitation(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInvitationResponse(invitation, token), nil
+}
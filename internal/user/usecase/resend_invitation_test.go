@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestResendInvitation tests the ResendInvitation usecase.
+// It verifies if resending rotates the token of the invitation.
+func TestResendInvitation(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+
+	resendInvitationUsecase := usecase.NewResendInvitationUsecase(mockInvitations, mockUsers)
+
+	// Create an existing invitation.
+	invitation, oldToken, err := entities.NewInvitation("org-1", "peter.parker@example.com", "user", "admin-1")
+	assert.NoError(t, err)
+
+	mockUsers.On("FindUserById", "admin-1").Return(&entities.User{ID: "admin-1", Role: "super"}, nil)
+	mockInvitations.On("FindInvitationById", invitation.ID).Return(invitation, nil)
+	mockInvitations.On("FindPendingInvitation", "org-1", "peter.parker@example.com").Return(invitation, nil)
+	mockInvitations.On("UpdateInvitation", mock.AnythingOfType("*entities.Invitation")).Return(nil)
+
+	// Execute the usecase.
+	response, err := resendInvitationUsecase.Execute(invitation.ID, "admin-1")
+
+	// Assert that a new token replaced the old one.
+	assert.NoError(t, err)
+	assert.NotEqual(t, oldToken, response.Token)
+	assert.Equal(t, entities.HashInvitationToken(response.Token), invitation.TokenHash)
+
+	mockInvitations.AssertExpectations(t)
+}
+
+// TestResendInvitation_Accepted verifies that accepted invitations cannot be resent.
+func TestResendInvitation_Accepted(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+
+	resendInvitationUsecase := usecase.NewResendInvitationUsecase(mockInvitations, mockUsers)
+
+	mockUsers.On("FindUserById", "admin-1").Return(&entities.User{ID: "admin-1", Role: "admin"}, nil)
+	mockInvitations.On("FindInvitationById", "1").Return(&entities.Invitation{ID: "1", Status: entities.InvitationAccepted}, nil)
+
+	// Execute the usecase.
+	_, err := resendInvitationUsecase.Execute("1", "admin-1")
+
+	// Assert that the invitation is no longer pending.
+	assert.Equal(t, usecase.ErrInvitationNotPending, err)
+}
+
+// TestResendInvitation_NewerPending verifies that an expired invitation is not
+// revived while a newer one for the same email is pending.
+func TestResendInvitation_NewerPending(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+
+	resendInvitationUsecase := usecase.NewResendInvitationUsecase(mockInvitations, mockUsers)
+
+	expired, _, err := entities.NewInvitation("org-1", "peter.parker@example.com", "user", "admin-1")
+	assert.NoError(t, err)
+	expired.Status = entities.InvitationExpired
+
+	newer, _, err := entities.NewInvitation("org-1", "peter.parker@example.com", "user", "admin-1")
+	assert.NoError(t, err)
+
+	mockUsers.On("FindUserById", "admin-1").Return(&entities.User{ID: "admin-1", Role: "admin"}, nil)
+	mockInvitations.On("FindInvitationById", expired.ID).Return(expired, nil)
+	mockInvitations.On("FindPendingInvitation", "org-1", "peter.parker@example.com").Return(newer, nil)
+
+	// Execute the usecase.
+	_, err = resendInvitationUsecase.Execute(expired.ID, "admin-1")
+
+	// Assert that the expired invitation stays expired.
+	assert.Equal(t, usecase.ErrInvitationAlreadyPending, err)
+	assert.Equal(t, entities.InvitationExpired, expired.Status)
+	mockInvitations.AssertNotCalled(t, "UpdateInvitation", mock.Anything)
+}
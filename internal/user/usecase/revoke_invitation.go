@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+)
+
+type RevokeInvitationUsecase struct {
+	invitations domain.InvitationRepository
+	users       domain.UserRepository
+}
+
+func NewRevokeInvitationUsecase(invitations domain.InvitationRepository, users domain.UserRepository) *RevokeInvitationUsecase {
+	return &RevokeInvitationUsecase{invitations: invitations, users: users}
+}
+
+func (u *RevokeInvitationUsecase) Execute(id string, requestedBy string) (*dto.InvitationResponseDTO, error) {
+	if err := requireAdmin(u.users, requestedBy); err != nil {
+		return nil, err
+	}
+
+	invitation, err := u.invitations.FindInvitationById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	if invitation.Status != entities.InvitationPending && invitation.Status != entities.InvitationExpired {
+		return nil, ErrInvitationNotPending
+	}
+
+	invitation.Status = entities.InvitationRevoked
+	invitation.RevokedAt = time.Now()
+	invitation.UpdatedAt = time.Now()
+
+	err = u.invitations.UpdateInvitation(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInvitationResponse(invitation, ""), nil
+}
@@ -0,0 +1,35 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestRevokeInvitation tests the RevokeInvitation usecase.
+// It verifies if a pending invitation is marked as revoked.
+func TestRevokeInvitation(t *testing.T) {
+	// Create the mock repositories.
+	mockInvitations := new(repository.MockInvitationRepository)
+	mockUsers := new(repository.MockUserRepository)
+
+	revokeInvitationUsecase := usecase.NewRevokeInvitationUsecase(mockInvitations, mockUsers)
+
+	mockUsers.On("FindUserById", "admin-1").Return(&entities.User{ID: "admin-1", Role: "admin"}, nil)
+	mockInvitations.On("FindInvitationById", "1").Return(&entities.Invitation{ID: "1", Status: entities.InvitationPending}, nil)
+	mockInvitations.On("UpdateInvitation", mock.AnythingOfType("*entities.Invitation")).Return(nil)
+
+	// Execute the usecase.
+	response, err := revokeInvitationUsecase.Execute("1", "admin-1")
+
+	// Assert that the invitation was revoked and no token was returned.
+	assert.NoError(t, err)
+	assert.Equal(t, entities.InvitationRevoked, response.Status)
+	assert.Empty(t, response.Token)
+
+	mockInvitations.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+	// MinSearchQueryLength avoids scanning the index for single letters,
+	// which would match almost every user.
+	MinSearchQueryLength = 2
+	MaxSearchQueryLength = 200
+)
+
+var (
+	ErrInvalidSearchQuery = errcode.New(errcode.SearchQueryInvalid, "param: 'q' must have between 2 and 200 characters")
+	ErrInvalidSearchLimit = errcode.New(errcode.SearchLimitInvalid, "invalid limit, enter a number between 1 and 100")
+)
+
+type SearchUsersUsecase struct {
+	index domain.UserSearchIndex
+}
+
+func NewSearchUsersUsecase(index domain.UserSearchIndex) *SearchUsersUsecase {
+	return &SearchUsersUsecase{index: index}
+}
+
+// Execute returns up to limit active users matching query, best matches
+// first. A limit of 0 means DefaultSearchLimit. No match is an empty result,
+// not an error.
+func (u *SearchUsersUsecase) Execute(query string, limit int) ([]*dto.UserSearchResultDTO, error) {
+	query = strings.TrimSpace(query)
+
+	length := utf8.RuneCountInString(query)
+	if length < MinSearchQueryLength || length > MaxSearchQueryLength || len(domain.SearchTerms(query)) == 0 {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	}
+
+	if limit < 1 || limit > MaxSearchLimit {
+		return nil, ErrInvalidSearchLimit
+	}
+
+	hits, err := u.index.SearchUsers(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*dto.UserSearchResultDTO, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, &dto.UserSearchResultDTO{
+			User: &dto.UserResponseDTO{
+				ID:          hit.User.ID,
+				FirstName:   hit.User.FirstName,
+				LastName:    hit.User.LastName,
+				Email:       hit.User.Email,
+				Role:        hit.User.Role,
+				Region:      hit.User.Region,
+				CreateAt:    hit.User.CreatedAt.Format("2006-01-02 15:04:05"),
+				UpdateAt:    hit.User.UpdatedAt.Format("2006-01-02 15:04:05"),
+				CreatedTime: hit.User.CreatedAt,
+				UpdatedTime: hit.User.UpdatedAt,
+				Version:     hit.User.Version,
+			},
+			Rank:       hit.Rank,
+			Highlights: hit.Highlights,
+		})
+	}
+
+	return results, nil
+}
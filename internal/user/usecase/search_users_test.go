@@ -0,0 +1,84 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestSearchUsers verifies that hits are returned in the order of the index
+// with their rank and highlights.
+func TestSearchUsers(t *testing.T) {
+	// Create the mock index and the usecase.
+	mockIndex := new(repository.MockUserSearchIndex)
+	searchUsersUsecase := usecase.NewSearchUsersUsecase(mockIndex)
+
+	john := &entities.User{ID: "1", FirstName: "John", LastName: "Lennon", Email: "john@example.com", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	johnny := &entities.User{ID: "2", FirstName: "Johnny", LastName: "Cash", Email: "cash@example.com", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	// Mock two hits, best first, searched with the default limit.
+	mockIndex.On("SearchUsers", "john", usecase.DefaultSearchLimit).Return([]*domain.UserSearchHit{
+		{User: john, Rank: 0.9, Highlights: map[string]string{"first_name": "<mark>John</mark>"}},
+		{User: johnny, Rank: 0.4, Highlights: map[string]string{"first_name": "<mark>John</mark>ny"}},
+	}, nil)
+
+	results, err := searchUsersUsecase.Execute("  john ", 0)
+
+	// Verify the order, ranks and highlights are kept.
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "1", results[0].User.ID)
+	assert.Equal(t, 0.9, results[0].Rank)
+	assert.Equal(t, "<mark>John</mark>ny", results[1].Highlights["first_name"])
+	mockIndex.AssertExpectations(t)
+}
+
+// TestSearchUsers_NoMatch verifies that no match is an empty result.
+func TestSearchUsers_NoMatch(t *testing.T) {
+	mockIndex := new(repository.MockUserSearchIndex)
+	searchUsersUsecase := usecase.NewSearchUsersUsecase(mockIndex)
+
+	mockIndex.On("SearchUsers", "nobody", 5).Return([]*domain.UserSearchHit(nil), nil)
+
+	results, err := searchUsersUsecase.Execute("nobody", 5)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, results)
+	assert.Empty(t, results)
+}
+
+// TestSearchUsers_InvalidRequest verifies that the query and limit are
+// checked before the index is touched.
+func TestSearchUsers_InvalidRequest(t *testing.T) {
+	mockIndex := new(repository.MockUserSearchIndex)
+	searchUsersUsecase := usecase.NewSearchUsersUsecase(mockIndex)
+
+	for _, query := range []string{"", " j ", "%%", string(make([]byte, usecase.MaxSearchQueryLength+1))} {
+		_, err := searchUsersUsecase.Execute(query, 0)
+		assert.Equal(t, usecase.ErrInvalidSearchQuery, err)
+	}
+
+	_, err := searchUsersUsecase.Execute("john", usecase.MaxSearchLimit+1)
+	assert.Equal(t, usecase.ErrInvalidSearchLimit, err)
+
+	mockIndex.AssertNotCalled(t, "SearchUsers", mock.Anything, mock.Anything)
+}
+
+// TestSearchUsers_IndexError verifies that index failures are returned.
+func TestSearchUsers_IndexError(t *testing.T) {
+	mockIndex := new(repository.MockUserSearchIndex)
+	searchUsersUsecase := usecase.NewSearchUsersUsecase(mockIndex)
+
+	mockIndex.On("SearchUsers", "john", usecase.DefaultSearchLimit).Return([]*domain.UserSearchHit(nil), errors.New("index unavailable"))
+
+	_, err := searchUsersUsecase.Execute("john", 0)
+
+	assert.EqualError(t, err, "index unavailable")
+}
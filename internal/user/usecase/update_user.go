@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	dto "github.com/jonattasmoraes/titan/internal/user/domain/DTO"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/domain/errcode"
+)
+
+var ErrInvalidUpdateMask = errcode.New(errcode.InvalidUpdateMask, "invalid update mask")
+
+// UpdatableUserFields are the field names an update mask may contain.
+var UpdatableUserFields = []string{"first_name", "last_name", "email", "region"}
+
+// PatchableUserFields are the fields a patch document may change: the
+// UpdatableUserFields and the role, which callers also need user.update_role
+// for.
+var PatchableUserFields = []string{"first_name", "last_name", "email", "region", "role"}
+
+type UpdateUserUsecase struct {
+	transactor domain.UserTransactor
+}
+
+func NewUpdateUserUsecase(transactor domain.UserTransactor) *UpdateUserUsecase {
+	return &UpdateUserUsecase{transactor: transactor}
+}
+
+// Execute changes exactly the fields listed in paths, so a field can be set to
+// an empty value. Without paths every non-empty field of user is applied, like
+// a patch. The resulting user is validated as a whole before it is saved.
+// When user.Version is set, the user is only changed at that version. The
+// event of the change is recorded in the same transaction.
+func (u *UpdateUserUsecase) Execute(user *entities.User, paths []string) (*dto.UserResponseDTO, error) {
+	if len(paths) == 0 {
+		paths = nonEmptyFields(user)
+		if len(paths) == 0 {
+			return nil, entities.ErrAtLeastOneParam
+		}
+	}
+
+	for _, path := range paths {
+		if !containsField(UpdatableUserFields, path) {
+			return nil, fmt.Errorf("%w: %q cannot be updated, use one of %v", ErrInvalidUpdateMask, path, UpdatableUserFields)
+		}
+	}
+
+	return u.update(user, paths)
+}
+
+// update changes the fields of user listed in paths, which may be any of the
+// PatchableUserFields.
+func (u *UpdateUserUsecase) update(user *entities.User, paths []string) (*dto.UserResponseDTO, error) {
+	var updatedUser entities.User
+
+	err := u.transactor.WithinTransaction(func(users domain.UserRepository, events domain.UserEventRepository) error {
+		userExists, err := users.FindUserById(user.ID)
+		if err != nil {
+			return err
+		}
+
+		if userExists == nil {
+			return ErrUserNotFound
+		}
+
+		if user.Version != 0 && user.Version != userExists.Version {
+			return ErrVersionMismatch
+		}
+
+		updatedUser = *userExists
+		updatedUser.UpdatedAt = time.Now()
+
+		for _, path := range paths {
+			switch path {
+			case "first_name":
+				updatedUser.FirstName = user.FirstName
+			case "last_name":
+				updatedUser.LastName = user.LastName
+			case "email":
+				updatedUser.Email = user.Email
+			case "region":
+				updatedUser.Region = user.Region
+			case "role":
+				updatedUser.Role = user.Role
+			}
+		}
+
+		if err := updatedUser.ValidateProfile(); err != nil {
+			return err
+		}
+
+		if containsField(paths, "role") && !entities.IsValidRole(updatedUser.Role) {
+			return entities.ErrIncorrectRole
+		}
+
+		if updatedUser.Email != userExists.Email {
+			owner, err := users.FindUserByEmail(updatedUser.Email)
+			if err != nil {
+				return err
+			}
+
+			if owner != nil && owner.ID != "" && owner.ID != updatedUser.ID {
+				return ErrEmailAlreadyExists
+			}
+		}
+
+		err = users.UpdateUser(&updatedUser)
+		if err != nil {
+			return err
+		}
+
+		return events.AppendUserEvent(entities.NewUserEvent(entities.UserUpdated, &updatedUser))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.UserResponseDTO{
+		ID:          updatedUser.ID,
+		FirstName:   updatedUser.FirstName,
+		LastName:    updatedUser.LastName,
+		Email:       updatedUser.Email,
+		Role:        updatedUser.Role,
+		Region:      updatedUser.Region,
+		CreateAt:    updatedUser.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdateAt:    updatedUser.UpdatedAt.Format("2006-01-02 15:04:05"),
+		CreatedTime: updatedUser.CreatedAt,
+		UpdatedTime: updatedUser.UpdatedAt,
+		Version:     updatedUser.Version,
+	}
+
+	return response, nil
+}
+
+func containsField(fields []string, path string) bool {
+	for _, field := range fields {
+		if path == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func nonEmptyFields(user *entities.User) []string {
+	var paths []string
+
+	if user.FirstName != "" {
+		paths = append(paths, "first_name")
+	}
+	if user.LastName != "" {
+		paths = append(paths, "last_name")
+	}
+	if user.Email != "" {
+		paths = append(paths, "email")
+	}
+	if user.Region != "" {
+		paths = append(paths, "region")
+	}
+
+	return paths
+}
@@ -0,0 +1,130 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func existingUpdateUser() *entities.User {
+	return &entities.User{
+		ID:        "1",
+		FirstName: "John",
+		LastName:  "Lennon",
+		Email:     "john.lennon@example.com",
+		Password:  "password",
+		Role:      "user",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// TestUpdateUser_OnlyMaskedFields verifies that only the fields named in the
+// mask are written.
+func TestUpdateUser_OnlyMaskedFields(t *testing.T) {
+	// Create the mock repositories and the usecase.
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	updateUserUsecase := usecase.NewUpdateUserUsecase(transactorFor(mockRepo, mockEvents))
+
+	// Mock the existing user, the full write and the change log.
+	mockRepo.On("FindUserById", "1").Return(existingUpdateUser(), nil)
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(user *entities.User) bool {
+		return user.FirstName == "Peter" && user.LastName == "Lennon" && user.Email == "john.lennon@example.com"
+	})).Return(nil)
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	// The last name and email are set in the request but not in the mask.
+	response, err := updateUserUsecase.Execute(&entities.User{
+		ID:        "1",
+		FirstName: "Peter",
+		LastName:  "Parker",
+		Email:     "peter.parker@example.com",
+	}, []string{"first_name"})
+
+	// Verify that only the first name changed.
+	assert.NoError(t, err)
+	assert.Equal(t, "Peter", response.FirstName)
+	assert.Equal(t, "Lennon", response.LastName)
+	mockRepo.AssertExpectations(t)
+	mockEvents.AssertExpectations(t)
+}
+
+// TestUpdateUser_Region verifies that the region is written when it is in
+// the mask, and cleared when it is empty.
+func TestUpdateUser_Region(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	updateUserUsecase := usecase.NewUpdateUserUsecase(transactorFor(mockRepo, mockEvents))
+
+	existing := existingUpdateUser()
+	existing.Region = "south"
+	mockRepo.On("FindUserById", "1").Return(existing, nil)
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(user *entities.User) bool {
+		return user.Region == "north" && user.FirstName == "John"
+	})).Return(nil).Once()
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(user *entities.User) bool {
+		return user.Region == ""
+	})).Return(nil).Once()
+	mockEvents.On("AppendUserEvent", mock.AnythingOfType("*entities.UserEvent")).Return(nil)
+
+	response, err := updateUserUsecase.Execute(&entities.User{ID: "1", Region: "north"}, []string{"region"})
+	assert.NoError(t, err)
+	assert.Equal(t, "north", response.Region)
+
+	response, err = updateUserUsecase.Execute(&entities.User{ID: "1"}, []string{"region"})
+	assert.NoError(t, err)
+	assert.Empty(t, response.Region)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUpdateUser_ClearingRequiredField verifies that a cleared field is
+// validated instead of being ignored.
+func TestUpdateUser_ClearingRequiredField(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	updateUserUsecase := usecase.NewUpdateUserUsecase(transactorFor(mockRepo, mockEvents))
+
+	mockRepo.On("FindUserById", "1").Return(existingUpdateUser(), nil)
+
+	// Clear the first name through the mask.
+	_, err := updateUserUsecase.Execute(&entities.User{ID: "1"}, []string{"first_name"})
+
+	// The whole user is validated, so the cleared first name is rejected.
+	assert.Equal(t, entities.ErrFirstNameIsRequired, err)
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+}
+
+// TestUpdateUser_InvalidMask verifies that fields outside the updatable set
+// are rejected before the repository is touched.
+func TestUpdateUser_InvalidMask(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	updateUserUsecase := usecase.NewUpdateUserUsecase(transactorFor(mockRepo, mockEvents))
+
+	_, err := updateUserUsecase.Execute(&entities.User{ID: "1", Role: "admin"}, []string{"role"})
+
+	assert.True(t, errors.Is(err, usecase.ErrInvalidUpdateMask))
+	mockRepo.AssertNotCalled(t, "FindUserById", mock.Anything)
+}
+
+// TestUpdateUser_EmailTaken verifies that a new email already used by another
+// user is rejected.
+func TestUpdateUser_EmailTaken(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	mockEvents := new(repository.MockUserEventRepository)
+	updateUserUsecase := usecase.NewUpdateUserUsecase(transactorFor(mockRepo, mockEvents))
+
+	mockRepo.On("FindUserById", "1").Return(existingUpdateUser(), nil)
+	mockRepo.On("FindUserByEmail", "paul@example.com").Return(&entities.User{ID: "2", Email: "paul@example.com"}, nil)
+
+	_, err := updateUserUsecase.Execute(&entities.User{ID: "1", Email: "paul@example.com"}, []string{"email"})
+
+	assert.Equal(t, usecase.ErrEmailAlreadyExists, err)
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ETag is the strong entity tag of a resource at version, shared by the HTTP
+// and gRPC APIs so a tag read from one can be sent to the other.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseETag returns the version of a strong entity tag made by ETag. Weak
+// tags are rejected.
+func ParseETag(tag string) (int64, bool) {
+	if len(tag) < 3 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+
+	return version, true
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,10 +15,19 @@ func SendSuccess(ctx *gin.Context, op string, data interface{}, status int) {
 	})
 }
 
-func SendError(ctx *gin.Context, code int, msg string) {
+// SendList is SendSuccess for paginated lists. It adds the cursor of the next
+// page, empty on the last one, and the total count when it is known.
+func SendList(ctx *gin.Context, op string, data interface{}, nextCursor string, totalCount *int) {
+	body := gin.H{
+		"message":     fmt.Sprintf("operation from handler: %s, successful.", op),
+		"data":        data,
+		"next_cursor": nextCursor,
+	}
+
+	if totalCount != nil {
+		body["total_count"] = *totalCount
+	}
+
 	ctx.Header("Content-type", "application/json")
-	ctx.JSON(code, gin.H{
-		"message":   msg,
-		"errorCode": code,
-	})
+	ctx.JSON(http.StatusOK, body)
 }
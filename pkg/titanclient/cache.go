@@ -0,0 +1,99 @@
+package titanclient
+
+import (
+	"sync"
+	"time"
+)
+
+type cachedUser struct {
+	user      User
+	expiresAt time.Time
+}
+
+// userCache is a small TTL cache for GetUserByID. When full, the entry
+// closest to expiring is evicted.
+//
+// Every delete starts a new generation, and a user read in an older
+// generation is not stored: a read that raced with a write may have fetched
+// the user as it was before the write.
+type userCache struct {
+	ttl  time.Duration
+	size int
+
+	mu         sync.Mutex
+	entries    map[string]cachedUser
+	generation uint64
+}
+
+func newUserCache(ttl time.Duration, size int) *userCache {
+	if size < 1 {
+		size = 1
+	}
+
+	return &userCache{ttl: ttl, size: size, entries: map[string]cachedUser{}}
+}
+
+func (c *userCache) get(id string) (*User, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, id)
+		return nil, false
+	}
+
+	user := entry.user
+	return &user, true
+}
+
+// current returns the generation to store the users read from now on with.
+func (c *userCache) current() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// set stores user, unless it was read before the last delete started.
+func (c *userCache) set(user *User, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if _, ok := c.entries[user.ID]; !ok && len(c.entries) >= c.size {
+		c.evict()
+	}
+
+	c.entries[user.ID] = cachedUser{user: *user, expiresAt: time.Now().Add(c.ttl)}
+}
+
+func (c *userCache) delete(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, id)
+	c.generation++
+}
+
+func (c *userCache) evict() {
+	var (
+		oldestID string
+		oldest   time.Time
+	)
+
+	for id, entry := range c.entries {
+		if oldestID == "" || entry.expiresAt.Before(oldest) {
+			oldestID, oldest = id, entry.expiresAt
+		}
+	}
+
+	delete(c.entries, oldestID)
+}
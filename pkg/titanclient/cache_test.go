@@ -0,0 +1,27 @@
+package titanclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCache_DropsReadsOlderThanAWrite(t *testing.T) {
+	cache := newUserCache(time.Minute, 10)
+
+	// A read starts, then a write to the user ends before the read does.
+	generation := cache.current()
+	cache.delete("1")
+	cache.set(&User{ID: "1", FirstName: "John"}, generation)
+
+	_, ok := cache.get("1")
+	assert.False(t, ok, "a user read before the write must not be cached")
+
+	// Reads started after the write are cached.
+	cache.set(&User{ID: "1", FirstName: "Paul"}, cache.current())
+
+	user, ok := cache.get("1")
+	assert.True(t, ok)
+	assert.Equal(t, "Paul", user.FirstName)
+}
@@ -0,0 +1,306 @@
+// Package titanclient is the Go client for the Titan user service. The same
+// typed Client works over gRPC (NewGRPCClient) or over the JSON gateway
+// served on the HTTP port (NewHTTPClient).
+//
+//	client, err := titanclient.NewGRPCClient("titan:50051",
+//		titanclient.WithToken(token),
+//		titanclient.WithTimeout(2*time.Second),
+//		titanclient.WithCache(time.Minute, 1000),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	user, err := client.GetUserByID(ctx, id)
+//	if errors.Is(err, titanclient.ErrUserNotFound) {
+//		...
+//	}
+package titanclient
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+type User struct {
+	ID        string
+	FirstName string
+	LastName  string
+	Email     string
+	Role      string
+	Region    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt is zero unless the user was listed with deleted users.
+	DeletedAt time.Time
+	// ETag is the version of the user. When set on the user passed to
+	// UpdateUser, the update fails with ErrConflict unless the user still has
+	// it.
+	ETag string
+}
+
+type CreateUserRequest struct {
+	FirstName string
+	LastName  string
+	Email     string
+	Password  string
+	Region    string
+}
+
+// PatchUserRequest changes the non-empty fields only.
+type PatchUserRequest struct {
+	FirstName string
+	LastName  string
+	Email     string
+	Region    string
+	// ETag, when set, makes the patch fail with ErrConflict unless the user
+	// still has it.
+	ETag string
+}
+
+// ListUsersRequest selects the users ListUsers returns. Every field is
+// optional.
+type ListUsersRequest struct {
+	// PageSize defaults to 10, at most 100.
+	PageSize int
+	// Cursor is the NextCursor of the previous page, empty for the first one.
+	// It is only valid with the same filters and order.
+	Cursor string
+	// Page is the offset pagination of older callers, prefer Cursor.
+	Page         int
+	IncludeTotal bool
+	// Role is admin, super or user.
+	Role string
+	// EmailDomain keeps the users whose email is at this domain, such as
+	// example.com.
+	EmailDomain string
+	// Ranges include their start and exclude their end. Zero times leave
+	// them open.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	// Deleted is exclude, the default, include or only.
+	Deleted string
+	// Sort is id, the default, created_at, updated_at, email, first_name or
+	// last_name.
+	Sort string
+	// Order is asc, the default, or desc.
+	Order string
+}
+
+// UserPage is a page of users.
+type UserPage struct {
+	Users []*User
+	// NextCursor is the Cursor of the next page, empty on the last page.
+	NextCursor string
+	// TotalCount is set only when IncludeTotal was requested.
+	TotalCount *int64
+}
+
+// transport is implemented by the gRPC and HTTP clients. Errors are already
+// converted to *Error.
+type transport interface {
+	getUserByID(ctx context.Context, id string) (*User, error)
+	getUserByEmail(ctx context.Context, email string) (*User, error)
+	batchGetUsers(ctx context.Context, ids []string) ([]*User, []string, error)
+	listUsers(ctx context.Context, req ListUsersRequest) (*UserPage, error)
+	createUser(ctx context.Context, req CreateUserRequest) (*User, error)
+	patchUser(ctx context.Context, id string, req PatchUserRequest) (*User, error)
+	updateUser(ctx context.Context, user *User, fields []string) (*User, error)
+	deleteUser(ctx context.Context, id string, etag string) (*User, error)
+	close() error
+}
+
+// Client calls Titan with retries, per-call timeouts and an optional cache
+// for GetUserByID. It is safe for concurrent use.
+type Client struct {
+	transport transport
+	options   options
+	cache     *userCache
+}
+
+func newClient(t transport, opts options) *Client {
+	client := &Client{transport: t, options: opts}
+	if opts.cacheTTL > 0 {
+		client.cache = newUserCache(opts.cacheTTL, opts.cacheSize)
+	}
+
+	return client
+}
+
+// GetUserByID returns the user with the given ID, from the cache when one is
+// configured and holds a fresh copy.
+func (c *Client) GetUserByID(ctx context.Context, id string) (*User, error) {
+	var generation uint64
+	if c.cache != nil {
+		if user, ok := c.cache.get(id); ok {
+			return user, nil
+		}
+		generation = c.cache.current()
+	}
+
+	var user *User
+	err := c.call(ctx, idempotent, func(ctx context.Context) (err error) {
+		user, err = c.transport.getUserByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		c.cache.set(user, generation)
+	}
+
+	return user, nil
+}
+
+func (c *Client) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	var user *User
+	err := c.call(ctx, idempotent, func(ctx context.Context) (err error) {
+		user, err = c.transport.getUserByEmail(ctx, email)
+		return err
+	})
+
+	return user, err
+}
+
+// BatchGetUsers resolves ids with a single request. Users come back in the
+// order they were asked for; IDs without a user are returned in missing.
+func (c *Client) BatchGetUsers(ctx context.Context, ids []string) (users []*User, missing []string, err error) {
+	err = c.call(ctx, idempotent, func(ctx context.Context) (err error) {
+		users, missing, err = c.transport.batchGetUsers(ctx, ids)
+		return err
+	})
+
+	return users, missing, err
+}
+
+// ListUsers returns a page of the users matched by req. Pass the NextCursor
+// of the page back in req.Cursor, with the same filters and order, to read
+// the next one.
+func (c *Client) ListUsers(ctx context.Context, req ListUsersRequest) (*UserPage, error) {
+	var page *UserPage
+	err := c.call(ctx, idempotent, func(ctx context.Context) (err error) {
+		page, err = c.transport.listUsers(ctx, req)
+		return err
+	})
+
+	return page, err
+}
+
+// CreateUser is not retried: a retry after a lost answer could create the
+// user twice or fail with ErrEmailAlreadyExists.
+func (c *Client) CreateUser(ctx context.Context, req CreateUserRequest) (*User, error) {
+	var user *User
+	err := c.call(ctx, once, func(ctx context.Context) (err error) {
+		user, err = c.transport.createUser(ctx, req)
+		return err
+	})
+
+	return user, err
+}
+
+// PatchUser is attempted once, as every write.
+func (c *Client) PatchUser(ctx context.Context, id string, req PatchUserRequest) (*User, error) {
+	defer c.forget(id)
+
+	var user *User
+	err := c.call(ctx, once, func(ctx context.Context) (err error) {
+		user, err = c.transport.patchUser(ctx, id, req)
+		return err
+	})
+
+	return user, err
+}
+
+// UpdateUser writes exactly the named fields of user (first_name, last_name,
+// email and region), empty values included. Without fields every non-empty
+// field is written. It is attempted once, as every write.
+func (c *Client) UpdateUser(ctx context.Context, user *User, fields ...string) (*User, error) {
+	defer c.forget(user.ID)
+
+	var updated *User
+	err := c.call(ctx, once, func(ctx context.Context) (err error) {
+		updated, err = c.transport.updateUser(ctx, user, fields)
+		return err
+	})
+
+	return updated, err
+}
+
+// DeleteUser is not retried: a retry after a lost answer would fail with
+// ErrUserNotFound. A non-empty etag makes the delete fail with ErrConflict
+// unless the user still has it.
+func (c *Client) DeleteUser(ctx context.Context, id string, etag string) (*User, error) {
+	defer c.forget(id)
+
+	var user *User
+	err := c.call(ctx, once, func(ctx context.Context) (err error) {
+		user, err = c.transport.deleteUser(ctx, id, etag)
+		return err
+	})
+
+	return user, err
+}
+
+// Close releases the underlying connection.
+func (c *Client) Close() error {
+	return c.transport.close()
+}
+
+// forget drops the cached copy of a user once a write to it ended, whatever
+// its outcome: a failed write may still have been applied.
+func (c *Client) forget(id string) {
+	if c.cache != nil {
+		c.cache.delete(id)
+	}
+}
+
+// retryPolicy tells call whether a failed call may be sent again.
+type retryPolicy bool
+
+const (
+	// idempotent calls only read, so they are retried.
+	idempotent retryPolicy = true
+	// once calls write, and may have been applied when their answer was
+	// lost, so they are attempted once.
+	once retryPolicy = false
+)
+
+// call runs fn with the per-call timeout. Idempotent calls are retried on
+// transient failures with exponential backoff and jitter until the attempts
+// run out or ctx ends.
+func (c *Client) call(ctx context.Context, retry retryPolicy, fn func(ctx context.Context) error) error {
+	backoff := c.options.retry.initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if c.options.timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, c.options.timeout)
+		}
+
+		err := fn(callCtx)
+		cancel()
+
+		if err == nil || retry == once || !IsTransient(err) || attempt >= c.options.retry.maxAttempts {
+			return err
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > c.options.retry.maxBackoff {
+			backoff = c.options.retry.maxBackoff
+		}
+	}
+}
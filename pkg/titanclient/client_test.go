@@ -0,0 +1,373 @@
+package titanclient_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"github.com/jonattasmoraes/titan/internal/user/infra/server"
+	"github.com/jonattasmoraes/titan/pkg/titanclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeUserService serves a single user and can fail the next calls.
+type fakeUserService struct {
+	pb.UnimplementedUserServiceServer
+
+	mu            sync.Mutex
+	calls         int
+	failures      int
+	authorization string
+	updateMask    []string
+	updateETag    string
+	deleteETag    string
+	listRequest   *pb.ListUsersRequest
+}
+
+func (s *fakeUserService) record(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		s.authorization = md.Get("authorization")[0]
+	}
+
+	if s.failures > 0 {
+		s.failures--
+		return status.Error(codes.Unavailable, "the service is temporarily unavailable, please retry")
+	}
+
+	return nil
+}
+
+func (s *fakeUserService) GetUserByID(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+
+	if req.Id != "1" {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	return &pb.GetUserResponse{Id: "1", FirstName: "John", Email: "john@example.com", CreatedAt: timestamppb.Now()}, nil
+}
+
+func (s *fakeUserService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+
+	st, _ := status.New(codes.InvalidArgument, "invalid email").WithDetails(
+		&errdetails.ErrorInfo{Reason: "user.validation.email_invalid", Domain: "titan"},
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "email", Description: "invalid email"}},
+		},
+	)
+
+	return nil, st.Err()
+}
+
+func (s *fakeUserService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.updateMask = req.UpdateMask.GetPaths()
+	s.updateETag = req.User.GetEtag()
+	s.mu.Unlock()
+
+	return req.User, nil
+}
+
+// PatchUser fails as for a user deleted since it was read.
+func (s *fakeUserService) PatchUser(ctx context.Context, req *pb.PatchUserRequest) (*pb.User, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+
+	st, _ := status.New(codes.FailedPrecondition, "the user was deleted").WithDetails(
+		&errdetails.ErrorInfo{Reason: "user.deleted", Domain: "titan"},
+	)
+
+	return nil, st.Err()
+}
+
+func (s *fakeUserService) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.User, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.deleteETag = req.Etag
+	s.mu.Unlock()
+
+	return nil, status.Error(codes.Aborted, "the user changed since it was read")
+}
+
+func (s *fakeUserService) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.listRequest = req
+	s.mu.Unlock()
+
+	total := int64(2)
+
+	return &pb.ListUsersResponse{
+		Users:      []*pb.User{{Id: "1", FirstName: "John", Etag: `"3"`, DeletedAt: timestamppb.Now()}},
+		NextCursor: "next",
+		TotalCount: &total,
+	}, nil
+}
+
+func (s *fakeUserService) failNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = n
+}
+
+func (s *fakeUserService) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
+
+func startFakeServer(t *testing.T) (*fakeUserService, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	service := &fakeUserService{}
+	grpcServer := grpc.NewServer()
+	pb.RegisterUserServiceServer(grpcServer, service)
+
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	return service, listener.Addr().String()
+}
+
+// newClients returns a gRPC client and an HTTP client, through the gateway,
+// for the same fake server.
+func newClients(t *testing.T, opts ...titanclient.Option) (*fakeUserService, map[string]*titanclient.Client) {
+	service, address := startFakeServer(t)
+
+	grpcClient, err := titanclient.NewGRPCClient(address, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { grpcClient.Close() })
+
+	gateway, err := server.NewGateway(context.Background(), address)
+	require.NoError(t, err)
+
+	httpServer := httptest.NewServer(gateway)
+	t.Cleanup(httpServer.Close)
+
+	httpClient, err := titanclient.NewHTTPClient(httpServer.URL, opts...)
+	require.NoError(t, err)
+
+	return service, map[string]*titanclient.Client{"grpc": grpcClient, "http": httpClient}
+}
+
+func TestClient_TypedErrors(t *testing.T) {
+	_, clients := newClients(t)
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.GetUserByID(context.Background(), "2")
+			assert.True(t, errors.Is(err, titanclient.ErrUserNotFound))
+
+			_, err = client.CreateUser(context.Background(), titanclient.CreateUserRequest{Email: "invalid"})
+			assert.True(t, errors.Is(err, titanclient.ErrInvalidArgument))
+
+			var titanErr *titanclient.Error
+			require.True(t, errors.As(err, &titanErr))
+			assert.Equal(t, "email", titanErr.Field)
+			assert.Equal(t, "user.validation.email_invalid", titanErr.Reason)
+		})
+	}
+}
+
+func TestClient_RetriesTransientErrors(t *testing.T) {
+	service, clients := newClients(t,
+		titanclient.WithRetry(3, time.Millisecond, 5*time.Millisecond),
+		titanclient.WithToken("secret"),
+	)
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			before := service.callCount()
+			service.failNext(2)
+
+			user, err := client.GetUserByID(context.Background(), "1")
+
+			require.NoError(t, err)
+			assert.Equal(t, "John", user.FirstName)
+			assert.Equal(t, 3, service.callCount()-before)
+			assert.Equal(t, "Bearer secret", service.authorization)
+		})
+	}
+}
+
+func TestClient_DoesNotRetryWrites(t *testing.T) {
+	service, clients := newClients(t, titanclient.WithRetry(3, time.Millisecond, 5*time.Millisecond))
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			before := service.callCount()
+			service.failNext(2)
+
+			_, err := client.CreateUser(context.Background(), titanclient.CreateUserRequest{Email: "john@example.com"})
+
+			assert.True(t, errors.Is(err, titanclient.ErrUnavailable))
+			assert.Equal(t, 1, service.callCount()-before)
+			service.failNext(0)
+		})
+	}
+}
+
+func TestClient_ConflictsAreNotTransient(t *testing.T) {
+	service, clients := newClients(t, titanclient.WithRetry(3, time.Millisecond, 5*time.Millisecond))
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			before := service.callCount()
+
+			_, err := client.DeleteUser(context.Background(), "1", `"1"`)
+
+			assert.Equal(t, `"1"`, service.deleteETag)
+			assert.True(t, errors.Is(err, titanclient.ErrConflict))
+			assert.False(t, errors.Is(err, titanclient.ErrUnavailable))
+			assert.False(t, titanclient.IsTransient(err))
+			assert.Equal(t, 1, service.callCount()-before)
+		})
+	}
+}
+
+func TestClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	service, clients := newClients(t, titanclient.WithRetry(2, time.Millisecond, time.Millisecond))
+
+	service.failNext(5)
+	_, err := clients["grpc"].GetUserByID(context.Background(), "1")
+
+	assert.True(t, errors.Is(err, titanclient.ErrUnavailable))
+	assert.Equal(t, 2, service.callCount())
+}
+
+func TestClient_CachesGetUserByID(t *testing.T) {
+	service, clients := newClients(t, titanclient.WithCache(time.Minute, 10))
+	client := clients["grpc"]
+
+	first, err := client.GetUserByID(context.Background(), "1")
+	require.NoError(t, err)
+
+	second, err := client.GetUserByID(context.Background(), "1")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, service.callCount())
+
+	// Updating through the client drops the cached copy.
+	_, err = client.UpdateUser(context.Background(), &titanclient.User{ID: "1", FirstName: "Paul"}, "first_name")
+	require.NoError(t, err)
+
+	_, err = client.GetUserByID(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, service.callCount())
+}
+
+func TestClient_UpdateUserSendsMask(t *testing.T) {
+	service, clients := newClients(t)
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.UpdateUser(context.Background(), &titanclient.User{ID: "1", FirstName: "Paul"}, "first_name", "last_name")
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"first_name", "last_name"}, service.updateMask)
+		})
+	}
+}
+
+func TestClient_UpdateUserSendsETag(t *testing.T) {
+	service, clients := newClients(t)
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.UpdateUser(context.Background(), &titanclient.User{ID: "1", FirstName: "Paul", ETag: `"2"`})
+
+			require.NoError(t, err)
+			assert.Equal(t, `"2"`, service.updateETag)
+		})
+	}
+}
+
+func TestClient_DeletedUser(t *testing.T) {
+	_, clients := newClients(t)
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.PatchUser(context.Background(), "1", titanclient.PatchUserRequest{FirstName: "Paul"})
+
+			assert.True(t, errors.Is(err, titanclient.ErrUserDeleted))
+			assert.False(t, errors.Is(err, titanclient.ErrInternal))
+		})
+	}
+}
+
+func TestClient_ListUsers(t *testing.T) {
+	service, clients := newClients(t)
+	createdAfter := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			page, err := client.ListUsers(context.Background(), titanclient.ListUsersRequest{
+				PageSize:     5,
+				Cursor:       "previous",
+				IncludeTotal: true,
+				Role:         "admin",
+				EmailDomain:  "example.com",
+				CreatedAfter: createdAfter,
+				Deleted:      "include",
+				Sort:         "email",
+				Order:        "desc",
+			})
+			require.NoError(t, err)
+
+			request := service.listRequest
+			assert.Equal(t, int32(5), request.PageSize)
+			assert.Equal(t, "previous", request.Cursor)
+			assert.True(t, request.IncludeTotal)
+			assert.Equal(t, "admin", request.Role)
+			assert.Equal(t, "example.com", request.EmailDomain)
+			assert.True(t, createdAfter.Equal(request.CreatedAfter.AsTime()))
+			assert.Nil(t, request.CreatedBefore)
+			assert.Equal(t, "include", request.Deleted)
+			assert.Equal(t, "email", request.Sort)
+			assert.Equal(t, "desc", request.Order)
+
+			require.Len(t, page.Users, 1)
+			assert.Equal(t, `"3"`, page.Users[0].ETag)
+			assert.False(t, page.Users[0].DeletedAt.IsZero())
+			assert.Equal(t, "next", page.NextCursor)
+			require.NotNil(t, page.TotalCount)
+			assert.Equal(t, int64(2), *page.TotalCount)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package titanclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Sentinel errors matching the errors Titan reports. Use errors.Is to test
+// for them; errors.As with *Error gives access to the details.
+var (
+	ErrUserNotFound       = errors.New("titan: user not found")
+	ErrEmailAlreadyExists = errors.New("titan: user with this email already exists")
+	ErrConflict           = errors.New("titan: user changed since it was read")
+	ErrUserDeleted        = errors.New("titan: user was deleted")
+	ErrInvalidArgument    = errors.New("titan: invalid argument")
+	ErrPermissionDenied   = errors.New("titan: permission denied")
+	ErrUnauthenticated    = errors.New("titan: unauthenticated")
+	ErrUnavailable        = errors.New("titan: service unavailable")
+	ErrInternal           = errors.New("titan: internal error")
+)
+
+// reasonUserDeleted is the error code of the failures on a deleted user.
+const reasonUserDeleted = "user.deleted"
+
+// Error is returned for every failure reported by Titan.
+type Error struct {
+	// Code is the gRPC code of the failure, also for the HTTP transport.
+	Code codes.Code
+	// Message is the message sent by the server.
+	Message string
+	// Field names the request field to fix for invalid arguments.
+	Field string
+	// Reason is the stable error code of the failure, such as
+	// user.email_taken. It is empty when the server sent none.
+	Reason string
+}
+
+func (e *Error) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("titan: %s: %s (field %s)", e.Code, e.Message, e.Field)
+	}
+
+	return fmt.Sprintf("titan: %s: %s", e.Code, e.Message)
+}
+
+// Unwrap ties the error to the matching sentinel error.
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case codes.NotFound:
+		return ErrUserNotFound
+	case codes.AlreadyExists:
+		return ErrEmailAlreadyExists
+	case codes.InvalidArgument, codes.OutOfRange:
+		return ErrInvalidArgument
+	case codes.PermissionDenied:
+		return ErrPermissionDenied
+	case codes.Unauthenticated:
+		return ErrUnauthenticated
+	case codes.Aborted:
+		return ErrConflict
+	case codes.FailedPrecondition:
+		if e.Reason == reasonUserDeleted {
+			return ErrUserDeleted
+		}
+		return ErrInternal
+	case codes.Unavailable, codes.ResourceExhausted:
+		return ErrUnavailable
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	case codes.Canceled:
+		return context.Canceled
+	default:
+		return ErrInternal
+	}
+}
+
+// IsTransient reports whether retrying the call may succeed. Conflicts are
+// not transient: the call has to be made again with the current user.
+func IsTransient(err error) bool {
+	var titanErr *Error
+	if !errors.As(err, &titanErr) {
+		return false
+	}
+
+	switch titanErr.Code {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	}
+
+	return false
+}
+
+// fromStatus converts a gRPC status into an *Error. Errors without a status,
+// such as context errors, are returned unchanged.
+func fromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	titanErr := &Error{Code: st.Code(), Message: st.Message()}
+
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			titanErr.Reason = detail.Reason
+		case *errdetails.BadRequest:
+			if len(detail.FieldViolations) > 0 {
+				titanErr.Field = detail.FieldViolations[0].Field
+			}
+		}
+	}
+
+	return titanErr
+}
+
+// codeFromHTTPStatus is used when an HTTP error carries no gRPC status body,
+// for example when a proxy answered instead of Titan.
+func codeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.Aborted
+	case http.StatusGone:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,271 @@
+package titanclient
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type grpcTransport struct {
+	conn    *grpc.ClientConn
+	client  pb.UserServiceClient
+	options options
+}
+
+// NewGRPCClient connects to the UserService at target, for example
+// "titan:50051".
+func NewGRPCClient(target string, opts ...Option) (*Client, error) {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, options.dialOptions...)
+
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(&grpcTransport{conn: conn, client: pb.NewUserServiceClient(conn), options: options}, options), nil
+}
+
+// outgoing adds the bearer token and extra headers to the call metadata.
+func (t *grpcTransport) outgoing(ctx context.Context) (context.Context, error) {
+	var pairs []string
+
+	for key, value := range t.options.headers {
+		pairs = append(pairs, key, value)
+	}
+
+	if t.options.tokenSource != nil {
+		token, err := t.options.tokenSource(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, "authorization", "Bearer "+token)
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, pairs...), nil
+}
+
+func (t *grpcTransport) getUserByID(ctx context.Context, id string) (*User, error) {
+	ctx, err := t.outgoing(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := t.client.GetUserByID(ctx, &pb.GetUserRequest{Id: id})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return &User{
+		ID:        response.Id,
+		FirstName: response.FirstName,
+		LastName:  response.LastName,
+		Email:     response.Email,
+		Role:      response.Role,
+		Region:    response.Region,
+		CreatedAt: toTime(response.CreatedAt),
+		UpdatedAt: toTime(response.UpdatedAt),
+		ETag:      response.Etag,
+	}, nil
+}
+
+func (t *grpcTransport) getUserByEmail(ctx context.Context, email string) (*User, error) {
+	ctx, err := t.outgoing(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := t.client.GetUserByEmail(ctx, &pb.GetUserByEmailRequest{Email: email})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return fromProtoUser(response), nil
+}
+
+func (t *grpcTransport) batchGetUsers(ctx context.Context, ids []string) ([]*User, []string, error) {
+	ctx, err := t.outgoing(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, err := t.client.BatchGetUsers(ctx, &pb.BatchGetUsersRequest{Ids: ids})
+	if err != nil {
+		return nil, nil, fromStatus(err)
+	}
+
+	return fromProtoUsers(response.Users), response.MissingIds, nil
+}
+
+func (t *grpcTransport) listUsers(ctx context.Context, req ListUsersRequest) (*UserPage, error) {
+	ctx, err := t.outgoing(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := t.client.ListUsers(ctx, &pb.ListUsersRequest{
+		Page:          int32(req.Page),
+		PageSize:      int32(req.PageSize),
+		Cursor:        req.Cursor,
+		IncludeTotal:  req.IncludeTotal,
+		Role:          req.Role,
+		EmailDomain:   req.EmailDomain,
+		CreatedAfter:  toTimestamp(req.CreatedAfter),
+		CreatedBefore: toTimestamp(req.CreatedBefore),
+		UpdatedAfter:  toTimestamp(req.UpdatedAfter),
+		UpdatedBefore: toTimestamp(req.UpdatedBefore),
+		Deleted:       req.Deleted,
+		Sort:          req.Sort,
+		Order:         req.Order,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return fromProtoPage(response), nil
+}
+
+func (t *grpcTransport) createUser(ctx context.Context, req CreateUserRequest) (*User, error) {
+	ctx, err := t.outgoing(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := t.client.CreateUser(ctx, &pb.CreateUserRequest{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Password:  req.Password,
+		Region:    req.Region,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return fromProtoUser(response), nil
+}
+
+func (t *grpcTransport) patchUser(ctx context.Context, id string, req PatchUserRequest) (*User, error) {
+	ctx, err := t.outgoing(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := t.client.PatchUser(ctx, &pb.PatchUserRequest{
+		Id:        id,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Region:    req.Region,
+		Etag:      req.ETag,
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return fromProtoUser(response), nil
+}
+
+func (t *grpcTransport) updateUser(ctx context.Context, user *User, fields []string) (*User, error) {
+	ctx, err := t.outgoing(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := t.client.UpdateUser(ctx, &pb.UpdateUserRequest{
+		User: &pb.User{
+			Id:        user.ID,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+			Region:    user.Region,
+			Etag:      user.ETag,
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: fields},
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return fromProtoUser(response), nil
+}
+
+func (t *grpcTransport) deleteUser(ctx context.Context, id string, etag string) (*User, error) {
+	ctx, err := t.outgoing(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := t.client.DeleteUser(ctx, &pb.DeleteUserRequest{Id: id, Etag: etag})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return fromProtoUser(response), nil
+}
+
+func (t *grpcTransport) close() error {
+	return t.conn.Close()
+}
+
+func fromProtoUser(user *pb.User) *User {
+	return &User{
+		ID:        user.Id,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Role:      user.Role,
+		Region:    user.Region,
+		CreatedAt: toTime(user.CreatedAt),
+		UpdatedAt: toTime(user.UpdatedAt),
+		DeletedAt: toTime(user.DeletedAt),
+		ETag:      user.Etag,
+	}
+}
+
+func fromProtoUsers(users []*pb.User) []*User {
+	var result []*User
+	for _, user := range users {
+		result = append(result, fromProtoUser(user))
+	}
+
+	return result
+}
+
+func fromProtoPage(response *pb.ListUsersResponse) *UserPage {
+	return &UserPage{
+		Users:      fromProtoUsers(response.Users),
+		NextCursor: response.NextCursor,
+		TotalCount: response.TotalCount,
+	}
+}
+
+// toTime leaves unset timestamps as the zero time instead of the Unix epoch.
+func toTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
+
+// toTimestamp leaves the zero time unset, which the server reads as no
+// bound.
+func toTimestamp(value time.Time) *timestamppb.Timestamp {
+	if value.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(value)
+}
@@ -0,0 +1,267 @@
+package titanclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// httpTransport talks to the JSON gateway served under /v1, which shares the
+// gRPC contract and error codes.
+type httpTransport struct {
+	baseURL string
+	options options
+}
+
+// NewHTTPClient calls the gateway on the HTTP port, for example
+// "http://titan:8080".
+func NewHTTPClient(baseURL string, opts ...Option) (*Client, error) {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, err
+	}
+
+	return newClient(&httpTransport{baseURL: strings.TrimSuffix(baseURL, "/"), options: options}, options), nil
+}
+
+var unmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// do sends body (when not nil) as JSON and decodes the answer into response.
+func (t *httpTransport) do(ctx context.Context, method string, path string, query url.Values, body proto.Message, response proto.Message) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	target := t.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	for key, value := range t.options.headers {
+		req.Header.Set(key, value)
+	}
+
+	if t.options.tokenSource != nil {
+		token, err := t.options.tokenSource(ctx)
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := t.options.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// The request never got an answer, so it is worth retrying.
+		return &Error{Code: codes.Unavailable, Message: err.Error()}
+	}
+	defer res.Body.Close()
+
+	payload, err := io.ReadAll(res.Body)
+	if err != nil {
+		return &Error{Code: codes.Unavailable, Message: err.Error()}
+	}
+
+	if res.StatusCode >= 300 {
+		return httpError(res.StatusCode, payload)
+	}
+
+	return unmarshalOptions.Unmarshal(payload, response)
+}
+
+// httpError decodes the google.rpc.Status body written by the gateway.
+func httpError(httpStatus int, payload []byte) error {
+	var st spb.Status
+	if err := unmarshalOptions.Unmarshal(payload, &st); err == nil && st.Code != 0 {
+		return fromStatus(status.FromProto(&st).Err())
+	}
+
+	return &Error{Code: codeFromHTTPStatus(httpStatus), Message: http.StatusText(httpStatus)}
+}
+
+func (t *httpTransport) getUserByID(ctx context.Context, id string) (*User, error) {
+	var response pb.GetUserResponse
+	if err := t.do(ctx, http.MethodGet, "/v1/users/"+url.PathEscape(id), nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:        response.Id,
+		FirstName: response.FirstName,
+		LastName:  response.LastName,
+		Email:     response.Email,
+		Role:      response.Role,
+		Region:    response.Region,
+		CreatedAt: toTime(response.CreatedAt),
+		UpdatedAt: toTime(response.UpdatedAt),
+		ETag:      response.Etag,
+	}, nil
+}
+
+func (t *httpTransport) getUserByEmail(ctx context.Context, email string) (*User, error) {
+	var response pb.User
+	if err := t.do(ctx, http.MethodGet, "/v1/users/email/"+url.PathEscape(email), nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return fromProtoUser(&response), nil
+}
+
+func (t *httpTransport) batchGetUsers(ctx context.Context, ids []string) ([]*User, []string, error) {
+	var response pb.BatchGetUsersResponse
+	if err := t.do(ctx, http.MethodGet, "/v1/users:batchGet", url.Values{"ids": ids}, nil, &response); err != nil {
+		return nil, nil, err
+	}
+
+	return fromProtoUsers(response.Users), response.MissingIds, nil
+}
+
+func (t *httpTransport) listUsers(ctx context.Context, req ListUsersRequest) (*UserPage, error) {
+	query := url.Values{}
+	set := func(key string, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	setTime := func(key string, value time.Time) {
+		if !value.IsZero() {
+			query.Set(key, value.UTC().Format(time.RFC3339Nano))
+		}
+	}
+
+	if req.Page != 0 {
+		set("page", strconv.Itoa(req.Page))
+	}
+	if req.PageSize != 0 {
+		set("page_size", strconv.Itoa(req.PageSize))
+	}
+	if req.IncludeTotal {
+		set("include_total", "true")
+	}
+	set("cursor", req.Cursor)
+	set("role", req.Role)
+	set("email_domain", req.EmailDomain)
+	setTime("created_after", req.CreatedAfter)
+	setTime("created_before", req.CreatedBefore)
+	setTime("updated_after", req.UpdatedAfter)
+	setTime("updated_before", req.UpdatedBefore)
+	set("deleted", req.Deleted)
+	set("sort", req.Sort)
+	set("order", req.Order)
+
+	var response pb.ListUsersResponse
+	if err := t.do(ctx, http.MethodGet, "/v1/users", query, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return fromProtoPage(&response), nil
+}
+
+func (t *httpTransport) createUser(ctx context.Context, req CreateUserRequest) (*User, error) {
+	var response pb.User
+	body := &pb.CreateUserRequest{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Password:  req.Password,
+		Region:    req.Region,
+	}
+	if err := t.do(ctx, http.MethodPost, "/v1/users", nil, body, &response); err != nil {
+		return nil, err
+	}
+
+	return fromProtoUser(&response), nil
+}
+
+func (t *httpTransport) patchUser(ctx context.Context, id string, req PatchUserRequest) (*User, error) {
+	var response pb.User
+	body := &pb.PatchUserRequest{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Region:    req.Region,
+		Etag:      req.ETag,
+	}
+	if err := t.do(ctx, http.MethodPost, "/v1/users/"+url.PathEscape(id)+":patch", nil, body, &response); err != nil {
+		return nil, err
+	}
+
+	return fromProtoUser(&response), nil
+}
+
+func (t *httpTransport) updateUser(ctx context.Context, user *User, fields []string) (*User, error) {
+	var response pb.User
+	body := &pb.User{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Region:    user.Region,
+		Etag:      user.ETag,
+	}
+
+	// Without an explicit mask the gateway would derive it from the body,
+	// which leaves out the empty fields protojson does not emit.
+	var query url.Values
+	if len(fields) > 0 {
+		query = url.Values{"update_mask": {strings.Join(fields, ",")}}
+	}
+
+	if err := t.do(ctx, http.MethodPatch, "/v1/users/"+url.PathEscape(user.ID), query, body, &response); err != nil {
+		return nil, err
+	}
+
+	return fromProtoUser(&response), nil
+}
+
+func (t *httpTransport) deleteUser(ctx context.Context, id string, etag string) (*User, error) {
+	var query url.Values
+	if etag != "" {
+		query = url.Values{"etag": {etag}}
+	}
+
+	var response pb.User
+	if err := t.do(ctx, http.MethodDelete, "/v1/users/"+url.PathEscape(id), query, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return fromProtoUser(&response), nil
+}
+
+func (t *httpTransport) close() error {
+	return nil
+}
@@ -0,0 +1,123 @@
+package titanclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	DefaultMaxAttempts    = 3
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 2 * time.Second
+)
+
+// TokenSource returns the bearer token sent with every call.
+type TokenSource func(ctx context.Context) (string, error)
+
+type retryOptions struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+type options struct {
+	timeout     time.Duration
+	retry       retryOptions
+	tokenSource TokenSource
+	headers     map[string]string
+	cacheTTL    time.Duration
+	cacheSize   int
+	dialOptions []grpc.DialOption
+	httpClient  *http.Client
+}
+
+type Option func(*options)
+
+func defaultOptions() options {
+	return options{
+		retry: retryOptions{
+			maxAttempts:    DefaultMaxAttempts,
+			initialBackoff: DefaultInitialBackoff,
+			maxBackoff:     DefaultMaxBackoff,
+		},
+		headers:    map[string]string{},
+		httpClient: http.DefaultClient,
+	}
+}
+
+// WithTimeout bounds every attempt of a call. The context passed to the call
+// still bounds the call as a whole.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetry sets how often reads failing with a transient error
+// (Unavailable or ResourceExhausted) are attempted, and the backoff between
+// attempts. Writes are always attempted once. maxAttempts of 1 disables
+// retries.
+func WithRetry(maxAttempts int, initialBackoff time.Duration, maxBackoff time.Duration) Option {
+	return func(o *options) {
+		if maxAttempts < 1 {
+			maxAttempts = 1
+		}
+
+		o.retry = retryOptions{
+			maxAttempts:    maxAttempts,
+			initialBackoff: initialBackoff,
+			maxBackoff:     maxBackoff,
+		}
+	}
+}
+
+// WithToken sends a fixed bearer token with every call.
+func WithToken(token string) Option {
+	return WithTokenSource(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// WithTokenSource asks source for the bearer token before every call, so
+// tokens can be refreshed.
+func WithTokenSource(source TokenSource) Option {
+	return func(o *options) {
+		o.tokenSource = source
+	}
+}
+
+// WithHeader sends an extra header (metadata over gRPC) with every call, for
+// example the X-User-ID and X-User-Role identity headers.
+func WithHeader(key string, value string) Option {
+	return func(o *options) {
+		o.headers[key] = value
+	}
+}
+
+// WithCache keeps users returned by GetUserByID for ttl, up to size entries.
+// Users changed or deleted through this client are dropped from the cache;
+// changes made elsewhere show up once the entry expires.
+func WithCache(ttl time.Duration, size int) Option {
+	return func(o *options) {
+		o.cacheTTL = ttl
+		o.cacheSize = size
+	}
+}
+
+// WithDialOptions adds gRPC dial options, such as transport credentials.
+// Without credentials the gRPC client connects without TLS.
+func WithDialOptions(dialOptions ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, dialOptions...)
+	}
+}
+
+// WithHTTPClient sets the http.Client used by the HTTP transport.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
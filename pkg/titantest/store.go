@@ -0,0 +1,510 @@
+package titantest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonattasmoraes/titan/internal/user/domain"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+)
+
+// store keeps users and the change log in memory. It implements the user
+// repository interfaces of the SQL store and the search index the APIs use,
+// and can be told to slow down or fail.
+type store struct {
+	mu     sync.Mutex
+	users  map[string]*entities.User
+	events []*entities.UserEvent
+
+	// tx serializes transactions with each other, but not with other writes.
+	tx sync.Mutex
+
+	latency  time.Duration
+	failures int
+	failErr  error
+}
+
+func newStore() *store {
+	return &store{users: map[string]*entities.User{}}
+}
+
+// enter waits for the configured latency and returns the injected failure,
+// if any. It must be called with s.mu held; the lock is released while
+// sleeping.
+func (s *store) enter() error {
+	if s.latency > 0 {
+		latency := s.latency
+		s.mu.Unlock()
+		time.Sleep(latency)
+		s.mu.Lock()
+	}
+
+	if s.failures != 0 {
+		if s.failures > 0 {
+			s.failures--
+		}
+		return s.failErr
+	}
+
+	return nil
+}
+
+func (s *store) activeUser(id string) (*entities.User, bool) {
+	user, ok := s.users[id]
+	if !ok || !user.DeletedAt.IsZero() {
+		return nil, false
+	}
+
+	return user, true
+}
+
+func (s *store) CreateUser(user *entities.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return err
+	}
+
+	user.Version = 1
+	stored := *user
+	s.users[user.ID] = &stored
+
+	return nil
+}
+
+// seed stores a fixture with the event of its creation, as CreateUser and
+// AppendUserEvent would in one transaction, but without the injected latency
+// and failures. Like the SQL store, it refuses an email another active user
+// has.
+func (s *store) seed(user *entities.User) error {
+	s.tx.Lock()
+	defer s.tx.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.users {
+		if existing.ID == user.ID {
+			return fmt.Errorf("titantest: a user with the ID %s already exists", user.ID)
+		}
+		if existing.Email == user.Email && existing.DeletedAt.IsZero() {
+			return usecase.ErrEmailAlreadyExists
+		}
+	}
+
+	user.Version = 1
+	stored := *user
+	s.users[user.ID] = &stored
+
+	event := entities.NewUserEvent(entities.UserCreated, &stored)
+	event.Sequence = int64(len(s.events) + 1)
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+func (s *store) FindUserById(id string) (*entities.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+
+	user, ok := s.activeUser(id)
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+
+	found := *user
+	return &found, nil
+}
+
+// FindUserByEmail mirrors the SQL store, which returns an empty user when no
+// one matches.
+func (s *store) FindUserByEmail(email string) (*entities.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+
+	for _, user := range s.users {
+		if user.Email == email && user.DeletedAt.IsZero() {
+			found := *user
+			return &found, nil
+		}
+	}
+
+	return &entities.User{}, nil
+}
+
+func (s *store) IsUserDeleted(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return false, err
+	}
+
+	user, ok := s.users[id]
+	return ok && !user.DeletedAt.IsZero(), nil
+}
+
+func (s *store) FindUsersByIds(ids []string) ([]*entities.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+
+	var users []*entities.User
+	for _, id := range ids {
+		if user, ok := s.activeUser(id); ok {
+			found := *user
+			users = append(users, &found)
+		}
+	}
+
+	return users, nil
+}
+
+func (s *store) ListUsers(query domain.ListUsersQuery) ([]*entities.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+
+	users := s.matchingUsers(query.Filter)
+
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = domain.SortByID
+	}
+
+	// compare orders users the way the SQL store does: by the sort field,
+	// then by ID.
+	compare := func(a *entities.User, value, id string) int {
+		if c := compareSortValues(sortBy, sortBy.Value(a), value); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, id)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		c := compare(users[i], sortBy.Value(users[j]), users[j].ID)
+		if query.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	var page []*entities.User
+	for _, user := range users {
+		if query.After != nil {
+			afterValue := query.After.Value
+			if sortBy == domain.SortByID {
+				afterValue = query.After.ID
+			}
+
+			c := compare(user, afterValue, query.After.ID)
+			if (!query.Descending && c <= 0) || (query.Descending && c >= 0) {
+				continue
+			}
+		}
+		page = append(page, user)
+	}
+
+	if query.Offset >= len(page) {
+		return nil, nil
+	}
+	page = page[query.Offset:]
+
+	if len(page) > query.Limit {
+		page = page[:query.Limit]
+	}
+
+	return page, nil
+}
+
+// ExportUsers reads the matching users as one page, then calls fn without
+// holding the lock.
+func (s *store) ExportUsers(query domain.ListUsersQuery, fn func(user *entities.User) error) error {
+	query.After = nil
+	query.Offset = 0
+	query.Limit = math.MaxInt
+
+	users, err := s.ListUsers(query)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *store) CountUsers(query domain.ListUsersQuery) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return 0, err
+	}
+
+	return len(s.matchingUsers(query.Filter)), nil
+}
+
+// SearchUsers matches the words of the names and emails of active users that
+// start with a term of query, like the SQLite index. Users matching more
+// terms rank higher.
+func (s *store) SearchUsers(query string, limit int) ([]*domain.UserSearchHit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+
+	terms := domain.SearchTerms(query)
+
+	var hits []*domain.UserSearchHit
+	for _, user := range s.users {
+		if !user.DeletedAt.IsZero() {
+			continue
+		}
+
+		words := domain.SearchTerms(user.FirstName + " " + user.LastName + " " + user.Email)
+
+		matched := 0
+		for _, term := range terms {
+			for _, word := range words {
+				if strings.HasPrefix(word, term) {
+					matched++
+					break
+				}
+			}
+		}
+		if matched == 0 {
+			continue
+		}
+
+		found := *user
+		hits = append(hits, &domain.UserSearchHit{
+			User:       &found,
+			Rank:       float64(matched),
+			Highlights: domain.UserHighlights(&found, terms),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].User.ID < hits[j].User.ID
+	})
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits, nil
+}
+
+// matchingUsers returns copies of the users matched by filter.
+func (s *store) matchingUsers(filter domain.UserFilter) []*entities.User {
+	var users []*entities.User
+	for _, user := range s.users {
+		if !matchesUserFilter(user, filter) {
+			continue
+		}
+
+		found := *user
+		users = append(users, &found)
+	}
+
+	return users
+}
+
+func matchesUserFilter(user *entities.User, filter domain.UserFilter) bool {
+	deleted := !user.DeletedAt.IsZero()
+
+	switch {
+	case filter.Deleted == domain.ExcludeDeleted && deleted,
+		filter.Deleted == domain.OnlyDeleted && !deleted,
+		filter.Role != "" && user.Role != filter.Role,
+		filter.EmailDomain != "" && !strings.HasSuffix(strings.ToLower(user.Email), "@"+strings.ToLower(filter.EmailDomain)),
+		!filter.CreatedAfter.IsZero() && user.CreatedAt.Before(filter.CreatedAfter),
+		!filter.CreatedBefore.IsZero() && !user.CreatedAt.Before(filter.CreatedBefore),
+		!filter.UpdatedAfter.IsZero() && user.UpdatedAt.Before(filter.UpdatedAfter),
+		!filter.UpdatedBefore.IsZero() && !user.UpdatedAt.Before(filter.UpdatedBefore):
+		return false
+	}
+
+	return true
+}
+
+func compareSortValues(field domain.UserSortField, a, b string) int {
+	if field.IsTime() {
+		ta, _ := time.Parse(time.RFC3339Nano, a)
+		tb, _ := time.Parse(time.RFC3339Nano, b)
+		return ta.Compare(tb)
+	}
+
+	return strings.Compare(a, b)
+}
+
+// UpdateUser mirrors the SQL store, which writes empty last names and
+// regions as NULL and reads them back empty.
+func (s *store) UpdateUser(user *entities.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return err
+	}
+
+	stored, ok := s.writableUser(user.ID, user.Version)
+	if !ok {
+		return versionMismatch(user.Version)
+	}
+
+	stored.FirstName = user.FirstName
+	stored.LastName = user.LastName
+	stored.Email = user.Email
+	stored.Role = user.Role
+	stored.Region = user.Region
+	stored.UpdatedAt = user.UpdatedAt
+	stored.Version++
+	user.Version = stored.Version
+
+	return nil
+}
+
+func (s *store) DeleteUser(id string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return err
+	}
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil
+	}
+
+	if version != 0 && user.Version != version {
+		return domain.ErrVersionMismatch
+	}
+
+	user.DeletedAt = time.Now()
+	user.Version++
+
+	return nil
+}
+
+// WithinTransaction runs fn against the store itself and, when fn fails,
+// restores the users and the change log to what they were before.
+func (s *store) WithinTransaction(fn func(users domain.UserRepository, events domain.UserEventRepository) error) error {
+	return s.within(func() error {
+		return fn(s, s)
+	})
+}
+
+func (s *store) within(fn func() error) error {
+	s.tx.Lock()
+	defer s.tx.Unlock()
+
+	s.mu.Lock()
+	users := make(map[string]entities.User, len(s.users))
+	for id, user := range s.users {
+		users[id] = *user
+	}
+	events := len(s.events)
+	s.mu.Unlock()
+
+	if err := fn(); err != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.users = make(map[string]*entities.User, len(users))
+		for id, user := range users {
+			s.users[id] = &user
+		}
+		s.events = s.events[:events]
+
+		return err
+	}
+
+	return nil
+}
+
+// writableUser returns the active user with id when it is at version, or at
+// any version when version is 0.
+func (s *store) writableUser(id string, version int64) (*entities.User, bool) {
+	stored, ok := s.activeUser(id)
+	if !ok || (version != 0 && stored.Version != version) {
+		return nil, false
+	}
+
+	return stored, true
+}
+
+// versionMismatch is the error of a write that found no user, which the SQL
+// store only reports when a version was expected.
+func versionMismatch(version int64) error {
+	if version != 0 {
+		return domain.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+func (s *store) AppendUserEvent(event *entities.UserEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return err
+	}
+
+	event.Sequence = int64(len(s.events) + 1)
+	stored := *event
+	s.events = append(s.events, &stored)
+
+	return nil
+}
+
+func (s *store) ListUserEvents(afterSequence int64, limit int) ([]*entities.UserEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+
+	var events []*entities.UserEvent
+	for _, event := range s.events {
+		if event.Sequence > afterSequence && len(events) < limit {
+			found := *event
+			events = append(events, &found)
+		}
+	}
+
+	return events, nil
+}
@@ -0,0 +1,309 @@
+// Package titantest starts a real Titan UserService in process, for hermetic
+// tests of code that calls Titan through titanclient or over HTTP. The gRPC
+// server, the /v1 gateway and the user routes under /api run the production
+// handlers and usecases on in-memory listeners, backed by an in-memory store
+// instead of Postgres. Invitations are not served.
+//
+//	func TestProfile(t *testing.T) {
+//		titan := titantest.Start(t)
+//		user := titan.CreateUser(t, titantest.User{FirstName: "Ada"})
+//
+//		client := titan.Client(t)
+//		got, err := client.GetUserByID(ctx, user.ID)
+//		...
+//	}
+package titantest
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jonattasmoraes/titan/internal/policy"
+	"github.com/jonattasmoraes/titan/internal/user/domain/entities"
+	grpcserver "github.com/jonattasmoraes/titan/internal/user/infra/grpc"
+	httphandler "github.com/jonattasmoraes/titan/internal/user/infra/http"
+	"github.com/jonattasmoraes/titan/internal/user/infra/repository"
+	"github.com/jonattasmoraes/titan/internal/user/infra/server"
+	"github.com/jonattasmoraes/titan/internal/user/usecase"
+	"github.com/jonattasmoraes/titan/pkg/titanclient"
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	// GRPCTarget is the target to dial with GRPCDialOptions.
+	GRPCTarget = "passthrough:///titan.test"
+	// URL is the base URL of the HTTP routes, the gateway under /v1 and the
+	// API under /api, reachable with HTTPClient.
+	URL = "http://titan.test"
+
+	bufferSize = 1 << 20
+)
+
+// ErrStorageUnavailable makes calls fail the way they do when the database
+// is down: Unavailable over gRPC.
+var ErrStorageUnavailable = fmt.Errorf("titantest: storage unavailable: %w", driver.ErrBadConn)
+
+// User describes a fixture. Empty fields get unique defaults.
+type User struct {
+	ID        string
+	FirstName string
+	LastName  string
+	Email     string
+	Password  string
+	Role      string
+	Region    string
+}
+
+type Server struct {
+	store        *store
+	grpcListener *bufconn.Listener
+	httpListener *bufconn.Listener
+	sequence     atomic.Int64
+	fixtures     []User
+}
+
+type Option func(*Server)
+
+// WithUsers creates fixtures, as CreateUser does, before the server starts.
+func WithUsers(users ...User) Option {
+	return func(s *Server) {
+		s.fixtures = append(s.fixtures, users...)
+	}
+}
+
+// Start runs the gRPC server and the HTTP routes until the test ends.
+func Start(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	s := &Server{
+		store:        newStore(),
+		grpcListener: bufconn.Listen(bufferSize),
+		httpListener: bufconn.Listen(bufferSize),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for _, user := range s.fixtures {
+		s.CreateUser(t, user)
+	}
+
+	createUser := usecase.NewCreateUserUsecase(s.store)
+	getUserById := usecase.NewGetUserByIdUsecase(s.store)
+	getUserByEmail := usecase.NewGetUserByEmailUsecase(s.store)
+	batchGetUsers := usecase.NewBatchGetUsersUsecase(s.store)
+	listUsers := usecase.NewListUsersUsecase(s.store)
+	exportUsers := usecase.NewExportUsersUsecase(s.store)
+	updateUser := usecase.NewUpdateUserUsecase(s.store)
+	deleteUser := usecase.NewDeleteUserUsecase(s.store)
+	listUserEvents := usecase.NewListUserEventsUsecase(s.store)
+
+	userGrpcServer := grpcserver.NewUserGrpcServer(
+		createUser,
+		getUserById,
+		getUserByEmail,
+		batchGetUsers,
+		listUsers,
+		exportUsers,
+		updateUser,
+		deleteUser,
+		listUserEvents,
+		nil,
+		context.Background(),
+	)
+
+	grpcServer := server.NewGrpcServer(userGrpcServer, health.NewServer(), grpcserver.ServerConfig{Recovery: true, Identity: policy.TrustIdentityHeaders}.ServerOptions()...)
+	go grpcServer.Serve(s.grpcListener)
+	t.Cleanup(grpcServer.Stop)
+
+	gateway, err := server.NewGateway(context.Background(), GRPCTarget, s.dialer())
+	if err != nil {
+		t.Fatalf("titantest: failed to start gateway: %v", err)
+	}
+
+	userHandlers := httphandler.NewUserHandler(
+		userGrpcServer,
+		getUserById,
+		usecase.NewSearchUsersUsecase(s.store),
+		usecase.NewPatchUserDocumentUsecase(s.store, updateUser),
+		usecase.NewReplaceUserUsecase(s.store, false),
+		usecase.NewBatchWriteUsersUsecase(s.store, s.store),
+		usecase.NewImportUsersUsecase(s.store, createUser),
+		nil,
+		httphandler.NewIdempotency(repository.NewMemoryIdempotencyStore(), 0),
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := server.NewRouter(userHandlers, nil, gateway, nil, server.RouterConfig{Identity: policy.TrustIdentityHeaders})
+
+	httpServer := &http.Server{Handler: router}
+	go httpServer.Serve(s.httpListener)
+	t.Cleanup(func() { httpServer.Close() })
+
+	return s
+}
+
+func (s *Server) dialer() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return s.grpcListener.DialContext(ctx)
+	})
+}
+
+// GRPCDialOptions connects a gRPC client to the server when dialing
+// GRPCTarget, for callers that use their own generated stubs.
+func (s *Server) GRPCDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{s.dialer(), grpc.WithTransportCredentials(insecure.NewCredentials())}
+}
+
+// HTTPClient returns an http.Client whose requests to URL reach the HTTP
+// routes.
+func (s *Server) HTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return s.httpListener.DialContext(ctx)
+			},
+		},
+	}
+}
+
+// Client returns a titanclient over gRPC, closed when the test ends.
+func (s *Server) Client(t testing.TB, opts ...titanclient.Option) *titanclient.Client {
+	t.Helper()
+
+	opts = append([]titanclient.Option{titanclient.WithDialOptions(s.dialer())}, opts...)
+
+	client, err := titanclient.NewGRPCClient(GRPCTarget, opts...)
+	if err != nil {
+		t.Fatalf("titantest: failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// HTTPTitanClient returns a titanclient over the HTTP gateway.
+func (s *Server) HTTPTitanClient(t testing.TB, opts ...titanclient.Option) *titanclient.Client {
+	t.Helper()
+
+	opts = append([]titanclient.Option{titanclient.WithHTTPClient(s.HTTPClient())}, opts...)
+
+	client, err := titanclient.NewHTTPClient(URL, opts...)
+	if err != nil {
+		t.Fatalf("titantest: failed to create client: %v", err)
+	}
+
+	return client
+}
+
+// CreateUser stores a fixture and records its creation in the change log, and
+// returns it with its defaults filled in. Unlike the CreateUser RPC, it keeps
+// the ID and the role of the fixture, but it validates it the same way and
+// fails the test when it is invalid or its email is taken. Injected latency
+// and failures do not apply.
+func (s *Server) CreateUser(t testing.TB, user User) titanclient.User {
+	t.Helper()
+
+	created, err := s.seed(user)
+	if err != nil {
+		t.Fatalf("titantest: invalid fixture: %v", err)
+	}
+
+	return created
+}
+
+func (s *Server) seed(user User) (titanclient.User, error) {
+	n := s.sequence.Add(1)
+
+	if user.ID == "" {
+		user.ID = ulid.Make().String()
+	}
+	if user.FirstName == "" {
+		user.FirstName = fmt.Sprintf("User%d", n)
+	}
+	if user.LastName == "" {
+		user.LastName = "Fixture"
+	}
+	if user.Email == "" {
+		user.Email = fmt.Sprintf("user%d@titan.test", n)
+	}
+	if user.Password == "" {
+		user.Password = "password123"
+	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+
+	now := time.Now()
+	entity := &entities.User{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Password:  user.Password,
+		Role:      user.Role,
+		Region:    user.Region,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := entity.Validate(); err != nil {
+		return titanclient.User{}, err
+	}
+
+	if !entities.IsValidRole(entity.Role) {
+		return titanclient.User{}, entities.ErrIncorrectRole
+	}
+
+	if err := s.store.seed(entity); err != nil {
+		return titanclient.User{}, err
+	}
+
+	return titanclient.User{
+		ID:        entity.ID,
+		FirstName: entity.FirstName,
+		LastName:  entity.LastName,
+		Email:     entity.Email,
+		Role:      entity.Role,
+		Region:    entity.Region,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// SetLatency delays every storage operation by latency, for testing
+// timeouts. Zero removes the delay.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	s.store.latency = latency
+}
+
+// FailNext makes the next n storage operations fail with err; a negative n
+// keeps failing until Recover is called. Use ErrStorageUnavailable to
+// simulate a database outage.
+func (s *Server) FailNext(n int, err error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	s.store.failures = n
+	s.store.failErr = err
+}
+
+// Recover stops injected failures.
+func (s *Server) Recover() {
+	s.FailNext(0, nil)
+}
@@ -0,0 +1,98 @@
+package titantest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	pb "github.com/jonattasmoraes/titan/internal/user/infra/proto"
+	"github.com/jonattasmoraes/titan/pkg/titanclient"
+	"github.com/jonattasmoraes/titan/pkg/titantest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// TestServer_Smoke checks that the fixture serves both clients and injects
+// failures. The behaviour of the API itself is tested where it is
+// implemented.
+func TestServer_Smoke(t *testing.T) {
+	titan := titantest.Start(t, titantest.WithUsers(titantest.User{FirstName: "John", Email: "john@example.com"}))
+	paul := titan.CreateUser(t, titantest.User{FirstName: "Paul"})
+
+	for name, client := range map[string]*titanclient.Client{
+		"grpc": titan.Client(t, titanclient.WithRetry(1, 0, 0)),
+		"http": titan.HTTPTitanClient(t, titanclient.WithRetry(1, 0, 0)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			user, err := client.GetUserByID(context.Background(), paul.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Paul", user.FirstName)
+
+			user, err = client.GetUserByEmail(context.Background(), "john@example.com")
+			require.NoError(t, err)
+			assert.Equal(t, "John", user.FirstName)
+
+			// Last names are optional, so an update can clear them.
+			user, err = client.UpdateUser(context.Background(), &titanclient.User{ID: paul.ID}, "last_name")
+			require.NoError(t, err)
+			assert.Empty(t, user.LastName)
+			assert.Equal(t, "Paul", user.FirstName)
+
+			titan.FailNext(1, titantest.ErrStorageUnavailable)
+			_, err = client.GetUserByID(context.Background(), paul.ID)
+			assert.True(t, errors.Is(err, titanclient.ErrUnavailable))
+		})
+	}
+}
+
+// TestServer_API checks that the routes under /api are served next to the
+// gateway.
+func TestServer_API(t *testing.T) {
+	titan := titantest.Start(t, titantest.WithUsers(titantest.User{FirstName: "John", Email: "john@example.com"}))
+
+	for path, want := range map[string]string{
+		"/api/users":                       `"first_name":"John"`,
+		"/api/users/search?q=joh":          `"rank":1`,
+		"/v1/users/email/john@example.com": `"first_name":"John"`,
+	} {
+		res, err := titan.HTTPClient().Get(titantest.URL + path)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode, path)
+		assert.Contains(t, string(body), want, path)
+	}
+}
+
+// TestServer_Fixtures checks that fixtures are recorded in the change log
+// and validated like the users the API creates.
+func TestServer_Fixtures(t *testing.T) {
+	titan := titantest.Start(t, titantest.WithUsers(titantest.User{FirstName: "John"}))
+	paul := titan.CreateUser(t, titantest.User{FirstName: "Paul", Role: "admin"})
+
+	conn, err := grpc.NewClient(titantest.GRPCTarget, titan.GRPCDialOptions()...)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := pb.NewUserServiceClient(conn).WatchUsers(ctx, &pb.WatchUsersRequest{})
+	require.NoError(t, err)
+
+	var created []string
+	for i := 0; i < 2; i++ {
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, pb.UserEvent_EVENT_TYPE_CREATED, event.Type)
+		created = append(created, event.User.FirstName)
+	}
+	assert.Equal(t, []string{"John", "Paul"}, created)
+	assert.Equal(t, "admin", paul.Role)
+}
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id),
+    role VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id VARCHAR(255) PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(32) NOT NULL,
+    invited_by VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255),
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS invitations_organization_email_idx ON invitations (organization_id, email);
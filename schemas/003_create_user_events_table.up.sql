@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS user_events (
+    sequence BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(32) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL
+);
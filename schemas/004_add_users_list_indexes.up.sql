@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS users_created_at_id_idx ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS users_updated_at_id_idx ON users (updated_at, id);
+CREATE INDEX IF NOT EXISTS users_role_idx ON users (role);
@@ -0,0 +1,6 @@
+-- Keys of different callers may collide once unscoped; the responses are only a replay cache.
+DELETE FROM idempotency_keys;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS subject;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (idempotency_key);
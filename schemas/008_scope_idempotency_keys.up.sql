@@ -0,0 +1,5 @@
+-- Idempotency keys are chosen by the callers, so they are only unique per caller.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS subject VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (subject, idempotency_key);
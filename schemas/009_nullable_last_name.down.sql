@@ -0,0 +1,2 @@
+-- Older versions read last_name into a string, which fails on NULL.
+UPDATE users SET last_name = '' WHERE last_name IS NULL;
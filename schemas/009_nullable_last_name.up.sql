@@ -0,0 +1,2 @@
+-- Last names are optional: a user without one has NULL, never an empty string.
+UPDATE users SET last_name = NULL WHERE last_name = '';
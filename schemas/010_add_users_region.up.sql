@@ -0,0 +1,2 @@
+-- Regions are optional: access policies compare them with the region of the caller.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS region VARCHAR(50);
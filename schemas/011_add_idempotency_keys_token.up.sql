@@ -0,0 +1,2 @@
+-- Retries send the same fingerprint, so reservations are told apart by a token of their own.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS token VARCHAR(64) NOT NULL DEFAULT '';